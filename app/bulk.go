@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/operations"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxBulkItems 与 spec 中请求体的 maxItems 一致，没有挂载请求校验时由 handler 检查
+const maxBulkItems = 10000
+
+// errUnsupportedBulkBody 请求体既不是 JSON 也不是 NDJSON
+var errUnsupportedBulkBody = errors.New("bulk import accepts application/json or application/x-ndjson")
+
+// decodeBulkItems 把 JSON 数组或 NDJSON 请求体拆成逐项的原始 JSON。
+// NDJSON 与请求校验一样按 codec.SplitNDJSON 分行；挂载了请求校验时，某一行不是合法 JSON
+// 会让整个请求返回 400，没有挂载时只有这一项失败
+func decodeBulkItems(contentType string, body io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	switch {
+	case codec.IsNDJSON(contentType):
+		var err error
+		if items, err = codec.SplitNDJSON(body); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(contentType, codec.MIMEApplicationJSON):
+		if err := json.NewDecoder(body).Decode(&items); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errUnsupportedBulkBody
+	}
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d items are allowed, got %d", maxBulkItems, len(items))
+	}
+	return items, nil
+}
+
+// jsonBulkItems strict 模式下 JSON 请求体已经解码，重新编码成逐项的原始 JSON
+func jsonBulkItems(body BulkImportPetsJSONBody) ([]json.RawMessage, error) {
+	if len(body) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d items are allowed, got %d", maxBulkItems, len(body))
+	}
+	items := make([]json.RawMessage, len(body))
+	for i, item := range body {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = data
+	}
+	return items, nil
+}
+
+var newPetSchema = struct {
+	once   sync.Once
+	schema *openapi3.Schema
+	err    error
+}{}
+
+// validateNewPet 按 spec 中的 NewPet 校验单项，返回的 Error 指出第一个问题的位置
+func validateNewPet(raw json.RawMessage) (NewPet, *Error) {
+	newPetSchema.once.Do(func() {
+		swagger, err := GetSwagger()
+		if err != nil {
+			newPetSchema.err = err
+			return
+		}
+		newPetSchema.schema = swagger.Components.Schemas["NewPet"].Value
+	})
+	if newPetSchema.err != nil {
+		return NewPet{}, &Error{Code: http.StatusInternalServerError, Message: newPetSchema.err.Error()}
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return NewPet{}, &Error{Code: http.StatusBadRequest, Message: "invalid JSON: " + err.Error()}
+	}
+	if err := newPetSchema.schema.VisitJSON(value); err != nil {
+		return NewPet{}, &Error{Code: http.StatusBadRequest, Message: schemaMessage(err)}
+	}
+	var pet NewPet
+	if err := json.Unmarshal(raw, &pet); err != nil {
+		return NewPet{}, &Error{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	return pet, nil
+}
+
+// schemaMessage 只保留 JSON Pointer 与原因，不带 kin-openapi 附加的 schema 与取值
+func schemaMessage(err error) string {
+	var se *openapi3.SchemaError
+	if !errors.As(err, &se) {
+		return err.Error()
+	}
+	if pointer := se.JSONPointer(); len(pointer) > 0 {
+		return "/" + strings.Join(pointer, "/") + ": " + se.Reason
+	}
+	return se.Reason
+}
+
+// progressEvery 后台执行时每校验多少项报告一次进度
+const progressEvery = 100
+
+// importPets 逐项校验后写入 store，结果按请求中的顺序排列。
+// atomic 模式下任一项无效时什么都不写并返回 422，有效的项只带 index。
+// 在后台执行时报告校验的进度，写入之前 ctx 已取消则什么都不写并返回 ctx.Err()
+func importPets(ctx context.Context, store *PetStore, items []json.RawMessage, mode *BulkImportPetsParamsMode) (int, BulkImportResult, error) {
+	result := BulkImportResult{Items: make([]BulkItemResult, len(items))}
+	pets := make([]NewPet, 0, len(items))
+	valid := make([]int, 0, len(items))
+	total := int64(len(items))
+	for i, raw := range items {
+		if i%progressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, BulkImportResult{}, err
+			}
+			operations.SetProgress(ctx, int64(i), total)
+		}
+		result.Items[i].Index = int32(i)
+		pet, e := validateNewPet(raw)
+		if e != nil {
+			result.Items[i].Error = e
+			result.Failed++
+			continue
+		}
+		pets = append(pets, pet)
+		valid = append(valid, i)
+	}
+	operations.SetProgress(ctx, total, total)
+	if mode != nil && *mode == Atomic && result.Failed > 0 {
+		return http.StatusUnprocessableEntity, result, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, BulkImportResult{}, err
+	}
+	for j, pet := range store.AddAll(pets) {
+		id := pet.Id
+		result.Items[valid[j]].Id = &id
+	}
+	result.Created = int32(len(pets))
+	return http.StatusOK, result, nil
+}
+
+// exportCSV 按 id,name,tag 逐行写出游标中的宠物，每 codec.FlushEvery 行调用一次 flush
+func exportCSV(w io.Writer, flush func(), cursor *PetCursor) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "name", "tag"}); err != nil {
+		return err
+	}
+	for n := 1; cursor.Next(); n++ {
+		pet := cursor.Pet()
+		tag := ""
+		if pet.Tag != nil {
+			tag = *pet.Tag
+		}
+		if err := cw.Write([]string{strconv.FormatInt(pet.Id, 10), pet.Name, tag}); err != nil {
+			return err
+		}
+		if n%codec.FlushEvery == 0 {
+			cw.Flush()
+			flush()
+		}
+	}
+	cw.Flush()
+	flush()
+	return cw.Error()
+}
+
+// exportTags 把可选的 tags 展开
+func exportTags(params ExportPetsParams) []string {
+	if params.Tags == nil {
+		return nil
+	}
+	return *params.Tags
+}
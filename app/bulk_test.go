@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCSV(t *testing.T) {
+	for _, server := range petTestServers() {
+		t.Run(server.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo_test/pets:bulk", strings.NewReader(
+				`[{"name":"rex","tag":"dog"},{"name":"tom, the cat","tag":"cat"},{"name":"fido"}]`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			server.handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			req = httptest.NewRequest(http.MethodGet, "/echo_test/pets:export", nil)
+			req.Header.Set("Accept", "text/csv, application/json;q=0.5")
+			rec = httptest.NewRecorder()
+			server.handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, codec.MIMETextCSV, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "id,name,tag\n1,rex,dog\n2,\"tom, the cat\",cat\n3,fido,\n", rec.Body.String())
+
+			req = httptest.NewRequest(http.MethodGet, "/echo_test/pets:export?tags=cat", nil)
+			rec = httptest.NewRecorder()
+			server.handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json"))
+			assert.JSONEq(t, `[{"id":2,"name":"tom, the cat","tag":"cat"}]`, rec.Body.String())
+		})
+	}
+}
+
+func TestBulkImportNDJSONLines(t *testing.T) {
+	// 与请求校验相同的分行：一行里的两个值是一个无效的项，不是两项
+	body := "{\"name\":\"rex\"}\n{\"name\":\"a\"} {\"name\":\"b\"}\nnot json\n"
+	for _, server := range petTestServers() {
+		t.Run(server.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo_test/pets:bulk", strings.NewReader(body))
+			req.Header.Set("Content-Type", codec.MIMEApplicationNDJSON)
+			rec := httptest.NewRecorder()
+			server.handler.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var result codegenTest.BulkImportResult
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.EqualValues(t, 1, result.Created)
+			assert.EqualValues(t, 2, result.Failed)
+			require.Len(t, result.Items, 3)
+			assert.NotNil(t, result.Items[1].Error)
+		})
+	}
+}
+
+// newBulkClient 通过真实的 HTTP 连接访问以 store 挂载的 Echo 实现
+func newBulkClient(t *testing.T, store *PetStore) codegenTest.ClientInterface {
+	e := echo.New()
+	codegenTest.RegisterHandlersWithBaseURL(e, &EchoServer{Store: store}, "/echo_test")
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	client, err := codegenTest.NewClient(server.URL + "/echo_test")
+	require.NoError(t, err)
+	return client
+}
+
+func newPets(n int) []codegenTest.NewPet {
+	pets := make([]codegenTest.NewPet, n)
+	for i := range pets {
+		pets[i].Name = fmt.Sprintf("pet%d", i)
+	}
+	return pets
+}
+
+func TestBulkImportClient(t *testing.T) {
+	store := NewPetStore()
+	client := newBulkClient(t, store)
+
+	var mu sync.Mutex
+	var progress []codegenTest.BulkImportProgress
+	result, err := codegenTest.BulkImport(context.Background(), client, newPets(25), codegenTest.BulkImportOptions{
+		ChunkSize:   4,
+		Concurrency: 3,
+		Progress: func(p codegenTest.BulkImportProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p)
+		},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 25, result.Created)
+	assert.EqualValues(t, 0, result.Failed)
+	require.Len(t, result.Items, 25)
+	for i, item := range result.Items {
+		assert.EqualValues(t, i, item.Index)
+		require.NotNil(t, item.Id, i)
+		pet, ok := store.Get(*item.Id)
+		require.True(t, ok)
+		assert.Equal(t, fmt.Sprintf("pet%d", i), pet.Name)
+	}
+	require.Len(t, progress, 7)
+	for i := 1; i < len(progress); i++ {
+		assert.Greater(t, progress[i].Done, progress[i-1].Done)
+	}
+	assert.Equal(t, codegenTest.BulkImportProgress{Done: 25, Total: 25, Created: 25}, progress[6])
+}
+
+// rejectingServer 模拟 schema 比客户端更严格的服务端：名字为 bad 的项无效，其余按 atomic 语义处理
+func rejectingServer(t *testing.T, requests *atomic.Int32) codegenTest.ClientInterface {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		items, err := decodeBulkItems(r.Header.Get("Content-Type"), r.Body)
+		if err != nil {
+			// 不在测试 goroutine 中，不能调用 require；500 会让客户端返回 *ResponseError
+			writeJSON(w, http.StatusInternalServerError, codegenTest.Error{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		result := codegenTest.BulkImportResult{Items: make([]codegenTest.BulkItemResult, len(items))}
+		for i, item := range items {
+			result.Items[i].Index = int32(i)
+			if strings.Contains(string(item), `"bad"`) {
+				result.Items[i].Error = &codegenTest.Error{Code: http.StatusBadRequest, Message: "bad name"}
+				result.Failed++
+			}
+		}
+		code := http.StatusOK
+		if result.Failed > 0 {
+			code = http.StatusUnprocessableEntity
+		}
+		writeJSON(w, code, result)
+	}))
+	t.Cleanup(server.Close)
+	client, err := codegenTest.NewClient(server.URL)
+	require.NoError(t, err)
+	return client
+}
+
+func TestBulkImportClientAtomic(t *testing.T) {
+	var requests atomic.Int32
+	client := rejectingServer(t, &requests)
+	pets := newPets(12)
+	pets[5].Name = "bad"
+
+	// atomic 不分片：ChunkSize 被忽略，一个请求里的任何一项无效都会拒绝全部
+	result, err := codegenTest.BulkImport(context.Background(), client, pets, codegenTest.BulkImportOptions{
+		Mode:      codegenTest.Atomic,
+		ChunkSize: 4,
+	})
+	assert.ErrorIs(t, err, codegenTest.ErrBulkImportAborted)
+	assert.EqualValues(t, 1, requests.Load())
+	assert.EqualValues(t, 1, result.Failed)
+	require.Len(t, result.Items, 12)
+	assert.EqualValues(t, 5, result.Items[5].Index)
+	assert.NotNil(t, result.Items[5].Error)
+}
+
+func TestBulkImportClientUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, codegenTest.Error{Code: http.StatusServiceUnavailable, Message: "maintenance"})
+	}))
+	defer server.Close()
+	client, err := codegenTest.NewClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = codegenTest.BulkImport(context.Background(), client, newPets(10), codegenTest.BulkImportOptions{ChunkSize: 2})
+	var responseErr *codegenTest.ResponseError
+	require.ErrorAs(t, err, &responseErr)
+	assert.Equal(t, http.StatusServiceUnavailable, responseErr.StatusCode)
+	assert.Equal(t, "maintenance", responseErr.Body.Message)
+}
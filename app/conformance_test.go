@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/ginserver"
+	"demo/oapi-codegen-go/httpserver"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const conformanceBaseURL = "/api"
+
+// conformanceServers 每个用例都新建实例，保证仓库状态从零开始
+var conformanceServers = []struct {
+	name string
+	new  func(t *testing.T) http.Handler
+}{
+	{"echo", func(t *testing.T) http.Handler {
+		e := newConformanceEcho(t)
+		codegenTest.RegisterHandlersWithBaseURL(e, &EchoServer{}, conformanceBaseURL)
+		return e
+	}},
+	{"echo-strict", func(t *testing.T) http.Handler {
+		e := newConformanceEcho(t)
+		codegenTest.RegisterHandlersWithBaseURL(e, codegenTest.NewStrictHandler(NewStrictServer(nil), nil), conformanceBaseURL)
+		return e
+	}},
+	{"gin", func(t *testing.T) http.Handler {
+		r := newConformanceGin(t)
+		ginserver.RegisterHandlersWithOptions(r, &GinServer{}, ginserver.GinServerOptions{BaseURL: conformanceBaseURL})
+		return r
+	}},
+	{"gin-strict", func(t *testing.T) http.Handler {
+		r := newConformanceGin(t)
+		ginserver.RegisterHandlersWithOptions(r, ginserver.NewStrictHandler(NewStrictServer(nil), nil), ginserver.GinServerOptions{BaseURL: conformanceBaseURL})
+		return r
+	}},
+	{"net/http", func(t *testing.T) http.Handler {
+		return newConformanceHttp(t, &HttpServer{})
+	}},
+	{"net/http-strict", func(t *testing.T) http.Handler {
+		return newConformanceHttp(t, httpserver.NewStrictHandler(NewStrictServer(nil), nil))
+	}},
+}
+
+func newConformanceEcho(t *testing.T) *echo.Echo {
+	swagger, err := codegenTest.GetSwaggerWithPrefix(conformanceBaseURL)
+	require.NoError(t, err)
+	e := echo.New()
+	e.Use(middleware.OapiRequestValidator(swagger))
+	return e
+}
+
+func newConformanceGin(t *testing.T) *gin.Engine {
+	swagger, err := codegenTest.GetSwaggerWithPrefix(conformanceBaseURL)
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ginserver.OapiRequestValidator(swagger))
+	return r
+}
+
+func newConformanceHttp(t *testing.T, si httpserver.ServerInterface) http.Handler {
+	swagger, err := codegenTest.GetSwaggerWithPrefix(conformanceBaseURL)
+	require.NoError(t, err)
+	mux := httpserver.HandlerWithOptions(si, httpserver.StdHTTPServerOptions{BaseURL: conformanceBaseURL})
+	return httpserver.OapiRequestValidator(swagger)(mux)
+}
+
+type conformanceStep struct {
+	name        string
+	method      string
+	path        string
+	contentType string
+	body        string
+	status      int
+}
+
+// 按顺序执行，后面的步骤依赖前面写入的数据
+var conformanceSteps = []conformanceStep{
+	{"add first", http.MethodPost, "/pets", "application/json", `{"name":"baby","tag":"cat"}`, http.StatusOK},
+	{"add second", http.MethodPost, "/pets", "application/json", `{"name":"kitty"}`, http.StatusOK},
+	{"add missing name", http.MethodPost, "/pets", "application/json", `{"tag":"cat"}`, http.StatusBadRequest},
+	{"add wrong content type", http.MethodPost, "/pets", "text/plain", `name=baby`, http.StatusBadRequest},
+	{"list all", http.MethodGet, "/pets", "", "", http.StatusOK},
+	{"list by tag", http.MethodGet, "/pets?tags=cat", "", "", http.StatusOK},
+	{"list with limit", http.MethodGet, "/pets?limit=12", "", "", http.StatusOK},
+	{"limit below minimum", http.MethodGet, "/pets?limit=1", "", "", http.StatusBadRequest},
+	{"limit not integer", http.MethodGet, "/pets?limit=abc", "", "", http.StatusBadRequest},
+	{"find by id", http.MethodGet, "/pets/1", "", "", http.StatusOK},
+	{"find by bad id", http.MethodGet, "/pets/abc", "", "", http.StatusBadRequest},
+	{"find missing", http.MethodGet, "/pets/99", "", "", http.StatusNotFound},
+	{"delete", http.MethodDelete, "/pets/2", "", "", http.StatusNoContent},
+	{"delete again", http.MethodDelete, "/pets/2", "", "", http.StatusNotFound},
+	{"list after delete", http.MethodGet, "/pets", "", "", http.StatusOK},
+	{"bulk best effort", http.MethodPost, "/pets:bulk", "application/json", `[{"name":"rex","tag":"dog"},{"tag":"dog"},{"name":"fido"}]`, http.StatusOK},
+	{"bulk ndjson", http.MethodPost, "/pets:bulk", "application/x-ndjson", "{\"name\":\"max\",\"tag\":\"dog\"}\n\n{\"name\":5}\n", http.StatusOK},
+	{"bulk ndjson invalid line", http.MethodPost, "/pets:bulk", "application/x-ndjson", "{\"name\":\"max\"}\n{\"name\":\"a\"} {\"name\":\"b\"}\n", http.StatusBadRequest},
+	{"bulk atomic rejected", http.MethodPost, "/pets:bulk?mode=atomic", "application/json", `[{"name":"a"},{}]`, http.StatusUnprocessableEntity},
+	{"bulk atomic", http.MethodPost, "/pets:bulk?mode=atomic", "application/json", `[{"name":"a"},{"name":"b"}]`, http.StatusOK},
+	{"bulk unknown mode", http.MethodPost, "/pets:bulk?mode=all", "application/json", `[]`, http.StatusBadRequest},
+	{"bulk not an array", http.MethodPost, "/pets:bulk", "application/json", `{"name":"a"}`, http.StatusBadRequest},
+	{"export by tag", http.MethodGet, "/pets:export?tags=dog", "", "", http.StatusOK},
+	{"operation not found", http.MethodGet, "/operations/unknown", "", "", http.StatusNotFound},
+	{"cancel operation not found", http.MethodDelete, "/operations/unknown", "", "", http.StatusNotFound},
+	{"unknown custom method", http.MethodGet, "/pets:import", "", "", http.StatusNotFound},
+	{"unknown path", http.MethodGet, "/owners", "", "", http.StatusNotFound},
+	{"unknown method", http.MethodPut, "/pets", "application/json", `{}`, http.StatusNotFound},
+}
+
+func TestConformance(t *testing.T) {
+	// results[server][step] 保存解码后的响应体，最后与 echo 的结果逐一比对
+	results := make(map[string][]interface{})
+	for _, server := range conformanceServers {
+		t.Run(server.name, func(t *testing.T) {
+			handler := server.new(t)
+			for _, step := range conformanceSteps {
+				req := httptest.NewRequest(step.method, conformanceBaseURL+step.path, strings.NewReader(step.body))
+				if step.contentType != "" {
+					req.Header.Set("Content-Type", step.contentType)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				require.Equal(t, step.status, rec.Code, "%s: %s", step.name, rec.Body.String())
+
+				var decoded interface{}
+				if rec.Body.Len() > 0 {
+					require.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json"), step.name)
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded), step.name)
+				}
+				results[server.name] = append(results[server.name], decoded)
+			}
+		})
+	}
+
+	reference := results[conformanceServers[0].name]
+	for _, server := range conformanceServers[1:] {
+		got := results[server.name]
+		require.Len(t, got, len(reference), server.name)
+		for i, step := range conformanceSteps {
+			assert.Equal(t, reference[i], got[i], "%s differs from %s on %q", server.name, conformanceServers[0].name, step.name)
+		}
+	}
+}
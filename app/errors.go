@@ -0,0 +1,42 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/violations"
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler 把 echo.HTTPError（包括校验失败和参数绑定失败）按 Error schema 输出，
+// 格式按 Accept 协商，客户端的 ParseXxxResponse 可以解析到 JSONDefault。violations.Body 在 Error 的基础上
+// 多出逐条的 violations，原样输出。
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	code := http.StatusInternalServerError
+	var body interface{} = Error{Code: int32(code), Message: http.StatusText(code)}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = he.Code
+		switch msg := he.Message.(type) {
+		case string:
+			body = Error{Code: int32(code), Message: msg}
+		case *violations.Body:
+			body = msg
+		default:
+			body = Error{Code: int32(code), Message: http.StatusText(code)}
+		}
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(code)
+	} else {
+		err = codec.Respond(c, code, body)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"net/http"
+	"sync"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/operations"
+	"github.com/gin-gonic/gin"
+)
+
+// GinServer 实现 ginserver.ServerInterface，行为与 EchoServer 一致
+type GinServer struct {
+	// Store 为空时首次使用自动创建
+	Store *PetStore
+	// Operations 为空时没有后台任务，GET/DELETE /operations/{id} 总是 404
+	Operations *operations.Manager
+	once       sync.Once
+}
+
+func (g *GinServer) store() *PetStore {
+	g.once.Do(func() {
+		if g.Store == nil {
+			g.Store = NewPetStore()
+		}
+	})
+	return g.Store
+}
+
+func (g *GinServer) CancelOperation(c *gin.Context, id string) {
+	op, err := cancelOperation(g.Operations, id)
+	if err != nil {
+		c.JSON(operationFailure(err))
+		return
+	}
+	c.JSON(http.StatusOK, op)
+}
+
+func (g *GinServer) GetOperation(c *gin.Context, id string) {
+	op, err := getOperation(g.Operations, id)
+	if err != nil {
+		c.JSON(operationFailure(err))
+		return
+	}
+	c.JSON(http.StatusOK, op)
+}
+
+func (g *GinServer) FindPets(c *gin.Context, params FindPetsParams) {
+	tags, limit := findPetsArgs(params)
+	c.JSON(http.StatusOK, g.store().Find(tags, limit))
+}
+
+func (g *GinServer) AddPet(c *gin.Context) {
+	pet := AddPetJSONRequestBody{}
+	if err := c.ShouldBindJSON(&pet); err != nil {
+		c.JSON(http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, g.store().Add(pet))
+}
+
+func (g *GinServer) DeletePet(c *gin.Context, id int64) {
+	if !g.store().Delete(id) {
+		c.JSON(http.StatusNotFound, petNotFound())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (g *GinServer) FindPetById(c *gin.Context, id int64) {
+	pet, ok := g.store().Get(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, petNotFound())
+		return
+	}
+	c.JSON(http.StatusOK, pet)
+}
+
+func (g *GinServer) BulkImportPets(c *gin.Context, params BulkImportPetsParams) {
+	items, err := decodeBulkItems(c.ContentType(), c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	code, result, err := importPets(c.Request.Context(), g.store(), items, params.Mode)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(code, result)
+}
+
+func (g *GinServer) ExportPets(c *gin.Context, params ExportPetsParams) {
+	c.Header("Vary", "Accept")
+	if codec.Select(c.GetHeader("Accept"), codec.MIMEApplicationJSON, codec.MIMETextCSV) == codec.MIMETextCSV {
+		c.Header("Content-Type", codec.MIMETextCSV)
+		c.Status(http.StatusOK)
+		if err := exportCSV(c.Writer, c.Writer.Flush, g.store().Cursor(exportTags(params), 0)); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, g.store().Find(exportTags(params), 0))
+}
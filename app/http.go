@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/operations"
+)
+
+// HttpServer 实现 httpserver.ServerInterface，行为与 EchoServer 一致
+type HttpServer struct {
+	// Store 为空时首次使用自动创建
+	Store *PetStore
+	// Operations 为空时没有后台任务，GET/DELETE /operations/{id} 总是 404
+	Operations *operations.Manager
+	once       sync.Once
+}
+
+func (h *HttpServer) store() *PetStore {
+	h.once.Do(func() {
+		if h.Store == nil {
+			h.Store = NewPetStore()
+		}
+	})
+	return h.Store
+}
+
+func (h *HttpServer) CancelOperation(w http.ResponseWriter, r *http.Request, id string) {
+	op, err := cancelOperation(h.Operations, id)
+	if err != nil {
+		code, body := operationFailure(err)
+		writeJSON(w, code, body)
+		return
+	}
+	writeJSON(w, http.StatusOK, op)
+}
+
+func (h *HttpServer) GetOperation(w http.ResponseWriter, r *http.Request, id string) {
+	op, err := getOperation(h.Operations, id)
+	if err != nil {
+		code, body := operationFailure(err)
+		writeJSON(w, code, body)
+		return
+	}
+	writeJSON(w, http.StatusOK, op)
+}
+
+func (h *HttpServer) FindPets(w http.ResponseWriter, r *http.Request, params FindPetsParams) {
+	tags, limit := findPetsArgs(params)
+	writeJSON(w, http.StatusOK, h.store().Find(tags, limit))
+}
+
+func (h *HttpServer) AddPet(w http.ResponseWriter, r *http.Request) {
+	pet := AddPetJSONRequestBody{}
+	if err := json.NewDecoder(r.Body).Decode(&pet); err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.store().Add(pet))
+}
+
+func (h *HttpServer) DeletePet(w http.ResponseWriter, r *http.Request, id int64) {
+	if !h.store().Delete(id) {
+		writeJSON(w, http.StatusNotFound, petNotFound())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HttpServer) FindPetById(w http.ResponseWriter, r *http.Request, id int64) {
+	pet, ok := h.store().Get(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, petNotFound())
+		return
+	}
+	writeJSON(w, http.StatusOK, pet)
+}
+
+func (h *HttpServer) BulkImportPets(w http.ResponseWriter, r *http.Request, params BulkImportPetsParams) {
+	items, err := decodeBulkItems(r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	code, result, err := importPets(r.Context(), h.store(), items, params.Mode)
+	if err != nil {
+		// 只有客户端断开时才会取消，没有人读取响应
+		return
+	}
+	writeJSON(w, code, result)
+}
+
+func (h *HttpServer) ExportPets(w http.ResponseWriter, r *http.Request, params ExportPetsParams) {
+	w.Header().Add("Vary", "Accept")
+	if codec.Select(r.Header.Get("Accept"), codec.MIMEApplicationJSON, codec.MIMETextCSV) == codec.MIMETextCSV {
+		w.Header().Set("Content-Type", codec.MIMETextCSV)
+		w.WriteHeader(http.StatusOK)
+		flush := func() {}
+		if f, ok := w.(http.Flusher); ok {
+			flush = f.Flush
+		}
+		_ = exportCSV(w, flush, h.store().Cursor(exportTags(params), 0))
+		return
+	}
+	writeJSON(w, http.StatusOK, h.store().Find(exportTags(params), 0))
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
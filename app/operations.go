@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/operations"
+)
+
+// getOperation 查询后台任务；m 为 nil 表示没有启用异步执行，总是 ErrNotFound
+func getOperation(m *operations.Manager, id string) (Operation, error) {
+	if m == nil {
+		return Operation{}, operations.ErrNotFound
+	}
+	return m.Get(id)
+}
+
+// cancelOperation 取消后台任务，已经结束的任务返回 ErrFinished
+func cancelOperation(m *operations.Manager, id string) (Operation, error) {
+	if m == nil {
+		return Operation{}, operations.ErrNotFound
+	}
+	return m.Cancel(id)
+}
+
+// operationFailure 把 Manager 返回的错误转换为状态码与响应体
+func operationFailure(err error) (int, Error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, operations.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, operations.ErrFinished):
+		code = http.StatusConflict
+	}
+	return code, Error{Code: int32(code), Message: err.Error()}
+}
@@ -2,24 +2,45 @@ package app
 
 import (
 	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/httpserver"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 )
 
-func TestAddPet(t *testing.T) {
+// petTestServers 返回挂载在 /echo_test 下的 Echo 与 net/http 两种实现
+func petTestServers() []struct {
+	name    string
+	handler http.Handler
+} {
 	e := echo.New()
-	recorder := httptest.NewRecorder()
 	codegenTest.RegisterHandlersWithBaseURL(e, &EchoServer{}, "/echo_test")
-	tag := "tag1"
-	body := codegenTest.AddPetJSONRequestBody{
-		Name: "baby",
-		Tag:  &tag,
+	mux := httpserver.HandlerWithOptions(&HttpServer{}, httpserver.StdHTTPServerOptions{BaseURL: "/echo_test"})
+	return []struct {
+		name    string
+		handler http.Handler
+	}{
+		{"echo", e},
+		{"net/http", mux},
+	}
+}
+
+func TestAddPet(t *testing.T) {
+	for _, server := range petTestServers() {
+		t.Run(server.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tag := "tag1"
+			body := codegenTest.AddPetJSONRequestBody{
+				Name: "baby",
+				Tag:  &tag,
+			}
+			request, _ := codegenTest.NewAddPetRequest("/echo_test/", body)
+			server.handler.ServeHTTP(recorder, request)
+			response, err := codegenTest.ParseAddPetResponse(recorder.Result())
+			assert.Nil(t, err)
+			assert.Equal(t, body.Name, response.JSON200.Name)
+		})
 	}
-	request, _ := codegenTest.NewAddPetRequest("/echo_test/", body)
-	e.ServeHTTP(recorder, request)
-	response, err := codegenTest.ParseAddPetResponse(recorder.Result())
-	assert.Nil(t, err)
-	assert.Equal(t, body.Name, response.JSON200.Name)
 }
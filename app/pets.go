@@ -2,34 +2,105 @@ package app
 
 import (
 	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/operations"
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"sync"
 )
 
-type EchoServer struct{}
+type EchoServer struct {
+	// Store 为空时首次使用自动创建
+	Store *PetStore
+	// Operations 为空时没有后台任务，GET/DELETE /operations/{id} 总是 404
+	Operations *operations.Manager
+	once       sync.Once
+}
+
+func (e *EchoServer) store() *PetStore {
+	e.once.Do(func() {
+		if e.Store == nil {
+			e.Store = NewPetStore()
+		}
+	})
+	return e.Store
+}
+
+func (e *EchoServer) CancelOperation(ctx echo.Context, id string) error {
+	op, err := cancelOperation(e.Operations, id)
+	if err != nil {
+		code, body := operationFailure(err)
+		return codec.Respond(ctx, code, body)
+	}
+	return codec.Respond(ctx, http.StatusOK, op)
+}
+
+func (e *EchoServer) GetOperation(ctx echo.Context, id string) error {
+	op, err := getOperation(e.Operations, id)
+	if err != nil {
+		code, body := operationFailure(err)
+		return codec.Respond(ctx, code, body)
+	}
+	return codec.Respond(ctx, http.StatusOK, op)
+}
 
 func (e *EchoServer) FindPets(ctx echo.Context, params FindPetsParams) error {
-	// todo 业务逻辑
-	return ctx.JSON(http.StatusOK, "find list")
+	tags, limit := findPetsArgs(params)
+	// NDJSON 直接从游标写出，不在内存中构造整个列表
+	if codec.AcceptsNDJSON(ctx.Request().Header.Get(echo.HeaderAccept)) {
+		cursor := e.store().Cursor(tags, limit)
+		return codec.StreamNDJSON(ctx, http.StatusOK, func() (interface{}, bool) {
+			if !cursor.Next() {
+				return nil, false
+			}
+			return cursor.Pet(), true
+		})
+	}
+	return codec.Respond(ctx, http.StatusOK, e.store().Find(tags, limit))
 }
 
 func (e *EchoServer) AddPet(ctx echo.Context) error {
 	pet := AddPetJSONRequestBody{}
-	_ = ctx.Bind(&pet)
-	resp := Pet{
-		Id:   1,
-		Name: pet.Name,
-		Tag:  pet.Tag,
+	if err := ctx.Bind(&pet); err != nil {
+		return codec.Respond(ctx, http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
 	}
-	return ctx.JSON(http.StatusOK, resp)
+	return codec.Respond(ctx, http.StatusOK, e.store().Add(pet))
 }
 
 func (e *EchoServer) DeletePet(ctx echo.Context, id int64) error {
-	// todo 业务逻辑
-	return ctx.JSON(http.StatusOK, "delete")
+	if !e.store().Delete(id) {
+		return codec.Respond(ctx, http.StatusNotFound, petNotFound())
+	}
+	return ctx.NoContent(http.StatusNoContent)
 }
 
 func (e *EchoServer) FindPetById(ctx echo.Context, id int64) error {
-	// todo 业务逻辑
-	return ctx.JSON(http.StatusOK, "find single")
+	pet, ok := e.store().Get(id)
+	if !ok {
+		return codec.Respond(ctx, http.StatusNotFound, petNotFound())
+	}
+	return codec.Respond(ctx, http.StatusOK, pet)
+}
+
+func (e *EchoServer) BulkImportPets(ctx echo.Context, params BulkImportPetsParams) error {
+	items, err := decodeBulkItems(ctx.Request().Header.Get(echo.HeaderContentType), ctx.Request().Body)
+	if err != nil {
+		return codec.Respond(ctx, http.StatusBadRequest, Error{Code: http.StatusBadRequest, Message: err.Error()})
+	}
+	code, result, err := importPets(ctx.Request().Context(), e.store(), items, params.Mode)
+	if err != nil {
+		return err
+	}
+	return codec.Respond(ctx, code, result)
+}
+
+func (e *EchoServer) ExportPets(ctx echo.Context, params ExportPetsParams) error {
+	res := ctx.Response()
+	res.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	if codec.Select(ctx.Request().Header.Get(echo.HeaderAccept), codec.MIMEApplicationJSON, codec.MIMETextCSV) == codec.MIMETextCSV {
+		res.Header().Set(echo.HeaderContentType, codec.MIMETextCSV)
+		res.WriteHeader(http.StatusOK)
+		return exportCSV(res, res.Flush, e.store().Cursor(exportTags(params), 0))
+	}
+	return ctx.JSON(http.StatusOK, e.store().Find(exportTags(params), 0))
 }
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"sort"
+	"sync"
+
+	. "demo/oapi-codegen-go"
+)
+
+// PetStore 内存中的宠物仓库，Echo / gin 等各种 server 实现共用
+type PetStore struct {
+	mu     sync.RWMutex
+	pets   map[int64]Pet
+	nextID int64
+}
+
+func NewPetStore() *PetStore {
+	return &PetStore{pets: make(map[int64]Pet), nextID: 1}
+}
+
+// Add 新增宠物并分配 id
+func (s *PetStore) Add(newPet NewPet) Pet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pet := Pet{Id: s.nextID, Name: newPet.Name, Tag: newPet.Tag}
+	s.pets[pet.Id] = pet
+	s.nextID++
+	return pet
+}
+
+// AddAll 在同一把锁内新增全部宠物，id 连续分配，其它请求看不到只写入一部分的状态
+func (s *PetStore) AddAll(newPets []NewPet) []Pet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pets := make([]Pet, len(newPets))
+	for i, newPet := range newPets {
+		pets[i] = Pet{Id: s.nextID, Name: newPet.Name, Tag: newPet.Tag}
+		s.pets[pets[i].Id] = pets[i]
+		s.nextID++
+	}
+	return pets
+}
+
+// Get 按 id 查询
+func (s *PetStore) Get(id int64) (Pet, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pet, ok := s.pets[id]
+	return pet, ok
+}
+
+// Delete 按 id 删除，不存在时返回 false
+func (s *PetStore) Delete(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pets[id]; !ok {
+		return false
+	}
+	delete(s.pets, id)
+	return true
+}
+
+// Find 按 id 升序返回 tag 命中 tags 的宠物；tags 为空不过滤，limit <= 0 不限制数量
+func (s *PetStore) Find(tags []string, limit int) []Pet {
+	cursor := s.Cursor(tags, limit)
+	result := make([]Pet, 0, len(cursor.ids))
+	for cursor.Next() {
+		result = append(result, cursor.Pet())
+	}
+	return result
+}
+
+// Cursor 与 Find 的条件和顺序相同，但逐个读取宠物，不会一次复制整个结果。
+// 创建时只复制 id，遍历期间删除的宠物被跳过，新增的不会出现
+func (s *PetStore) Cursor(tags []string, limit int) *PetCursor {
+	s.mu.RLock()
+	ids := make([]int64, 0, len(s.pets))
+	for id := range s.pets {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return &PetCursor{store: s, ids: ids, tags: tags, limit: limit}
+}
+
+// PetCursor 见 PetStore.Cursor，不能并发使用
+type PetCursor struct {
+	store *PetStore
+	ids   []int64
+	tags  []string
+	limit int
+	n     int
+	pet   Pet
+}
+
+// Next 前进到下一个符合条件的宠物，没有更多时返回 false
+func (c *PetCursor) Next() bool {
+	for len(c.ids) > 0 && (c.limit <= 0 || c.n < c.limit) {
+		id := c.ids[0]
+		c.ids = c.ids[1:]
+		pet, ok := c.store.Get(id)
+		if ok && matchTags(pet, c.tags) {
+			c.pet = pet
+			c.n++
+			return true
+		}
+	}
+	return false
+}
+
+// Pet 当前的宠物
+func (c *PetCursor) Pet() Pet {
+	return c.pet
+}
+
+func matchTags(pet Pet, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	if pet.Tag == nil {
+		return false
+	}
+	for _, tag := range tags {
+		if *pet.Tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// findPetsArgs 把可选的查询参数展开
+func findPetsArgs(params FindPetsParams) (tags []string, limit int) {
+	if params.Tags != nil {
+		tags = *params.Tags
+	}
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+	return
+}
+
+// petNotFound 各实现统一的 404 响应体
+func petNotFound() Error {
+	return Error{Code: 404, Message: "pet not found"}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	. "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/operations"
+)
+
+// StrictServer 实现 StrictServerInterface，与框架无关，可通过各框架的 NewStrictHandler 挂载
+type StrictServer struct {
+	Store *PetStore
+	// Operations 为空时没有后台任务，GET/DELETE /operations/{id} 总是 404
+	Operations *operations.Manager
+}
+
+func NewStrictServer(store *PetStore) *StrictServer {
+	if store == nil {
+		store = NewPetStore()
+	}
+	return &StrictServer{Store: store}
+}
+
+func (s *StrictServer) CancelOperation(ctx context.Context, request CancelOperationRequestObject) (CancelOperationResponseObject, error) {
+	op, err := cancelOperation(s.Operations, request.Id)
+	if err != nil {
+		code, body := operationFailure(err)
+		switch code {
+		case http.StatusNotFound:
+			return CancelOperation404JSONResponse(body), nil
+		case http.StatusConflict:
+			return CancelOperation409JSONResponse(body), nil
+		}
+		return CancelOperationdefaultJSONResponse{Body: body, StatusCode: code}, nil
+	}
+	return CancelOperation200JSONResponse(op), nil
+}
+
+func (s *StrictServer) GetOperation(ctx context.Context, request GetOperationRequestObject) (GetOperationResponseObject, error) {
+	op, err := getOperation(s.Operations, request.Id)
+	if err != nil {
+		code, body := operationFailure(err)
+		if code == http.StatusNotFound {
+			return GetOperation404JSONResponse(body), nil
+		}
+		return GetOperationdefaultJSONResponse{Body: body, StatusCode: code}, nil
+	}
+	return GetOperation200JSONResponse(op), nil
+}
+
+func (s *StrictServer) FindPets(ctx context.Context, request FindPetsRequestObject) (FindPetsResponseObject, error) {
+	tags, limit := findPetsArgs(request.Params)
+	return FindPets200JSONResponse(s.Store.Find(tags, limit)), nil
+}
+
+func (s *StrictServer) AddPet(ctx context.Context, request AddPetRequestObject) (AddPetResponseObject, error) {
+	return AddPet200JSONResponse(s.Store.Add(*request.Body)), nil
+}
+
+func (s *StrictServer) DeletePet(ctx context.Context, request DeletePetRequestObject) (DeletePetResponseObject, error) {
+	if !s.Store.Delete(request.Id) {
+		return DeletePetdefaultJSONResponse{Body: petNotFound(), StatusCode: 404}, nil
+	}
+	return DeletePet204Response{}, nil
+}
+
+func (s *StrictServer) FindPetById(ctx context.Context, request FindPetByIdRequestObject) (FindPetByIdResponseObject, error) {
+	pet, ok := s.Store.Get(request.Id)
+	if !ok {
+		return FindPetByIddefaultJSONResponse{Body: petNotFound(), StatusCode: 404}, nil
+	}
+	return FindPetById200JSONResponse(pet), nil
+}
+
+func (s *StrictServer) BulkImportPets(ctx context.Context, request BulkImportPetsRequestObject) (BulkImportPetsResponseObject, error) {
+	var items []json.RawMessage
+	var err error
+	if request.JSONBody != nil {
+		items, err = jsonBulkItems(*request.JSONBody)
+	} else if request.Body != nil {
+		items, err = decodeBulkItems(codec.MIMEApplicationNDJSON, request.Body)
+	} else {
+		err = errUnsupportedBulkBody
+	}
+	if err != nil {
+		return BulkImportPetsdefaultJSONResponse{Body: Error{Code: http.StatusBadRequest, Message: err.Error()}, StatusCode: http.StatusBadRequest}, nil
+	}
+	code, result, err := importPets(ctx, s.Store, items, request.Params.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if code == http.StatusUnprocessableEntity {
+		return BulkImportPets422JSONResponse(result), nil
+	}
+	return BulkImportPets200JSONResponse(result), nil
+}
+
+// ExportPets strict 模式看不到 Accept，总是返回 JSON
+func (s *StrictServer) ExportPets(ctx context.Context, request ExportPetsRequestObject) (ExportPetsResponseObject, error) {
+	return ExportPets200JSONResponse(s.Store.Find(exportTags(request.Params), 0)), nil
+}
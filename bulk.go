@@ -0,0 +1,154 @@
+package codegen_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"demo/oapi-codegen-go/codec"
+)
+
+// BulkImport 的默认分片大小与并发数
+const (
+	DefaultBulkChunkSize   = 500
+	DefaultBulkConcurrency = 4
+)
+
+// ErrBulkImportAborted atomic 模式下有无效的项，整个请求被拒绝，什么都没有写入
+var ErrBulkImportAborted = errors.New("bulk import aborted: atomic import was rejected, nothing was written")
+
+// BulkImportOptions 控制 BulkImport
+type BulkImportOptions struct {
+	// Mode 为空时按 BestEffort
+	Mode BulkImportPetsParamsMode
+	// ChunkSize 每个请求携带的宠物数，0 表示 DefaultBulkChunkSize，不能超过 spec 的 maxItems；Atomic 模式下不分片
+	ChunkSize int
+	// Concurrency 同时进行的请求数，0 表示 DefaultBulkConcurrency；Atomic 模式下只有一个请求
+	Concurrency int
+	// Progress 每个分片完成后以累计的数量调用，调用之间不会并发
+	Progress func(BulkImportProgress)
+}
+
+// BulkImportProgress 已完成的分片的累计结果
+type BulkImportProgress struct {
+	Done    int
+	Total   int
+	Created int
+	Failed  int
+}
+
+// BulkImport 把 pets 分片后以 NDJSON 并发调用 BulkImportPets，合并的结果中 index 是在 pets 中的位置。
+//
+// Atomic 模式不分片，pets 在一个请求中发送，服务端保证全部写入或什么都不写；
+// 数量超过 spec 的 maxItems 时服务端返回 400。被拒绝时返回逐项的结果与 ErrBulkImportAborted。
+// 非 200/422 的响应返回 *ResponseError，并取消尚未完成的请求
+func BulkImport(ctx context.Context, client ClientInterface, pets []NewPet, options BulkImportOptions, reqEditors ...RequestEditorFn) (*BulkImportResult, error) {
+	if options.Mode == "" {
+		options.Mode = BestEffort
+	}
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultBulkChunkSize
+	}
+	// 分片之间无法回滚，只有一个请求才能保证 atomic
+	if options.Mode == Atomic && len(pets) > 0 {
+		options.ChunkSize = len(pets)
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultBulkConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		progress = BulkImportProgress{Total: len(pets)}
+		chunks   = make([]*BulkImportResult, (len(pets)+options.ChunkSize-1)/options.ChunkSize)
+		sem      = make(chan struct{}, options.Concurrency)
+	)
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for i := range chunks {
+		sem <- struct{}{}
+		mu.Lock()
+		stop := firstErr != nil || ctx.Err() != nil
+		mu.Unlock()
+		if stop {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			offset := i * options.ChunkSize
+			end := offset + options.ChunkSize
+			if end > len(pets) {
+				end = len(pets)
+			}
+			result, err := importChunk(ctx, client, pets[offset:end], options.Mode, reqEditors)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fail(err)
+				cancel()
+				return
+			}
+			for j := range result.Items {
+				result.Items[j].Index += int32(offset)
+			}
+			chunks[i] = result
+			progress.Done += end - offset
+			progress.Created += int(result.Created)
+			progress.Failed += int(result.Failed)
+			if options.Mode == Atomic && result.Failed > 0 {
+				fail(ErrBulkImportAborted)
+			}
+			if options.Progress != nil {
+				options.Progress(progress)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	merged := &BulkImportResult{Items: make([]BulkItemResult, 0, progress.Done)}
+	for _, result := range chunks {
+		if result == nil {
+			continue
+		}
+		merged.Created += result.Created
+		merged.Failed += result.Failed
+		merged.Items = append(merged.Items, result.Items...)
+	}
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return merged, firstErr
+}
+
+// importChunk 发送一个分片，200 与 422 都返回解码后的结果
+func importChunk(ctx context.Context, client ClientInterface, pets []NewPet, mode BulkImportPetsParamsMode, reqEditors []RequestEditorFn) (*BulkImportResult, error) {
+	body, err := codec.NDJSON.Marshal(pets)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := client.BulkImportPetsWithBody(ctx, &BulkImportPetsParams{Mode: &mode}, codec.MIMEApplicationNDJSON, bytes.NewReader(body), reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParseBulkImportPetsResponse(rsp)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case parsed.JSON200 != nil:
+		return parsed.JSON200, nil
+	case parsed.JSON422 != nil:
+		return parsed.JSON422, nil
+	}
+	return nil, &ResponseError{StatusCode: rsp.StatusCode, Body: parsed.JSONDefault}
+}
@@ -0,0 +1,146 @@
+// Package cassette 提供录制/回放的 HttpRequestDoer，让调用其它服务的客户端测试可以离线、确定地运行。
+//
+//	rec, err := cassette.New(cassette.Options{Mode: cassette.ModeReplay, Path: "testdata/pets.yaml"})
+//	client, err := codegenTest.NewClientWithResponses(server, codegenTest.WithHTTPClient(rec))
+//
+// ModeRecord 把请求转发给真实的 Doer 并记录，测试结束时调用 Save 写入文件；
+// ModeReplay 只从文件中查找匹配的响应，找不到时返回 *UnmatchedError；
+// ModePassthrough 直接转发，不读也不写文件。
+package cassette
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode 录制模式
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+	ModePassthrough
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModePassthrough:
+		return "passthrough"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ParseMode 解析 "replay"、"record"、"passthrough"，空字符串为 ModeReplay，
+// 便于用环境变量切换：cassette.ParseMode(os.Getenv("CASSETTE_MODE"))
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "passthrough":
+		return ModePassthrough, nil
+	}
+	return 0, fmt.Errorf("cassette: unknown mode %q", s)
+}
+
+// Request 录制下来的请求，Header 中的敏感值已被替换为 Redacted
+type Request struct {
+	Method string      `json:"method" yaml:"method"`
+	URL    string      `json:"url" yaml:"url"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body   Body        `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Response 录制下来的响应
+type Response struct {
+	Status int         `json:"status" yaml:"status"`
+	Header http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body   Body        `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Body 文本按原样保存，非 UTF-8 内容以 base64 保存
+type Body struct {
+	Text   string `json:"text,omitempty" yaml:"text,omitempty"`
+	Base64 string `json:"base64,omitempty" yaml:"base64,omitempty"`
+}
+
+func newBody(data []byte) Body {
+	if utf8.Valid(data) {
+		return Body{Text: string(data)}
+	}
+	return Body{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+// Bytes 还原原始内容
+func (b Body) Bytes() ([]byte, error) {
+	if b.Base64 != "" {
+		return base64.StdEncoding.DecodeString(b.Base64)
+	}
+	return []byte(b.Text), nil
+}
+
+// Interaction 一次请求及其响应
+type Interaction struct {
+	Request  Request  `json:"request" yaml:"request"`
+	Response Response `json:"response" yaml:"response"`
+}
+
+// Cassette 文件内容，按录制顺序保存
+type Cassette struct {
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// isYAML 按扩展名选择格式，.yaml/.yml 为 YAML，其它为 JSON
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Load 读取 cassette 文件
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if isYAML(path) {
+		err = yaml.Unmarshal(data, c)
+	} else {
+		err = json.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cassette: decode %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save 写入 cassette 文件，必要时创建目录
+func (c *Cassette) Save(path string) error {
+	var data []byte
+	var err error
+	if isYAML(path) {
+		data, err = yaml.Marshal(c)
+	} else {
+		data, err = json.MarshalIndent(c, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,143 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/cassette"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const server = "http://testkit/api"
+
+func newClient(t *testing.T, rec *cassette.Recorder) *codegenTest.ClientWithResponses {
+	client, err := codegenTest.NewClientWithResponses(server,
+		codegenTest.WithHTTPClient(rec),
+		codegenTest.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer secret-token")
+			return nil
+		}))
+	require.NoError(t, err)
+	return client
+}
+
+// record 对 testkit 服务录制一组请求
+func record(t *testing.T, path string, match cassette.MatchOn) {
+	h := testkit.New(t, nil)
+	rec, err := cassette.New(cassette.Options{Mode: cassette.ModeRecord, Path: path, Doer: h.Doer, Match: match})
+	require.NoError(t, err)
+	client := newClient(t, rec)
+	ctx := context.Background()
+
+	added, err := client.AddPetWithResponse(ctx, codegenTest.NewPet{Name: "baby"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, added.StatusCode())
+	found, err := client.FindPetByIdWithResponse(ctx, added.JSON200.Id)
+	require.NoError(t, err)
+	require.Equal(t, "baby", found.JSON200.Name)
+	missing, err := client.FindPetByIdWithResponse(ctx, 99)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, missing.StatusCode())
+
+	require.Len(t, rec.Interactions(), 3)
+	require.NoError(t, rec.Save())
+}
+
+func TestRecordReplay(t *testing.T) {
+	for _, name := range []string{"pets.yaml", "pets.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "testdata", name)
+			record(t, path, 0)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), cassette.Redacted)
+			assert.NotContains(t, string(data), "secret-token")
+
+			// 回放时没有任何服务端
+			rec, err := cassette.New(cassette.Options{Mode: cassette.ModeReplay, Path: path})
+			require.NoError(t, err)
+			client := newClient(t, rec)
+			ctx := context.Background()
+
+			added, err := client.AddPetWithResponse(ctx, codegenTest.NewPet{Name: "baby"})
+			require.NoError(t, err)
+			require.NotNil(t, added.JSON200)
+			assert.Equal(t, int64(1), added.JSON200.Id)
+
+			found, err := client.FindPetByIdWithResponse(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, "baby", found.JSON200.Name)
+
+			missing, err := client.FindPetByIdWithResponse(ctx, 99)
+			require.NoError(t, err)
+			require.NotNil(t, missing.JSONDefault)
+			assert.Equal(t, int32(http.StatusNotFound), missing.JSONDefault.Code)
+
+			// 每条录制只使用一次，也不会凭空出现没录过的请求
+			_, err = client.FindPetByIdWithResponse(ctx, 1)
+			var unmatched *cassette.UnmatchedError
+			require.True(t, errors.As(err, &unmatched), "got %v", err)
+			assert.Equal(t, http.MethodGet, unmatched.Method)
+			assert.Equal(t, server+"/pets/1", unmatched.URL)
+		})
+	}
+}
+
+func TestReplayMatchBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pets.json")
+	record(t, path, 0)
+
+	for _, tt := range []struct {
+		name  string
+		match cassette.MatchOn
+		pet   codegenTest.NewPet
+		ok    bool
+	}{
+		{"default ignores body", 0, codegenTest.NewPet{Name: "kitty"}, true},
+		{"body differs", cassette.DefaultMatch | cassette.MatchBody, codegenTest.NewPet{Name: "kitty"}, false},
+		{"body equal", cassette.DefaultMatch | cassette.MatchBody, codegenTest.NewPet{Name: "baby"}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := cassette.New(cassette.Options{Mode: cassette.ModeReplay, Path: path, Match: tt.match})
+			require.NoError(t, err)
+			_, err = newClient(t, rec).AddPetWithResponse(context.Background(), tt.pet)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	h := testkit.New(t, nil)
+	rec, err := cassette.New(cassette.Options{Mode: cassette.ModePassthrough, Doer: h.Doer})
+	require.NoError(t, err)
+	resp, err := newClient(t, rec).AddPetWithResponse(context.Background(), codegenTest.NewPet{Name: "baby"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Empty(t, rec.Interactions())
+	assert.NoError(t, rec.Save())
+}
+
+func TestParseMode(t *testing.T) {
+	for input, want := range map[string]cassette.Mode{"": cassette.ModeReplay, "Record": cassette.ModeRecord, "passthrough": cassette.ModePassthrough} {
+		got, err := cassette.ParseMode(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := cassette.ParseMode("rewind")
+	assert.Error(t, err)
+
+	_, err = cassette.New(cassette.Options{Mode: cassette.ModeReplay, Path: filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/internal/httpdoer"
+)
+
+// Redacted 替换敏感 header 的值
+const Redacted = "REDACTED"
+
+// DefaultRedactHeaders 默认脱敏的 header
+var DefaultRedactHeaders = httpdoer.CredentialHeaders
+
+// MatchOn 回放时比较请求的哪些部分，可以按位组合
+type MatchOn int
+
+const (
+	MatchMethod MatchOn = 1 << iota
+	MatchPath
+	MatchQuery
+	// MatchBody JSON 请求体按语义比较，其它按字节比较
+	MatchBody
+
+	DefaultMatch = MatchMethod | MatchPath | MatchQuery
+)
+
+// Options 配置 Recorder
+type Options struct {
+	Mode Mode
+	// Path 为 cassette 文件，.yaml/.yml 使用 YAML，其它使用 JSON；ModePassthrough 下可以为空
+	Path string
+	// Doer 为 ModeRecord 和 ModePassthrough 下真正发出请求的 Doer，为 nil 时使用 http.DefaultClient
+	Doer codegenTest.HttpRequestDoer
+	// Match 为 0 时使用 DefaultMatch
+	Match MatchOn
+	// RedactHeaders 为 nil 时使用 DefaultRedactHeaders
+	RedactHeaders []string
+}
+
+// UnmatchedError 回放时没有找到匹配的录制
+type UnmatchedError struct {
+	Method string
+	URL    string
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("cassette: no recorded interaction matches %s %s", e.Method, e.URL)
+}
+
+// Recorder 实现 HttpRequestDoer，并发安全
+type Recorder struct {
+	options Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	// used 回放时已经用过的录制，同样的请求依次返回后续的录制
+	used []bool
+}
+
+// New 创建 Recorder，ModeReplay 下会立即读取 Options.Path
+func New(options Options) (*Recorder, error) {
+	if options.Doer == nil {
+		options.Doer = http.DefaultClient
+	}
+	if options.Match == 0 {
+		options.Match = DefaultMatch
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = DefaultRedactHeaders
+	}
+
+	r := &Recorder{options: options, cassette: &Cassette{}}
+	switch options.Mode {
+	case ModeReplay:
+		c, err := Load(options.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	case ModeRecord:
+		if options.Path == "" {
+			return nil, fmt.Errorf("cassette: Path is required in %s mode", options.Mode)
+		}
+	case ModePassthrough:
+	default:
+		return nil, fmt.Errorf("cassette: unknown mode %s", options.Mode)
+	}
+	return r, nil
+}
+
+// Mode 当前模式
+func (r *Recorder) Mode() Mode {
+	return r.options.Mode
+}
+
+// Interactions 返回目前录制或加载的全部交互
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save ModeRecord 下把录制结果写入 Options.Path，其它模式下什么都不做
+func (r *Recorder) Save() error {
+	if r.options.Mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.options.Path)
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	switch r.options.Mode {
+	case ModePassthrough:
+		return r.options.Doer.Do(req)
+	case ModeRecord:
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := httpdoer.ReadBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.options.Doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := httpdoer.ReadBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redact(req.Header),
+			Body:   newBody(reqBody),
+		},
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: r.redact(resp.Header),
+			Body:   newBody(respBody),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := httpdoer.ReadBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matches(interaction.Request, req, body) {
+			continue
+		}
+		r.used[i] = true
+		return interaction.Response.toHTTP(req)
+	}
+	return nil, &UnmatchedError{Method: req.Method, URL: req.URL.String()}
+}
+
+func (r *Recorder) matches(recorded Request, req *http.Request, body []byte) bool {
+	match := r.options.Match
+	if match&MatchMethod != 0 && recorded.Method != req.Method {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	if match&MatchPath != 0 && recordedURL.Path != req.URL.Path {
+		return false
+	}
+	// Encode 会按 key 排序，参数顺序不同也视为相同
+	if match&MatchQuery != 0 && recordedURL.Query().Encode() != req.URL.Query().Encode() {
+		return false
+	}
+	if match&MatchBody != 0 {
+		recordedBody, err := recorded.Body.Bytes()
+		if err != nil || !equalBody(recordedBody, body) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalBody(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	na, _ := json.Marshal(va)
+	nb, _ := json.Marshal(vb)
+	return bytes.Equal(na, nb)
+}
+
+func (r *Recorder) redact(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := header.Clone()
+	for _, name := range r.options.RedactHeaders {
+		if values := redacted.Values(name); len(values) > 0 {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return redacted
+}
+
+func (resp Response) toHTTP(req *http.Request) (*http.Response, error) {
+	body, err := resp.Body.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
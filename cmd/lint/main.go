@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"demo/oapi-codegen-go/lint"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 按项目规则检查 OpenAPI 文档，例如：
+//
+//	go run ./cmd/lint -spec demo.yaml -format json
+//	go run ./cmd/lint -spec demo.yaml -config lint.yaml -fail-on warning
+//
+// -rules 列出全部规则及默认严重程度。
+// 退出码：0 没有达到 -fail-on 的结果，1 存在达到 -fail-on 的结果，2 参数或文档错误
+func main() {
+	spec := flag.String("spec", "./demo.yaml", "OpenAPI 文档路径")
+	config := flag.String("config", "", "规则配置文件（YAML 或 JSON）")
+	format := flag.String("format", "text", "输出格式：text、json")
+	failOn := flag.String("fail-on", "error", "何时返回 1：error、warning、info、off（从不）")
+	listRules := flag.Bool("rules", false, "列出规则后退出")
+	flag.Parse()
+
+	if *listRules {
+		for _, rule := range lint.Rules() {
+			fmt.Printf("%-22s %-7s %s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		return
+	}
+	os.Exit(run(os.Stdout, os.Stderr, *spec, *config, *format, *failOn))
+}
+
+func run(stdout, stderr io.Writer, spec, configPath, format, failOn string) int {
+	threshold, err := lint.ParseSeverity(failOn)
+	if err != nil {
+		fmt.Fprintln(stderr, "lint: -fail-on:", err)
+		return 2
+	}
+	var config *lint.Config
+	if configPath != "" {
+		if config, err = lint.LoadConfig(configPath); err != nil {
+			fmt.Fprintln(stderr, "lint:", err)
+			return 2
+		}
+	}
+
+	// 不调用 Validate：不合法的 example 等问题交给规则报告
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	swagger, err := loader.LoadFromFile(spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "lint: load %s: %v\n", spec, err)
+		return 2
+	}
+	report, err := lint.Lint(swagger, config)
+	if err != nil {
+		fmt.Fprintln(stderr, "lint:", err)
+		return 2
+	}
+
+	switch format {
+	case "text":
+		err = report.WriteText(stdout)
+	case "json":
+		err = report.WriteJSON(stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "lint:", err)
+		return 2
+	}
+	if threshold != lint.Off && report.Max() >= threshold {
+		return 1
+	}
+	return 0
+}
@@ -1,48 +1,61 @@
 package main
 
 import (
-	codegenTest "demo/oapi-codegen-go"
-	"demo/oapi-codegen-go/app"
-	"github.com/deepmap/oapi-codegen/pkg/middleware"
-	"github.com/labstack/echo/v4"
+	"context"
+	"errors"
+	"flag"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout 收到信号后等待进行中的请求与后台任务结束的时间，之后未完成的任务记为 failed
+const shutdownTimeout = 30 * time.Second
+
 func main() {
-	e := echo.New()
-	server := app.EchoServer{}
-	codegenTest.RegisterHandlersWithBaseURL(e, &server, "/james")
-	// swagger 对象
-	swagger, err := codegenTest.GetSwaggerWithPrefix("/james")
+	strictRoutes := flag.Bool("strict-routes", false, "路由与 spec 不一致时拒绝启动")
+	adminToken := flag.String("admin-token", "", "/james/admin/* 的 Bearer token，为空时不开放管理接口")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s, err := newServer(config{
+		Spec:         "./demo.yaml",
+		Docs:         "./index.html",
+		Spans:        "./spans.jsonl",
+		Operations:   "./operations",
+		AdminToken:   *adminToken,
+		StrictRoutes: *strictRoutes,
+	})
 	if err != nil {
 		panic(err)
 	}
-	// demo 1: 增加参数校验中间件：对 swagger 对象解析，并对请求参数校验
-	// e.Use(middleware.OapiRequestValidator(swagger))
-	// demo 2: 自定义参数校验
-	options := middleware.Options{
-		ErrorHandler: func(c echo.Context, err *echo.HTTPError) error {
-			println(err.Error())
-			return c.String(http.StatusOK, "bad request")
-		},
+	defer s.Close()
+	if err := s.Start(ctx); err != nil {
+		panic(err)
 	}
-	e.Use(middleware.OapiRequestValidatorWithOptions(swagger, &options))
-	// demo 3: Swagger UI 结合 Echo：在 echo 中配置中间件，对 Swagger 路由特殊预处理
-	e.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			if c.Request().URL.Path == "/james/swagger.yaml" {
-				return c.File("./demo.yaml") // 返回 Swagger YAML 文件
-			}
-			if c.Request().URL.Path == "/james/docs" {
-				return c.File("./index.html")
-			}
-			return next(c)
-		}
-	})
-	// 使用 oapi-codegen 校验
-	e.Use(middleware.OapiRequestValidator(swagger))
-	err = e.Start(":8090")
-	if err != nil {
+	errs := make(chan error, 1)
+	go func() { errs <- s.Echo.Start(":8090") }()
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+
+	// 先停止接收请求，再等后台任务；排队中的任务保留在 ./operations，下次启动继续
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.Echo.Shutdown(shutdownCtx); err != nil {
+		log.Println("ERROR: shutdown http server:", err)
+	}
+	if err := s.Operations.Shutdown(shutdownCtx); err != nil {
+		log.Println("ERROR: shutdown operations:", err)
+	}
+	// 端口被占用等启动失败在关闭之后再报告
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		panic(err)
 	}
 }
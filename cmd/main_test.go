@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"demo/oapi-codegen-go/violations"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer 与 main 相同的组装，文件都放在临时目录
+func newTestServer(t *testing.T, adminToken string) *service {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := newServer(config{
+		Spec:       "../demo.yaml",
+		Docs:       "../index.html",
+		Spans:      filepath.Join(dir, "spans.jsonl"),
+		Operations: filepath.Join(dir, "operations"),
+		AdminToken: adminToken,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = s.Operations.Shutdown(context.Background())
+		_ = s.Close()
+	})
+	return s
+}
+
+func send(s *service, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	s.Echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) violations.Body {
+	t.Helper()
+	var body violations.Body
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return body
+}
+
+func TestValidationViolations(t *testing.T) {
+	s := newTestServer(t, "")
+
+	rec := send(s, http.MethodPost, "/james/pets", `{"tag":1}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	body := decode(t, rec)
+	assert.Equal(t, int32(http.StatusBadRequest), body.Code)
+	var pointers []string
+	for _, v := range body.Violations {
+		assert.Equal(t, "body", v.Location)
+		pointers = append(pointers, v.Pointer+" "+v.Keyword)
+	}
+	assert.ElementsMatch(t, []string{"/name required", "/tag type"}, pointers)
+
+	rec = send(s, http.MethodPost, "/james/pets", `{"name":"rex"}`, nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestLocalizedViolations(t *testing.T) {
+	s := newTestServer(t, "")
+
+	rec := send(s, http.MethodPost, "/james/pets", `{"tag":1}`, http.Header{"Accept-Language": {"zh-CN,zh;q=0.9"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "zh-CN", rec.Header().Get("Content-Language"))
+	var messages []string
+	for _, v := range decode(t, rec).Violations {
+		messages = append(messages, v.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		`请求体有误：/name: 缺少属性 "name"`,
+		`请求体有误：/tag: 取值必须是字符串`,
+	}, messages)
+
+	body := decode(t, send(s, http.MethodGet, "/james/pets?limit=30", "", http.Header{"Accept-Language": {"zh"}}))
+	assert.Equal(t, `参数 "limit"（query）有误：数值不能大于 20`, body.Message)
+	body = decode(t, send(s, http.MethodGet, "/james/pets?limit=30", "", nil))
+	assert.Equal(t, `parameter "limit" in query has an error: number must be at most 20`, body.Message)
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	// 没有 token 时管理接口不存在
+	s := newTestServer(t, "")
+	assert.Equal(t, http.StatusNotFound, send(s, http.MethodGet, "/james/admin/routes", "", nil).Code)
+
+	s = newTestServer(t, "secret")
+	assert.Equal(t, http.StatusUnauthorized, send(s, http.MethodGet, "/james/admin/routes", "", nil).Code)
+	rec := send(s, http.MethodGet, "/james/admin/routes", "", http.Header{"Authorization": {"Bearer secret"}})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"demo/oapi-codegen-go/mock"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 根据 OpenAPI 文档启动 mock 服务，例如：
+//
+//	go run ./cmd/mock -spec demo.yaml -addr :8091
+//	curl -H 'Prefer: code=404' localhost:8091/pets/1
+func main() {
+	spec := flag.String("spec", "./demo.yaml", "OpenAPI 文档路径")
+	addr := flag.String("addr", ":8091", "监听地址")
+	keepServers := flag.Bool("keep-servers", false, "保留文档中的 servers：路由挂在第一个 server 的 path 下，校验器会按 Host 匹配")
+	noValidate := flag.Bool("no-validate", false, "关闭请求校验")
+	flag.Parse()
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	swagger, err := loader.LoadFromFile(*spec)
+	if err != nil {
+		log.Fatalf("load %s: %v", *spec, err)
+	}
+	if err := swagger.Validate(context.Background()); err != nil {
+		log.Fatalf("validate %s: %v", *spec, err)
+	}
+
+	e, err := mock.New(swagger, mock.Options{KeepServers: *keepServers, DisableValidation: *noValidate})
+	if err != nil {
+		log.Fatalf("mock %s: %v", *spec, err)
+	}
+	log.Fatal(e.Start(*addr))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"demo/oapi-codegen-go/oasdiff"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// 比较两个 OpenAPI 文档，例如在合并前检查 demo.yaml 的改动：
+//
+//	git show main:demo.yaml > /tmp/base.yaml
+//	go run ./cmd/oasdiff -format markdown /tmp/base.yaml demo.yaml
+//
+// 退出码：0 没有需要拦截的变化，1 存在 -fail-on 指定的变化，2 参数或文档错误
+func main() {
+	format := flag.String("format", "text", "输出格式：text、json、markdown")
+	failOn := flag.String("fail-on", "breaking", "何时返回 1：breaking、any、none")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: oasdiff [flags] base.yaml revision.yaml")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	os.Exit(run(os.Stdout, os.Stderr, *format, *failOn, flag.Args()))
+}
+
+func run(stdout, stderr io.Writer, format, failOn string, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(stderr, "oasdiff: expected two documents: base.yaml revision.yaml")
+		return 2
+	}
+	base, err := load(args[0])
+	if err != nil {
+		fmt.Fprintln(stderr, "oasdiff:", err)
+		return 2
+	}
+	revision, err := load(args[1])
+	if err != nil {
+		fmt.Fprintln(stderr, "oasdiff:", err)
+		return 2
+	}
+
+	report := oasdiff.Compare(base, revision)
+	switch format {
+	case "text":
+		err = report.WriteText(stdout)
+	case "json":
+		err = report.WriteJSON(stdout)
+	case "markdown", "md":
+		err = report.WriteMarkdown(stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "oasdiff:", err)
+		return 2
+	}
+
+	switch failOn {
+	case "breaking":
+		if report.HasBreaking() {
+			return 1
+		}
+	case "any":
+		if len(report.Changes) > 0 {
+			return 1
+		}
+	case "none":
+	default:
+		fmt.Fprintf(stderr, "oasdiff: unknown -fail-on %q\n", failOn)
+		return 2
+	}
+	return 0
+}
+
+func load(path string) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	swagger, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	if err := swagger.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", path, err)
+	}
+	return swagger, nil
+}
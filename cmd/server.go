@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/compression"
+	"demo/oapi-codegen-go/i18n"
+	"demo/oapi-codegen-go/operations"
+	"demo/oapi-codegen-go/protocodec"
+	"demo/oapi-codegen-go/reload"
+	"demo/oapi-codegen-go/routecheck"
+	"demo/oapi-codegen-go/tracing"
+	"demo/oapi-codegen-go/violations"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// config 服务用到的文件与开关，路径相对于工作目录
+type config struct {
+	Spec         string
+	Docs         string
+	Spans        string
+	Operations   string
+	AdminToken   string
+	StrictRoutes bool
+}
+
+// service 组装好的服务，Validator 与 Operations 的后台任务由调用方启动和关闭
+type service struct {
+	Echo       *echo.Echo
+	Validator  *reload.Validator
+	Operations *operations.Manager
+	exporter   *tracing.FileExporter
+}
+
+// newServer 按实际运行的顺序挂载中间件与路由，main 与测试共用
+func newServer(cfg config) (*service, error) {
+	e := echo.New()
+	// 错误统一按 Error schema 输出
+	e.HTTPErrorHandler = app.HTTPErrorHandler
+	// 请求体与响应按 Content-Type/Accept 选择 JSON、msgpack、YAML 或 XML
+	e.Binder = &codec.Binder{}
+	// 按 Accept-Language 翻译校验与参数绑定的错误，支持 zh-CN 与 en，默认英文
+	e.Use(i18n.New(i18n.Options{}).Middleware())
+	server := app.EchoServer{}
+	// 链路追踪：span 以 JSON lines 格式写入 cfg.Spans
+	exporter, err := tracing.NewFileExporter(cfg.Spans)
+	if err != nil {
+		return nil, err
+	}
+	s := &service{Echo: e, exporter: exporter}
+	if err := s.setup(cfg, &server, tracing.NewTracer(exporter)); err != nil {
+		exporter.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// setup 按顺序挂载各项功能，每项的细节见对应的方法
+func (s *service) setup(cfg config, server *app.EchoServer, tracer *tracing.Tracer) error {
+	e := s.Echo
+	codegenTest.RegisterHandlersWithBaseURL(tracing.Router(tracer, e), tracing.Handler(tracer, server), "/james")
+	// swagger 对象
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/james")
+	if err != nil {
+		return err
+	}
+	if err := registerProtobuf(swagger); err != nil {
+		return err
+	}
+	routes, err := s.verifyRoutes(cfg, swagger)
+	if err != nil {
+		return err
+	}
+	// 解析 traceparent/tracestate，必须在校验中间件之前
+	e.Use(tracing.Middleware(tracer, swagger))
+	if err := s.useValidation(cfg, tracer); err != nil {
+		return err
+	}
+	s.mountDocs(cfg, routes)
+	return s.useOperations(cfg, server, swagger)
+}
+
+// verifyRoutes 启动自检：注册的路由与 spec 中的 operation 逐一对应，指定 -admin-token 时结果见 /james/admin/routes
+func (s *service) verifyRoutes(cfg config, swagger *openapi3.T) (*routecheck.Report, error) {
+	routes, err := routecheck.Verify(s.Echo, swagger, routecheck.Options{Strict: cfg.StrictRoutes})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range routes.Mismatches {
+		log.Println("WARN:", m.Message)
+	}
+	return routes, nil
+}
+
+// useValidation 挂载解压与校验中间件。
+// demo 4: 热更新校验：修改 demo.yaml 后自动生效，指定 -admin-token 时也可以 POST /james/admin/reload 立即重新加载。
+// 校验失败时逐条列出违规的字段（位置、JSON pointer、关键字、期望值与实际值）
+func (s *service) useValidation(cfg config, tracer *tracing.Tracer) error {
+	renderer := violations.New(violations.Options{})
+	validator, err := reload.New(reload.Options{Path: cfg.Spec, Prefix: "/james", Validator: &middleware.Options{
+		Options:           openapi3filter.Options{MultiError: true},
+		MultiErrorHandler: renderer.MultiErrorHandler,
+		ErrorHandler:      renderer.ErrorHandler,
+	}})
+	if err != nil {
+		return err
+	}
+	s.Validator = validator
+	// 按 Accept-Encoding 压缩响应；请求体在校验之前解压，并拒绝压缩比异常的请求体。
+	// x-compression 按热更新后的当前版本查找
+	compress, err := compression.New(compression.Options{Router: validator})
+	if err != nil {
+		return err
+	}
+	s.Echo.Use(compress)
+	// 使用 oapi-codegen 校验，规则来自 demo.yaml 的当前版本
+	s.Echo.Use(tracing.Trace(tracer, "validation", validator.Middleware()))
+	return nil
+}
+
+// mountDocs demo 3: Swagger UI 结合 Echo：在 echo 中配置中间件，对 Swagger 与管理接口的路由特殊预处理
+func (s *service) mountDocs(cfg config, routes *routecheck.Report) {
+	s.Echo.Pre(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().URL.Path == "/james/swagger.yaml" {
+				return c.File(cfg.Spec) // 返回 Swagger YAML 文件
+			}
+			if c.Request().URL.Path == "/james/docs" {
+				return c.File(cfg.Docs)
+			}
+			if c.Request().URL.Path == "/james/admin/reload" {
+				return admin(cfg.AdminToken, s.Validator.Handler(), next)(c)
+			}
+			if c.Request().URL.Path == "/james/admin/routes" {
+				return admin(cfg.AdminToken, routecheck.Handler(routes), next)(c)
+			}
+			return next(c)
+		}
+	})
+}
+
+// useOperations 带 Prefer: respond-async 的批量导入在后台执行，任务保存在 cfg.Operations，重启后继续
+func (s *service) useOperations(cfg config, server *app.EchoServer, swagger *openapi3.T) error {
+	store, err := operations.NewFileStore(cfg.Operations)
+	if err != nil {
+		return err
+	}
+	manager, err := operations.New(operations.Options{Swagger: swagger, BaseURL: "/james", Store: store})
+	if err != nil {
+		return err
+	}
+	s.Echo.Use(manager.Middleware())
+	server.Operations = manager
+	s.Operations = manager
+	return nil
+}
+
+var protobuf struct {
+	once sync.Once
+	err  error
+}
+
+// registerProtobuf 把 protobuf 注册到 codec.Default：消息由 spec 中的组件生成，逐条列出违规的 violations.Body 按 Error 编码。
+// Respond 与生成的客户端都使用 codec.Default，每个服务的 spec 都相同，只在第一次创建服务时注册
+func registerProtobuf(swagger *openapi3.T) error {
+	protobuf.once.Do(func() {
+		pc, err := protocodec.New(swagger, protocodec.Options{Types: map[reflect.Type]string{reflect.TypeOf(violations.Body{}): "Error"}})
+		if err != nil {
+			protobuf.err = err
+			return
+		}
+		pc.Register(codec.Default)
+	})
+	return protobuf.err
+}
+
+// Start 启动热更新监听与后台任务的 worker
+func (s *service) Start(ctx context.Context) error {
+	go s.Validator.Watch(ctx)
+	return s.Operations.Start(s.Echo)
+}
+
+// Close 关闭 span 文件
+func (s *service) Close() error {
+	return s.exporter.Close()
+}
+
+// admin 只有请求带着 Authorization: Bearer <token> 时才调用 h；token 为空表示不开放管理接口，交给 next 按普通路由处理
+func admin(token string, h, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if token == "" {
+			return next(c)
+		}
+		got := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid admin token")
+		}
+		return h(c)
+	}
+}
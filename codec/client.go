@@ -0,0 +1,82 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// 以下函数返回的函数可以直接作为生成客户端的 RequestEditorFn：
+//
+//	client.FindPetsWithResponse(ctx, params, codec.WithAccept(codec.MIMEApplicationMsgpack))
+//	codegenTest.WithRequestEditorFn(codec.WithContentType(codec.MIMEApplicationMsgpack))
+
+// WithAccept 设置 Accept，Parse*Response 按响应的 Content-Type 解码
+func WithAccept(mediaType string) func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Accept", mediaType)
+		return nil
+	}
+}
+
+// WithContentType 把生成代码编码好的 JSON 请求体重新编码为 mediaType。
+// 没有请求体或者请求体不是 JSON 时不做修改；XML 的根元素由服务端忽略，固定为 value
+func WithContentType(mediaType string) func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil
+		}
+		if c, ok := Lookup(req.Header.Get("Content-Type")); !ok || c != JSON {
+			return nil
+		}
+		target, ok := Lookup(mediaType)
+		if !ok {
+			return fmt.Errorf("codec: no codec registered for %q", mediaType)
+		}
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if data, err = target.Marshal(numbers(value)); err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", target.MediaType())
+		req.ContentLength = int64(len(data))
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		return nil
+	}
+}
+
+// numbers 把 json.Number 换成 int64 或 float64，msgpack 与 YAML 才能编码成数字
+func numbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = numbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = numbers(item)
+		}
+	}
+	return value
+}
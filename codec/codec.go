@@ -0,0 +1,194 @@
+// Package codec 按 Content-Type 与 Accept 选择请求体和响应体的编码格式。
+//
+// Echo 服务端（Binder、Respond）与生成的客户端（Parse*Response、WithAccept、WithContentType）
+// 共用同一个 Registry。默认注册 JSON、MessagePack、YAML 与 XML，JSON 是兜底格式：
+// 没有 Accept、Accept 无法匹配或者 Content-Type 未注册时都按 JSON 处理。
+// 流式接口另外支持 NDJSON，由 handler 自己判断，见 AcceptsNDJSON 与 StreamNDJSON；
+// CSV 等其它格式用 Select 协商。
+package codec
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 各格式的标准媒体类型，spec 中声明的就是这些
+const (
+	MIMEApplicationJSON    = "application/json"
+	MIMEApplicationMsgpack = "application/msgpack"
+	MIMEApplicationYAML    = "application/yaml"
+	MIMEApplicationXML     = "application/xml"
+)
+
+// Codec 一种编码格式
+type Codec interface {
+	// MediaType 标准媒体类型，作为响应的 Content-Type
+	MediaType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Registry 媒体类型到 Codec 的映射，可以并发使用
+type Registry struct {
+	mu       sync.RWMutex
+	fallback Codec
+	codecs   []Codec
+	byType   map[string]Codec
+	// types 按注册顺序记录全部媒体类型（包括别名），用于匹配 type/*
+	types []string
+}
+
+// NewRegistry 创建只包含 fallback 的 Registry，fallback 用于无法协商的情况
+func NewRegistry(fallback Codec) *Registry {
+	r := &Registry{fallback: fallback, byType: make(map[string]Codec)}
+	r.Register(fallback)
+	return r
+}
+
+// Register 注册 c 及其别名，已有的媒体类型会被替换。协商 type/* 时按首次注册的顺序选择
+func (r *Registry) Register(c Codec, aliases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.codecs {
+		if existing.MediaType() == c.MediaType() {
+			r.codecs = append(r.codecs[:i:i], r.codecs[i+1:]...)
+			break
+		}
+	}
+	r.codecs = append(r.codecs, c)
+	for _, t := range append([]string{c.MediaType()}, aliases...) {
+		t = strings.ToLower(t)
+		if _, ok := r.byType[t]; !ok {
+			r.types = append(r.types, t)
+		}
+		r.byType[t] = c
+	}
+}
+
+// Lookup 按 Content-Type 查找，忽略参数；application/problem+json 这类结构化后缀
+// 按后缀对应的格式处理。contentType 为空或未注册时返回 false
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	if contentType == "" {
+		return nil, false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.byType[mediaType]; ok {
+		return c, true
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		if c, ok := r.byType["application/"+mediaType[i+1:]]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate 按 Accept 的 q 值选择格式，支持 */* 与 type/*；没有匹配时返回 fallback
+func (r *Registry) Negotiate(accept string) Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, mediaRange := range parseAccept(accept) {
+		switch {
+		case mediaRange == "*/*":
+			return r.fallback
+		case strings.HasSuffix(mediaRange, "/*"):
+			prefix := strings.TrimSuffix(mediaRange, "*")
+			for _, t := range r.types {
+				if strings.HasPrefix(t, prefix) {
+					return r.byType[t]
+				}
+			}
+		default:
+			if c, ok := r.byType[mediaRange]; ok {
+				return c
+			}
+		}
+	}
+	return r.fallback
+}
+
+// MediaTypes 按注册顺序返回各格式的标准媒体类型
+func (r *Registry) MediaTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.codecs))
+	for _, c := range r.codecs {
+		types = append(types, c.MediaType())
+	}
+	return types
+}
+
+// parseAccept 按 q 值从高到低返回媒体范围，q=0 的项被丢弃，相同 q 值保持原顺序
+func parseAccept(accept string) []string {
+	type entry struct {
+		mediaRange string
+		q          float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{mediaRange, q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	ranges := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ranges = append(ranges, e.mediaRange)
+	}
+	return ranges
+}
+
+// Default 服务端与客户端共用的 Registry
+var Default = NewRegistry(JSON)
+
+func init() {
+	Default.Register(Msgpack, "application/x-msgpack", "application/vnd.msgpack")
+	Default.Register(YAML, "application/x-yaml", "text/yaml")
+	Default.Register(XML, "text/xml")
+}
+
+// Lookup 等同于 Default.Lookup
+func Lookup(contentType string) (Codec, bool) {
+	return Default.Lookup(contentType)
+}
+
+// Negotiate 等同于 Default.Negotiate
+func Negotiate(accept string) Codec {
+	return Default.Negotiate(accept)
+}
+
+// MIMETextCSV 导出接口使用的 CSV，不是 Codec，由 handler 自己写出
+const MIMETextCSV = "text/csv"
+
+// Select 按 Accept 的 q 值从 offers 中选择媒体类型，支持 */* 与 type/*；
+// 没有 Accept 或都不匹配时返回 offers[0]。用于 CSV 这类不在 Registry 中的格式
+func Select(accept string, offers ...string) string {
+	for _, mediaRange := range parseAccept(accept) {
+		prefix := strings.TrimSuffix(mediaRange, "*")
+		for _, offer := range offers {
+			if mediaRange == "*/*" || mediaRange == offer ||
+				strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, prefix) {
+				return offer
+			}
+		}
+	}
+	return offers[0]
+}
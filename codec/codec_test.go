@@ -0,0 +1,230 @@
+package codec_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	for accept, want := range map[string]string{
+		"":                                  codec.MIMEApplicationJSON,
+		"*/*":                               codec.MIMEApplicationJSON,
+		"application/msgpack":               codec.MIMEApplicationMsgpack,
+		"application/x-msgpack":             codec.MIMEApplicationMsgpack,
+		"text/html, application/yaml;q=0.5": codec.MIMEApplicationYAML,
+		"application/xml;q=0.4, application/msgpack;q=0.9": codec.MIMEApplicationMsgpack,
+		"text/*":                         codec.MIMEApplicationYAML,
+		"application/xml;q=0, */*;q=0.1": codec.MIMEApplicationJSON,
+		"image/png":                      codec.MIMEApplicationJSON,
+		"not a media type;;":             codec.MIMEApplicationJSON,
+	} {
+		assert.Equal(t, want, codec.Negotiate(accept).MediaType(), accept)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for contentType, want := range map[string]string{
+		"application/json; charset=UTF-8": codec.MIMEApplicationJSON,
+		"application/problem+json":        codec.MIMEApplicationJSON,
+		"application/vnd.msgpack":         codec.MIMEApplicationMsgpack,
+		"text/yaml":                       codec.MIMEApplicationYAML,
+		"application/atom+xml":            codec.MIMEApplicationXML,
+	} {
+		c, ok := codec.Lookup(contentType)
+		require.True(t, ok, contentType)
+		assert.Equal(t, want, c.MediaType(), contentType)
+	}
+	for _, contentType := range []string{"", "text/plain", "multipart/form-data; boundary=x"} {
+		_, ok := codec.Lookup(contentType)
+		assert.False(t, ok, contentType)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tag := "cat"
+	pets := []codegenTest.Pet{{Id: 1, Name: "kitty", Tag: &tag}, {Id: 2, Name: "rex"}}
+	for _, c := range []codec.Codec{codec.JSON, codec.Msgpack, codec.YAML, codec.XML} {
+		t.Run(c.MediaType(), func(t *testing.T) {
+			data, err := c.Marshal(pets)
+			require.NoError(t, err)
+			var got []codegenTest.Pet
+			require.NoError(t, c.Unmarshal(data, &got), string(data))
+			assert.Equal(t, pets, got)
+		})
+	}
+}
+
+func TestXMLDocument(t *testing.T) {
+	tag := "cat"
+	data, err := codec.XML.Marshal([]codegenTest.Pet{{Id: 1, Name: "kitty & co", Tag: &tag}, {Id: 2, Name: "rex"}})
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<pets><pet><id>1</id><name>kitty &amp; co</name><tag>cat</tag></pet><pet><id>2</id><name>rex</name></pet></pets>`,
+		string(data))
+
+	// 对象中的数组是重复的子元素
+	type owner struct {
+		Name string   `json:"name"`
+		Pets []string `json:"pets"`
+	}
+	data, err = codec.XML.Marshal(owner{Name: "al", Pets: []string{"kitty", "rex"}})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<owner><name>al</name><pets>kitty</pets><pets>rex</pets></owner>`)
+	var got owner
+	require.NoError(t, codec.XML.Unmarshal(data, &got))
+	assert.Equal(t, owner{Name: "al", Pets: []string{"kitty", "rex"}}, got)
+
+	var pet codegenTest.Pet
+	assert.Error(t, codec.XML.Unmarshal([]byte(`<pet><id>one</id></pet>`), &pet))
+}
+
+func TestServerFormats(t *testing.T) {
+	for _, mediaType := range []string{codec.MIMEApplicationMsgpack, codec.MIMEApplicationYAML, codec.MIMEApplicationXML} {
+		t.Run(mediaType, func(t *testing.T) {
+			// 请求与响应都经过 testkit 中的 spec 校验
+			h := testkit.New(t, &testkit.Options{ClientOptions: []codegenTest.ClientOption{
+				codegenTest.WithRequestEditorFn(codec.WithAccept(mediaType)),
+				codegenTest.WithRequestEditorFn(codec.WithContentType(mediaType)),
+			}})
+			tag := "dog"
+			added := h.AddPet(codegenTest.NewPet{Name: "rex", Tag: &tag})
+			require.Equal(t, http.StatusOK, added.StatusCode(), string(added.Body))
+			assert.Equal(t, mediaType, added.HTTPResponse.Header.Get("Content-Type"))
+			require.NotNil(t, added.JSON200)
+			assert.Equal(t, "rex", added.JSON200.Name)
+			assert.Equal(t, &tag, added.JSON200.Tag)
+
+			found := h.FindPets(nil)
+			require.NotNil(t, found.JSON200, string(found.Body))
+			assert.Equal(t, []codegenTest.Pet{*added.JSON200}, *found.JSON200)
+
+			missing := h.FindPetById(404)
+			assert.Equal(t, http.StatusNotFound, missing.StatusCode())
+			require.NotNil(t, missing.JSONDefault, string(missing.Body))
+			assert.Equal(t, int32(http.StatusNotFound), missing.JSONDefault.Code)
+		})
+	}
+}
+
+func TestBinderRejectsMalformedBody(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{DisableRequestValidation: true})
+	req := httptest.NewRequest(http.MethodPost, testkit.DefaultBaseURL+"/pets", strings.NewReader("\xc1"))
+	req.Header.Set("Content-Type", codec.MIMEApplicationMsgpack)
+	rec := httptest.NewRecorder()
+	h.Echo.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, codec.MIMEApplicationJSON, strings.Split(rec.Header().Get("Content-Type"), ";")[0])
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept")
+}
+
+func TestNDJSON(t *testing.T) {
+	tag := "cat"
+	pets := []codegenTest.Pet{{Id: 1, Name: "kitty", Tag: &tag}, {Id: 2, Name: "rex"}}
+	data, err := codec.NDJSON.Marshal(pets)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"kitty\",\"tag\":\"cat\"}\n{\"id\":2,\"name\":\"rex\"}\n", string(data))
+	var decoded []codegenTest.Pet
+	require.NoError(t, codec.NDJSON.Unmarshal(data, &decoded))
+	assert.Equal(t, pets, decoded)
+
+	it := codec.NewIterator[codegenTest.Pet](io.NopCloser(strings.NewReader(string(data) + "{\"id\":")))
+	require.True(t, it.Next())
+	assert.Equal(t, pets[0], it.Value())
+	require.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err(), "最后一行不完整")
+	require.NoError(t, it.Close())
+
+	for accept, want := range map[string]bool{
+		"":                     false,
+		"*/*":                  false,
+		"application/json":     false,
+		"application/x-ndjson": true,
+		"application/jsonl":    true,
+		"application/json;q=0.5, application/x-ndjson": true,
+		"application/x-ndjson;q=0.5, application/json": false,
+	} {
+		assert.Equal(t, want, codec.AcceptsNDJSON(accept), accept)
+	}
+	assert.True(t, codec.IsNDJSON("application/x-ndjson; charset=utf-8"))
+	assert.False(t, codec.IsNDJSON(codec.MIMEApplicationJSON))
+}
+
+func TestFindPetsStream(t *testing.T) {
+	// 超过 FlushEvery，响应分多次写出；testkit 按数组 schema 校验整个流
+	h := testkit.New(t, nil)
+	names := make([]string, 3*codec.FlushEvery)
+	for i := range names {
+		names[i] = "pet"
+	}
+	h.SeedNames(names...)
+	tag := "dog"
+	h.Seed(codegenTest.NewPet{Name: "rex", Tag: &tag})
+
+	it, err := codegenTest.FindPetsStream(h.Ctx, h.Client, nil)
+	require.NoError(t, err)
+	var ids []int64
+	for it.Next() {
+		ids = append(ids, it.Value().Id)
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	require.Len(t, ids, len(names)+1)
+	for i, id := range ids {
+		assert.Equal(t, int64(i+1), id)
+	}
+
+	it, err = codegenTest.FindPetsStream(h.Ctx, h.Client, &codegenTest.FindPetsParams{Tags: &[]string{tag}})
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	assert.Equal(t, "rex", it.Value().Name)
+	assert.False(t, it.Next())
+	require.NoError(t, it.Close())
+
+	// 参数校验失败时错误按 JSON 返回
+	limit := int32(1)
+	_, err = codegenTest.FindPetsStream(h.Ctx, h.Client, &codegenTest.FindPetsParams{Limit: &limit})
+	var rspErr *codegenTest.ResponseError
+	require.True(t, errors.As(err, &rspErr), "%v", err)
+	assert.Equal(t, http.StatusBadRequest, rspErr.StatusCode)
+	require.NotNil(t, rspErr.Body)
+}
+
+// jsonOnlyServer 不支持流式的服务端
+type jsonOnlyServer struct {
+	*app.EchoServer
+}
+
+func (s jsonOnlyServer) FindPets(ctx echo.Context, params codegenTest.FindPetsParams) error {
+	ctx.Request().Header.Set(echo.HeaderAccept, codec.MIMEApplicationJSON)
+	return s.EchoServer.FindPets(ctx, params)
+}
+
+func TestFindPetsStreamFallback(t *testing.T) {
+	store := app.NewPetStore()
+	h := testkit.New(t, &testkit.Options{Server: jsonOnlyServer{&app.EchoServer{Store: store}}})
+	store.Add(codegenTest.NewPet{Name: "kitty"})
+	store.Add(codegenTest.NewPet{Name: "rex"})
+
+	it, err := codegenTest.FindPetsStream(h.Ctx, h.Client, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"kitty", "rex"}, names)
+}
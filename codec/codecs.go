@@ -0,0 +1,87 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// JSON encoding/json
+	JSON Codec = jsonCodec{}
+	// Msgpack MessagePack，字段名与 omitempty 沿用 json tag
+	Msgpack Codec = msgpackCodec{}
+	// YAML 先按 json tag 编码成 JSON 再转换，字段名与顺序与 JSON 一致
+	YAML Codec = yamlCodec{}
+	// XML 见 xml.go
+	XML Codec = xmlCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string { return MIMEApplicationJSON }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string { return MIMEApplicationMsgpack }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) MediaType() string { return MIMEApplicationYAML }
+
+func (yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// JSON 是 YAML 的子集，解析成 Node 可以保留键的顺序
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// blockStyle 去掉从 JSON 继承的 flow 风格与字符串引号，由 yaml 按需决定
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,76 @@
+package codec
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Binder 按 Content-Type 解码请求体，JSON 与未注册的类型（表单等）交给 echo.DefaultBinder。
+//
+//	e.Binder = &codec.Binder{}
+type Binder struct {
+	// Registry 为 nil 时使用 Default
+	Registry *Registry
+	echo.DefaultBinder
+}
+
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	req := c.Request()
+	co, ok := registry(b.Registry).Lookup(req.Header.Get(echo.HeaderContentType))
+	if !ok || co.MediaType() == MIMEApplicationJSON {
+		return b.DefaultBinder.Bind(i, c)
+	}
+	// 与 DefaultBinder 相同：先路径参数，GET、DELETE、HEAD 再绑定 query，最后是请求体
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	if req.ContentLength == 0 {
+		return nil
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if err := co.Unmarshal(data, i); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+// Respond 按 Accept 协商格式写出 v，并加上 Vary: Accept
+func Respond(c echo.Context, code int, v interface{}) error {
+	return respond(Default, c, code, v)
+}
+
+// Respond 与包级 Respond 相同，使用 r 协商
+func (r *Registry) Respond(c echo.Context, code int, v interface{}) error {
+	return respond(r, c, code, v)
+}
+
+func respond(r *Registry, c echo.Context, code int, v interface{}) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	co := r.Negotiate(c.Request().Header.Get(echo.HeaderAccept))
+	if co.MediaType() == MIMEApplicationJSON {
+		return c.JSON(code, v)
+	}
+	data, err := co.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(code, co.MediaType(), data)
+}
+
+func registry(r *Registry) *Registry {
+	if r == nil {
+		return Default
+	}
+	return r
+}
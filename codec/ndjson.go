@@ -0,0 +1,199 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationNDJSON 每行一个 JSON 值的流式格式
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// NDJSON 不在 Default 中注册，只有声明了流式响应的接口才协商它，见 AcceptsNDJSON。
+// Marshal 把切片的每个元素写成一行，其它值写成单独一行；Unmarshal 解码到切片时每行一个元素
+var NDJSON Codec = ndjsonCodec{}
+
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) MediaType() string { return MIMEApplicationNDJSON }
+
+func (ndjsonCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		err := enc.Encode(v)
+		return buf.Bytes(), err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (ndjsonCodec) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("codec: ndjson: Unmarshal(non-pointer %T)", v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	slice := rv.Elem()
+	if slice.Kind() != reflect.Slice {
+		return dec.Decode(v)
+	}
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	for {
+		elem := reflect.New(slice.Type().Elem())
+		if err := dec.Decode(elem.Interface()); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+}
+
+// ndjsonRegistry 只用于判断客户端是否要求 NDJSON，其余情况交给 Default 协商
+var ndjsonRegistry = func() *Registry {
+	r := NewRegistry(JSON)
+	r.Register(NDJSON, "application/jsonl", "application/ndjson")
+	return r
+}()
+
+// AcceptsNDJSON Accept 中 NDJSON 的优先级不低于 JSON 时返回 true
+func AcceptsNDJSON(accept string) bool {
+	return ndjsonRegistry.Negotiate(accept) == NDJSON
+}
+
+// FlushEvery StreamNDJSON 每写出多少行 Flush 一次，第一行总是立即 Flush
+var FlushEvery = 64
+
+// StreamNDJSON 写出 code 与 NDJSON 的 Content-Type，然后反复调用 next，每个值写成一行，
+// next 返回 false 时结束。值直接写入连接，客户端读得慢时写入阻塞，next 不会被提前调用；
+// 客户端断开后停止并返回请求 context 的错误。响应头写出之后的错误只能中断流
+func StreamNDJSON(c echo.Context, code int, next func() (interface{}, bool)) error {
+	ctx := c.Request().Context()
+	res := c.Response()
+	res.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	res.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	res.WriteHeader(code)
+	enc := json.NewEncoder(res)
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v, ok := next()
+		if !ok {
+			break
+		}
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		if n == 0 || (n+1)%FlushEvery == 0 {
+			res.Flush()
+		}
+	}
+	res.Flush()
+	return nil
+}
+
+// Iterator 逐行解码 NDJSON，只在调用 Next 时才从响应体读取。
+//
+//	for it.Next() {
+//		use(it.Value())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	next   func(*T) error
+	closer io.Closer
+	value  T
+	err    error
+}
+
+// NewIterator 从 body 逐行解码，Close 时关闭 body
+func NewIterator[T any](body io.ReadCloser) *Iterator[T] {
+	dec := json.NewDecoder(body)
+	return &Iterator[T]{next: func(v *T) error { return dec.Decode(v) }, closer: body}
+}
+
+// NewSliceIterator 遍历已经解码好的 items，用于服务端没有按 NDJSON 返回的情况
+func NewSliceIterator[T any](items []T) *Iterator[T] {
+	return &Iterator[T]{next: func(v *T) error {
+		if len(items) == 0 {
+			return io.EOF
+		}
+		*v, items = items[0], items[1:]
+		return nil
+	}}
+}
+
+// Next 解码下一个值，结束或出错时返回 false
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	var v T
+	if err := it.next(&v); err != nil {
+		it.err = err
+		return false
+	}
+	it.value = v
+	return true
+}
+
+// Value 最近一次 Next 解码的值
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err 正常结束时返回 nil
+func (it *Iterator[T]) Err() error {
+	if errors.Is(it.err, io.EOF) {
+		return nil
+	}
+	return it.err
+}
+
+// Close 关闭响应体，提前结束时也要调用
+func (it *Iterator[T]) Close() error {
+	if it.err == nil {
+		it.err = io.EOF
+	}
+	if it.closer == nil {
+		return nil
+	}
+	return it.closer.Close()
+}
+
+// IsNDJSON contentType 是否为 NDJSON 或其别名
+func IsNDJSON(contentType string) bool {
+	c, ok := ndjsonRegistry.Lookup(contentType)
+	return ok && c == NDJSON
+}
+
+// SplitNDJSON 按换行把请求体拆成逐行的原始内容，去掉首尾空白并跳过空行，不检查每行是否为合法 JSON。
+// 请求校验与 handler 都用它拆分，两边看到的行数一致：同一行里的两个 JSON 值是一项（不合法的）而不是两项
+func SplitNDJSON(body io.Reader) ([]json.RawMessage, error) {
+	var lines []json.RawMessage
+	r := bufio.NewReader(body)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// kin-openapi 只内置 JSON 与 YAML 的解码器，请求与响应校验遇到 msgpack、XML 与 NDJSON
+// 会报 unsupported content type。这里注册的解码器返回与 JSON 相同形状的值
+// （float64、[]interface{}、map[string]interface{}），schema 校验的结果与 JSON 请求一致。
+func init() {
+	for _, t := range []string{MIMEApplicationMsgpack, "application/x-msgpack", "application/vnd.msgpack"} {
+		openapi3filter.RegisterBodyDecoder(t, msgpackBodyDecoder)
+	}
+	for _, t := range []string{MIMEApplicationXML, "text/xml"} {
+		openapi3filter.RegisterBodyDecoder(t, xmlBodyDecoder)
+	}
+	for _, t := range []string{MIMEApplicationNDJSON, "application/jsonl", "application/ndjson"} {
+		openapi3filter.RegisterBodyDecoder(t, ndjsonBodyDecoder)
+	}
+}
+
+// ndjsonBodyDecoder 每行解码为数组的一个元素，spec 中 NDJSON 与 JSON 声明相同的数组 schema。
+// 按 SplitNDJSON 分行，任何一行不是合法的 JSON 时整个请求体无效
+func ndjsonBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	lines, err := SplitNDJSON(body)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	values := make([]interface{}, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal(line, &values[i]); err != nil {
+			return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: fmt.Errorf("line %d: %w", i+1, err)}
+		}
+	}
+	return values, nil
+}
+
+func msgpackBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	var value interface{}
+	if err := Msgpack.Unmarshal(data, &value); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	// msgpack 的整数是 int8、uint16 等，经 JSON 转一次统一成 float64
+	if data, err = json.Marshal(value); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	value = nil
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	return value, nil
+}
+
+func xmlBodyDecoder(body io.Reader, _ http.Header, schema *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	return schemaValue(root, schemaOf(schema)), nil
+}
+
+// schemaValue 按 schema 转换元素，与 typedValue 的规则相同；无法转换的文本保持字符串，
+// 交给 schema 校验报告类型错误
+func schemaValue(n *xmlNode, schema *openapi3.Schema) interface{} {
+	if schema == nil {
+		return untypedValue(n)
+	}
+	switch schemaType(schema) {
+	case openapi3.TypeObject:
+		properties := schemaProperties(schema)
+		object := make(map[string]interface{})
+		for _, child := range n.children {
+			property, ok := properties[child.name]
+			if !ok {
+				object[child.name] = untypedValue(child)
+				continue
+			}
+			if schemaType(property) == openapi3.TypeArray && (property.XML == nil || !property.XML.Wrapped) {
+				list, _ := object[child.name].([]interface{})
+				object[child.name] = append(list, schemaValue(child, schemaOf(property.Items)))
+				continue
+			}
+			object[child.name] = schemaValue(child, property)
+		}
+		return object
+	case openapi3.TypeArray:
+		list := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			list = append(list, schemaValue(child, schemaOf(schema.Items)))
+		}
+		return list
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(n.text), 64); err == nil {
+			return f
+		}
+	case openapi3.TypeBoolean:
+		if b, err := strconv.ParseBool(strings.TrimSpace(n.text)); err == nil {
+			return b
+		}
+	}
+	return n.text
+}
+
+func schemaOf(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+// schemaType 没有 type 时按 properties、items 或 allOf 推断
+func schemaType(schema *openapi3.Schema) string {
+	switch {
+	case schema.Type != "":
+		return schema.Type
+	case len(schema.Properties) > 0:
+		return openapi3.TypeObject
+	case schema.Items != nil:
+		return openapi3.TypeArray
+	}
+	for _, ref := range schema.AllOf {
+		if t := schemaType(ref.Value); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// schemaProperties 合并 allOf 中各部分的属性
+func schemaProperties(schema *openapi3.Schema) map[string]*openapi3.Schema {
+	properties := make(map[string]*openapi3.Schema)
+	for name, ref := range schema.Properties {
+		properties[name] = ref.Value
+	}
+	for _, ref := range schema.AllOf {
+		for name, property := range schemaProperties(ref.Value) {
+			if _, ok := properties[name]; !ok {
+				properties[name] = property
+			}
+		}
+	}
+	return properties
+}
@@ -0,0 +1,355 @@
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// XML 与 JSON 使用相同的字段名（json tag），生成的模型不需要 xml tag：
+//
+//   - 根元素是 Go 类型名首字母小写，例如 Pet 为 <pet>；切片为复数，例如 []Pet 为 <pets><pet>…</pet></pets>
+//   - 对象的属性是子元素，按 JSON 中的顺序输出；null 省略
+//   - 对象中的数组不加外层元素，每一项是一个同名子元素
+//
+// 解码时按目标类型判断哪些子元素是数组、哪些文本是数字，再经 JSON 写入目标值。
+type xmlCodec struct{}
+
+func (xmlCodec) MediaType() string { return MIMEApplicationXML }
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := readJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	root, item := rootNames(reflect.TypeOf(v))
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if value.kind == jsonArray {
+		err = writeArray(enc, root, item, value)
+	} else {
+		err = writeElement(enc, root, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	root, err := parseXML(data)
+	if err != nil {
+		return err
+	}
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return errors.New("codec: xml unmarshal target must be a non-nil pointer")
+	}
+	data, err = json.Marshal(typedValue(root, t.Elem()))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type jsonKind int
+
+const (
+	jsonScalar jsonKind = iota
+	jsonNull
+	jsonObject
+	jsonArray
+)
+
+// jsonValue 保留对象键顺序的 JSON 值
+type jsonValue struct {
+	kind   jsonKind
+	text   string
+	keys   []string
+	values []*jsonValue
+}
+
+func readJSON(dec *json.Decoder) (*jsonValue, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		value := &jsonValue{kind: jsonArray}
+		if t == '{' {
+			value.kind = jsonObject
+		}
+		for dec.More() {
+			if value.kind == jsonObject {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value.keys = append(value.keys, key.(string))
+			}
+			item, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			value.values = append(value.values, item)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case nil:
+		return &jsonValue{kind: jsonNull}, nil
+	default:
+		return &jsonValue{kind: jsonScalar, text: fmt.Sprint(t)}, nil
+	}
+}
+
+func writeElement(enc *xml.Encoder, name string, value *jsonValue) error {
+	switch value.kind {
+	case jsonNull:
+		return nil
+	case jsonArray:
+		return writeArray(enc, name, "item", value)
+	case jsonScalar:
+		return enc.EncodeElement(value.text, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for i, key := range value.keys {
+		child := value.values[i]
+		if child.kind != jsonArray {
+			if err := writeElement(enc, key, child); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, item := range child.values {
+			if err := writeElement(enc, key, item); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func writeArray(enc *xml.Encoder, name, item string, value *jsonValue) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, v := range value.values {
+		if err := writeElement(enc, item, v); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// rootNames 根元素与数组项的名称
+func rootNames(t reflect.Type) (root, item string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "value", "item"
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		item, _ = rootNames(t.Elem())
+		if item == "value" {
+			return "items", "item"
+		}
+		return item + "s", item
+	}
+	if t.Name() == "" {
+		return "value", "item"
+	}
+	return lowerFirst(t.Name()), "item"
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// xmlNode 解析后的元素，忽略属性与命名空间
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+func parseXML(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("codec: empty xml document")
+	}
+	return root, nil
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// typedValue 按目标类型把元素转换成可以 json.Marshal 的值
+func typedValue(n *xmlNode, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return n.text
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFields(t)
+		object := make(map[string]interface{})
+		for _, child := range n.children {
+			ft, ok := fields[child.name]
+			if !ok {
+				continue
+			}
+			if elem, ok := listElem(ft); ok {
+				list, _ := object[child.name].([]interface{})
+				object[child.name] = append(list, typedValue(child, elem))
+				continue
+			}
+			object[child.name] = typedValue(child, ft)
+		}
+		return object
+	case reflect.Map:
+		object := make(map[string]interface{})
+		for _, child := range n.children {
+			object[child.name] = typedValue(child, t.Elem())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return strings.TrimSpace(n.text)
+		}
+		list := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			list = append(list, typedValue(child, t.Elem()))
+		}
+		return list
+	case reflect.Interface:
+		return untypedValue(n)
+	case reflect.Bool:
+		return json.RawMessage(strings.TrimSpace(n.text))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return json.Number(strings.TrimSpace(n.text))
+	}
+	return n.text
+}
+
+// untypedValue 没有类型信息时：有子元素的是对象，重复的子元素合并为数组，否则是文本
+func untypedValue(n *xmlNode) interface{} {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	object := make(map[string]interface{})
+	for _, child := range n.children {
+		value := untypedValue(child)
+		switch existing := object[child.name].(type) {
+		case nil:
+			object[child.name] = value
+		case []interface{}:
+			object[child.name] = append(existing, value)
+		default:
+			object[child.name] = []interface{}{existing, value}
+		}
+	}
+	return object
+}
+
+// listElem 对象中以重复子元素表示的字段，返回数组项的类型
+func listElem(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		return t.Elem(), true
+	}
+	return nil, false
+}
+
+// jsonFields 按 json tag 的名称列出字段类型，包括嵌入结构体的字段
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = ft
+	}
+	return fields
+}
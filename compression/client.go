@@ -0,0 +1,104 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	codegenTest "demo/oapi-codegen-go"
+)
+
+// ClientOptions 配置 NewDoer
+type ClientOptions struct {
+	// Encodings 声明在 Accept-Encoding 中的编码及偏好顺序，为 nil 时使用 DefaultEncodings
+	Encodings []string
+	// RequestEncoding 请求体的压缩编码，为空时不压缩请求体
+	RequestEncoding string
+	// MinSize 请求体达到此大小才压缩，0 表示 DefaultMinSize
+	MinSize int
+	// Limits 响应体解压的限制
+	Limits Limits
+}
+
+// Doer 在请求上声明 Accept-Encoding 并透明解压响应，可选地压缩请求体
+type Doer struct {
+	next    codegenTest.HttpRequestDoer
+	options ClientOptions
+	accept  string
+}
+
+// NewDoer 包装 next，next 为 nil 时使用 http.DefaultClient。
+// RequestEncoding 不受支持时返回 *ErrUnsupported
+func NewDoer(next codegenTest.HttpRequestDoer, options ClientOptions) (*Doer, error) {
+	if next == nil {
+		next = http.DefaultClient
+	}
+	if options.Encodings == nil {
+		options.Encodings = DefaultEncodings
+	}
+	if options.MinSize <= 0 {
+		options.MinSize = DefaultMinSize
+	}
+	for _, encoding := range append([]string{options.RequestEncoding}, options.Encodings...) {
+		if encoding != "" && !supported(encoding) {
+			return nil, &ErrUnsupported{Encoding: encoding}
+		}
+	}
+	return &Doer{next: next, options: options, accept: acceptEncoding(options.Encodings)}, nil
+}
+
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", d.accept)
+	}
+	if err := d.compressBody(req); err != nil {
+		return nil, err
+	}
+	rsp, err := d.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	encodings, err := parseEncodings(rsp.Header.Get("Content-Encoding"))
+	if err != nil || len(encodings) == 0 {
+		return rsp, err
+	}
+	body, err := newReader(rsp.Body, encodings, d.options.Limits)
+	if err != nil {
+		rsp.Body.Close()
+		return nil, err
+	}
+	rsp.Body = body
+	rsp.Header.Del("Content-Encoding")
+	rsp.Header.Del("Content-Length")
+	rsp.ContentLength = -1
+	rsp.Uncompressed = true
+	return rsp, nil
+}
+
+// compressBody 压缩达到 MinSize 且尚未编码的请求体
+func (d *Doer) compressBody(req *http.Request) error {
+	if d.options.RequestEncoding == "" || req.Body == nil || req.Body == http.NoBody ||
+		req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if len(data) >= d.options.MinSize {
+		if data, err = compress(d.options.RequestEncoding, data); err != nil {
+			return err
+		}
+		req.Header.Set("Content-Encoding", d.options.RequestEncoding)
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
@@ -0,0 +1,276 @@
+// Package compression 按 Accept-Encoding 压缩响应，按 Content-Encoding 解压请求体，支持 gzip、br 与 zstd。
+//
+// 服务端由 New 返回的中间件需要注册在请求校验之前：请求体先在这里解压并检查压缩炸弹，
+// 校验与 handler 看到的都是解压后的内容。响应在达到 MinSize 后才开始压缩，
+// handler 调用 Flush（SSE、NDJSON 等流式响应）时立即压缩并把已写出的数据刷到客户端。
+//
+// spec 中 operation 的 x-compression 扩展可以按接口调整（Options.Router 为 reload.Validator 时随热更新生效）：
+//
+//	x-compression: false            # 不压缩响应
+//	x-compression: {minSize: 256}   # 覆盖 MinSize
+//
+// 客户端使用 NewDoer 包装传输，自动声明 Accept-Encoding 并解压响应。
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// Extension operation 上的扩展名
+const Extension = "x-compression"
+
+// DefaultMinSize 小于此大小的响应不压缩
+const DefaultMinSize = 1024
+
+// DefaultSkipContentTypes 本身已经压缩过的类型，按前缀匹配
+var DefaultSkipContentTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/zstd"}
+
+// Options 控制 Middleware
+type Options struct {
+	// Encodings 允许的响应编码及偏好顺序，为 nil 时使用 DefaultEncodings
+	Encodings []string
+	// MinSize 响应体达到此大小才压缩，0 表示 DefaultMinSize
+	MinSize int
+	// SkipContentTypes 不压缩的 Content-Type 前缀，为 nil 时使用 DefaultSkipContentTypes
+	SkipContentTypes []string
+	// Limits 请求体解压的限制
+	Limits Limits
+	// Router 不为 nil 时按找到的 operation 的 x-compression 扩展调整，
+	// 传入 reload.Validator 即可跟随 spec 的热更新
+	Router routers.Router
+	// Swagger Router 为 nil 时由它构建固定的路由
+	Swagger *openapi3.T
+}
+
+// setting 某个 operation 的压缩设置
+type setting struct {
+	disabled bool
+	minSize  int
+}
+
+// New 返回解压请求体并压缩响应的中间件，Swagger 无法构建路由时返回错误
+func New(options Options) (echo.MiddlewareFunc, error) {
+	if options.Encodings == nil {
+		options.Encodings = DefaultEncodings
+	}
+	if options.MinSize <= 0 {
+		options.MinSize = DefaultMinSize
+	}
+	if options.SkipContentTypes == nil {
+		options.SkipContentTypes = DefaultSkipContentTypes
+	}
+	router := options.Router
+	if router == nil && options.Swagger != nil {
+		var err error
+		if router, err = gorillamux.NewRouter(options.Swagger); err != nil {
+			return nil, err
+		}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := decompressRequest(c.Request(), options.Limits); err != nil {
+				return err
+			}
+			s := setting{minSize: options.MinSize}
+			if router != nil {
+				if route, _, err := router.FindRoute(c.Request()); err == nil && route.Operation != nil {
+					s = operationSetting(route.Operation, s)
+				}
+			}
+			if s.disabled || c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			encoding := negotiate(c.Request().Header.Get(echo.HeaderAcceptEncoding), options.Encodings)
+			if encoding == "" {
+				return next(c)
+			}
+			w := &responseWriter{
+				ResponseWriter: res.Writer,
+				encoding:       encoding,
+				minSize:        s.minSize,
+				skip:           options.SkipContentTypes,
+			}
+			res.Writer = w
+			defer func() {
+				if err := w.close(); err != nil {
+					c.Logger().Error(err)
+				}
+				res.Writer = w.ResponseWriter
+			}()
+			return next(c)
+		}
+	}, nil
+}
+
+// decompressRequest 解压请求体并替换为解压后的内容，去掉 Content-Encoding
+func decompressRequest(req *http.Request, limits Limits) error {
+	contentEncoding := req.Header.Get(echo.HeaderContentEncoding)
+	if contentEncoding == "" || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	encodings, err := parseEncodings(contentEncoding)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, err.Error()).SetInternal(err)
+	}
+	if len(encodings) == 0 {
+		req.Header.Del(echo.HeaderContentEncoding)
+		return nil
+	}
+	r, err := newReader(req.Body, encodings, limits)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid compressed request body").SetInternal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if errors.Is(err, ErrTooLarge) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, ErrTooLarge.Error()).SetInternal(err)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid compressed request body").SetInternal(err)
+	}
+	req.Header.Del(echo.HeaderContentEncoding)
+	req.Header.Del(echo.HeaderContentLength)
+	req.ContentLength = int64(len(data))
+	// 请求校验优先用 GetBody 放回请求体，也要返回解压后的内容
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// operationSetting 读取 x-compression：false 关闭，对象中的 minSize 覆盖阈值
+func operationSetting(op *openapi3.Operation, s setting) setting {
+	switch v := op.Extensions[Extension].(type) {
+	case bool:
+		s.disabled = !v
+	case map[string]interface{}:
+		if minSize, ok := v["minSize"].(float64); ok && minSize >= 0 {
+			s.minSize = int(minSize)
+		}
+	}
+	return s
+}
+
+// responseWriter 缓冲到 minSize 再决定是否压缩
+type responseWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	skip     []string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     encoder
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush 流式响应不等 minSize，立即开始压缩并刷出
+func (w *responseWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide 写出响应头与缓冲的数据；large 为 false 时不压缩
+func (w *responseWriter) decide(large bool) error {
+	w.decided = true
+	header := w.Header()
+	if large && w.compressible(header) {
+		header.Set(echo.HeaderContentEncoding, w.encoding)
+		header.Del(echo.HeaderContentLength)
+		w.ResponseWriter.WriteHeader(w.status)
+		w.enc = getEncoder(w.encoding, w.ResponseWriter)
+		_, err := w.enc.Write(w.buf)
+		w.buf = nil
+		return err
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+func (w *responseWriter) compressible(header http.Header) bool {
+	if header.Get(echo.HeaderContentEncoding) != "" ||
+		w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+	contentType := header.Get(echo.HeaderContentType)
+	for _, prefix := range w.skip {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// close 结束压缩流；handler 没有写出任何内容时不做处理
+func (w *responseWriter) close() error {
+	if !w.decided {
+		if w.status == 0 {
+			return nil
+		}
+		if err := w.decide(len(w.buf) >= w.minSize); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	putEncoder(w.encoding, w.enc)
+	w.enc = nil
+	return err
+}
@@ -0,0 +1,297 @@
+package compression_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/compression"
+	"demo/oapi-codegen-go/reload"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDoer 记录每个响应在解压之前的 Content-Encoding
+type recordingDoer struct {
+	next      codegenTest.HttpRequestDoer
+	encodings []string
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	rsp, err := d.next.Do(req)
+	if err == nil {
+		d.encodings = append(d.encodings, rsp.Header.Get("Content-Encoding"))
+	}
+	return rsp, err
+}
+
+func newClient(t *testing.T, h *testkit.Harness, options compression.ClientOptions) (*codegenTest.ClientWithResponses, *recordingDoer) {
+	t.Helper()
+	recorder := &recordingDoer{next: h.Doer}
+	doer, err := compression.NewDoer(recorder, options)
+	require.NoError(t, err)
+	client, err := codegenTest.NewClientWithResponses("http://testkit"+testkit.DefaultBaseURL, codegenTest.WithHTTPClient(doer))
+	require.NoError(t, err)
+	return client, recorder
+}
+
+func seed(h *testkit.Harness, n int) {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = strings.Repeat("pet", 10)
+	}
+	h.SeedNames(names...)
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	for _, encoding := range compression.DefaultEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			h := testkit.New(t, &testkit.Options{Compression: &compression.Options{Encodings: []string{encoding}}})
+			seed(h, 50)
+			client, recorder := newClient(t, h, compression.ClientOptions{RequestEncoding: encoding, MinSize: 1})
+
+			found, err := client.FindPetsWithResponse(h.Ctx, nil)
+			require.NoError(t, err)
+			require.NotNil(t, found.JSON200, string(found.Body))
+			assert.Len(t, *found.JSON200, 50)
+			assert.Equal(t, encoding, recorder.encodings[0])
+			assert.Empty(t, found.HTTPResponse.Header.Get("Content-Encoding"))
+
+			// 压缩的请求体在校验之前解压，校验与 handler 都能正常处理
+			added, err := client.AddPetWithResponse(h.Ctx, codegenTest.NewPet{Name: "rex"})
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, added.StatusCode(), string(added.Body))
+			assert.Equal(t, "rex", added.JSON200.Name)
+
+			// 解压后的请求体仍然按 schema 校验
+			rsp, err := client.AddPetWithBody(h.Ctx, echo.MIMEApplicationJSON, strings.NewReader(`{"tag":"dog"}`))
+			require.NoError(t, err)
+			rsp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+		})
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{Compression: &compression.Options{}})
+	seed(h, 50)
+	for accept, want := range map[string]string{
+		"":                           "",
+		"identity":                   "",
+		"gzip":                       compression.Gzip,
+		"x-gzip":                     compression.Gzip,
+		"gzip, br":                   compression.Brotli,
+		"gzip, br, zstd":             compression.Zstd,
+		"gzip;q=1, br;q=0.5":         compression.Gzip,
+		"*":                          compression.Zstd,
+		"*, zstd;q=0":                compression.Brotli,
+		"gzip;q=0, br;q=0, zstd;q=0": "",
+		"deflate":                    "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, testkit.DefaultBaseURL+"/pets", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, accept)
+		rec := httptest.NewRecorder()
+		h.Echo.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, accept)
+		assert.Equal(t, want, rec.Header().Get(echo.HeaderContentEncoding), accept)
+		assert.Equal(t, echo.HeaderAcceptEncoding, rec.Header().Get(echo.HeaderVary), accept)
+	}
+}
+
+func TestMinSize(t *testing.T) {
+	get := func(h *testkit.Harness, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, testkit.DefaultBaseURL+path, nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		rec := httptest.NewRecorder()
+		h.Echo.ServeHTTP(rec, req)
+		return rec
+	}
+
+	h := testkit.New(t, &testkit.Options{Compression: &compression.Options{MinSize: 32}})
+	seed(h, 1)
+	// 单个宠物超过全局的 32 字节，压缩
+	assert.Equal(t, compression.Gzip, get(h, "/pets/1").Header().Get(echo.HeaderContentEncoding))
+	// findPets 的 x-compression 把阈值提高到 256 字节
+	rec := get(h, "/pets")
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Less(t, rec.Body.Len(), 256)
+	seed(h, 10)
+	assert.Equal(t, compression.Gzip, get(h, "/pets").Header().Get(echo.HeaderContentEncoding))
+
+	// x-compression: false 关闭压缩
+	swagger, err := codegenTest.GetSwaggerWithPrefix(testkit.DefaultBaseURL)
+	require.NoError(t, err)
+	swagger.Paths.Find("/api/pets").Get.Extensions[compression.Extension] = false
+	h = testkit.New(t, &testkit.Options{Compression: &compression.Options{MinSize: 32, Swagger: swagger}})
+	seed(h, 10)
+	rec = get(h, "/pets")
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, compression.Gzip, get(h, "/pets/1").Header().Get(echo.HeaderContentEncoding))
+}
+
+func TestRequestLimits(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{Compression: &compression.Options{Limits: compression.Limits{MaxSize: 1 << 20}}})
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, testkit.DefaultBaseURL+"/pets", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderContentEncoding, encoding)
+		rec := httptest.NewRecorder()
+		h.Echo.ServeHTTP(rec, req)
+		return rec
+	}
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(data)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	rec := post("gzip", gzipped([]byte(`{"name":"rex"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// 压缩比超过 100：几 KiB 的数据展开为 512 KiB 的空白
+	bomb := gzipped(append(append([]byte(`{"name":"rex"`), bytes.Repeat([]byte(" "), 512<<10)...), '}'))
+	rec = post("gzip", bomb)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+
+	// 超过 MaxSize
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 2<<20; i++ {
+		buf.WriteString(`{"name":"` + strings.Repeat("x", i%97) + `"}`)
+	}
+	rec = post("gzip", gzipped(buf.Bytes()))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+
+	rec = post("compress", []byte(`{"name":"rex"}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code, rec.Body.String())
+
+	rec = post("gzip", []byte(`{"name":"rex"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
+
+func TestStreamingFlush(t *testing.T) {
+	release := make(chan struct{})
+	e := echo.New()
+	mw, err := compression.New(compression.Options{})
+	require.NoError(t, err)
+	e.Use(mw)
+	e.GET("/events", func(c echo.Context) error {
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(res, "data: first\n\n")
+		res.Flush()
+		<-release
+		_, _ = io.WriteString(res, "data: second\n\n")
+		return nil
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rsp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	assert.Equal(t, compression.Gzip, rsp.Header.Get("Content-Encoding"))
+
+	// 第一个事件远小于 MinSize，但 Flush 之后客户端就能解压读到，不必等 handler 返回
+	zr, err := gzip.NewReader(rsp.Body)
+	require.NoError(t, err)
+	r := bufio.NewReader(zr)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n", line)
+
+	close(release)
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "\ndata: second\n\n", string(rest))
+}
+
+func TestDecodesEveryEncoding(t *testing.T) {
+	data, err := json.Marshal(map[string]string{"name": strings.Repeat("rex", 1000)})
+	require.NoError(t, err)
+	e := echo.New()
+	mw, err := compression.New(compression.Options{})
+	require.NoError(t, err)
+	e.Use(mw)
+	e.POST("/echo", func(c echo.Context) error {
+		assert.Empty(t, c.Request().Header.Get(echo.HeaderContentEncoding))
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, body)
+	})
+
+	encode := map[string]func(w io.Writer) io.WriteCloser{
+		compression.Gzip:   func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		compression.Brotli: func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		compression.Zstd: func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		},
+	}
+	for encoding, newWriter := range encode {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		_, _ = w.Write(data)
+		require.NoError(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/echo", &buf)
+		req.Header.Set(echo.HeaderContentEncoding, encoding)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, encoding)
+		assert.Equal(t, data, rec.Body.Bytes(), encoding)
+	}
+}
+
+func TestFollowsReload(t *testing.T) {
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	validator, err := reload.New(reload.Options{Path: path, Prefix: "/api", Logger: log.New(io.Discard, "", 0)})
+	require.NoError(t, err)
+
+	e := echo.New()
+	mw, err := compression.New(compression.Options{MinSize: 32, Router: validator})
+	require.NoError(t, err)
+	e.Use(mw)
+	e.GET("/api/pets", func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("rex", 50))
+	})
+	get := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/api/pets", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, compression.Gzip)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Header().Get(echo.HeaderContentEncoding)
+	}
+	// findPets 的 minSize: 256 高于 150 字节的响应
+	assert.Empty(t, get())
+
+	data = bytes.Replace(data, []byte("minSize: 256"), []byte("minSize: 64"), 1)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	_, err = validator.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, compression.Gzip, get())
+}
@@ -0,0 +1,252 @@
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// 支持的 Content-Encoding
+const (
+	Gzip     = "gzip"
+	Brotli   = "br"
+	Zstd     = "zstd"
+	Identity = "identity"
+)
+
+// DefaultEncodings 服务端的偏好顺序，Accept-Encoding 中 q 值相同时靠前的优先
+var DefaultEncodings = []string{Zstd, Brotli, Gzip}
+
+// encoder 三种压缩器共同的方法，Reset 后可以复用
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	Gzip: {New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	Brotli: {New: func() interface{} {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	Zstd: {New: func() interface{} {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+}
+
+func getEncoder(encoding string, w io.Writer) encoder {
+	enc := encoderPools[encoding].Get().(encoder)
+	enc.Reset(w)
+	return enc
+}
+
+func putEncoder(encoding string, enc encoder) {
+	enc.Reset(nil)
+	encoderPools[encoding].Put(enc)
+}
+
+// compress 一次性压缩 data
+func compress(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := getEncoder(encoding, &buf)
+	defer putEncoder(encoding, enc)
+	if _, err := enc.Write(data); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func supported(encoding string) bool {
+	_, ok := encoderPools[encoding]
+	return ok
+}
+
+// negotiate 按 Accept-Encoding 选择 encodings 中的一种，q 值相同时按 encodings 的顺序；
+// 返回 "" 表示不压缩
+func negotiate(acceptEncoding string, encodings []string) string {
+	q := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		value := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(k) == "q" {
+				var err error
+				if value, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+					value = 0
+				}
+			}
+		}
+		if coding == "*" {
+			wildcard = value
+			continue
+		}
+		if coding == "x-gzip" {
+			coding = Gzip
+		}
+		q[coding] = value
+	}
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		value, ok := q[encoding]
+		if !ok {
+			value = wildcard
+		}
+		if value > bestQ {
+			best, bestQ = encoding, value
+		}
+	}
+	return best
+}
+
+// Limits 解压时的限制，防止压缩炸弹
+type Limits struct {
+	// MaxRatio 解压后与压缩数据的大小之比上限，解压后不足 64 KiB 时不检查。0 表示 DefaultMaxRatio
+	MaxRatio float64
+	// MaxSize 解压后的最大字节数，0 表示 DefaultMaxSize
+	MaxSize int64
+}
+
+const (
+	DefaultMaxRatio = 100
+	DefaultMaxSize  = 32 << 20
+	// ratioFloor 以下不检查压缩比，很小的请求体压缩比可能天然很高
+	ratioFloor = 64 << 10
+)
+
+// ErrTooLarge 解压后的数据超过 Limits
+var ErrTooLarge = errors.New("compression: decompressed body exceeds limit")
+
+// ErrUnsupported 不支持的 Content-Encoding
+type ErrUnsupported struct {
+	Encoding string
+}
+
+func (e *ErrUnsupported) Error() string {
+	return fmt.Sprintf("compression: unsupported content encoding %q", e.Encoding)
+}
+
+// parseEncodings 解析 Content-Encoding，返回按应用顺序排列的编码，identity 被忽略
+func parseEncodings(contentEncoding string) ([]string, error) {
+	var encodings []string
+	for _, part := range strings.Split(contentEncoding, ",") {
+		encoding := strings.ToLower(strings.TrimSpace(part))
+		switch encoding {
+		case "", Identity:
+			continue
+		case "x-gzip":
+			encoding = Gzip
+		}
+		if !supported(encoding) {
+			return nil, &ErrUnsupported{Encoding: encoding}
+		}
+		encodings = append(encodings, encoding)
+	}
+	return encodings, nil
+}
+
+// newReader 按 encodings 逆序解压 body，读取超过 limits 时返回 ErrTooLarge
+func newReader(body io.ReadCloser, encodings []string, limits Limits) (io.ReadCloser, error) {
+	if limits.MaxRatio <= 0 {
+		limits.MaxRatio = DefaultMaxRatio
+	}
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = DefaultMaxSize
+	}
+	counted := &countingReader{r: body}
+	var r io.Reader = counted
+	closers := []io.Closer{body}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch encodings[i] {
+		case Gzip:
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			r = zr
+		case Brotli:
+			r = brotli.NewReader(r)
+		case Zstd:
+			zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limits.MaxSize)))
+			if err != nil {
+				return nil, err
+			}
+			closers = append(closers, closerFunc(func() error { zr.Close(); return nil }))
+			r = zr
+		}
+	}
+	return &limitedReader{r: r, compressed: counted, limits: limits, closers: closers}, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type limitedReader struct {
+	r          io.Reader
+	compressed *countingReader
+	limits     Limits
+	n          int64
+	closers    []io.Closer
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limits.MaxSize ||
+		l.n > ratioFloor && float64(l.n) > l.limits.MaxRatio*float64(l.compressed.n) {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedReader) Close() error {
+	var first error
+	for i := len(l.closers) - 1; i >= 0; i-- {
+		if err := l.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+// acceptEncoding 按偏好顺序生成 Accept-Encoding，q 值递减
+func acceptEncoding(encodings []string) string {
+	parts := make([]string, 0, len(encodings))
+	for i, encoding := range encodings {
+		if i == 0 {
+			parts = append(parts, encoding)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s;q=%.1f", encoding, 1-0.1*float64(i)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/internal/httpdoer"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// recordedHeaders 契约只关心这些 header，其它（鉴权、追踪等）不写入文件
+var recordedHeaders = []string{"Content-Type", "Accept"}
+
+// Consumer 在消费者的客户端测试中记录交互
+//
+//	pact := contract.NewConsumer(t, "web", "petstore", swagger)
+//	client, _ := codegenTest.NewClientWithResponses("http://petstore/api",
+//		codegenTest.WithHTTPClient(pact.Doer(doer, "/api")))
+//	pact.Given("pet 1 exists").Upon("fetch a pet")
+//	client.FindPetByIdWithResponse(ctx, 1)
+//	require.NoError(t, pact.Write("pacts/web-petstore.json"))
+type Consumer struct {
+	t         testing.TB
+	validator *validator
+
+	mu          sync.Mutex
+	contract    *Contract
+	states      []string
+	description string
+}
+
+// NewConsumer swagger 使用不带前缀的文档（codegenTest.GetSwagger）
+func NewConsumer(t testing.TB, consumer, provider string, swagger *openapi3.T) *Consumer {
+	t.Helper()
+	v, err := newValidator(swagger)
+	if err != nil {
+		t.Fatalf("contract: %v", err)
+	}
+	return &Consumer{
+		t:         t,
+		validator: v,
+		contract:  &Contract{Consumer: consumer, Provider: provider, Interactions: map[string][]Interaction{}},
+	}
+}
+
+// Given 设置之后记录的交互所需的 provider state，直到下一次调用 Given
+func (c *Consumer) Given(states ...string) *Consumer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states = append([]string(nil), states...)
+	return c
+}
+
+// Upon 设置下一条交互的描述，只作用于一次请求
+func (c *Consumer) Upon(description string) *Consumer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.description = description
+	return c
+}
+
+// Doer 包装客户端传输。basePath 为客户端 server 地址中的路径部分，会从记录的 Path 中去掉
+func (c *Consumer) Doer(next codegenTest.HttpRequestDoer, basePath string) codegenTest.HttpRequestDoer {
+	basePath = strings.TrimSuffix(basePath, "/")
+	return httpdoer.Func(func(req *http.Request) (*http.Response, error) {
+		reqBody, err := httpdoer.ReadBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := httpdoer.ReadBody(&resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		interaction := Interaction{
+			Request: Request{
+				Method: req.Method,
+				Path:   strings.TrimPrefix(req.URL.Path, basePath),
+				Query:  req.URL.Query(),
+				Header: pick(req.Header),
+				Body:   jsonBody(reqBody),
+			},
+			Response: Response{
+				Status: resp.StatusCode,
+				Header: pick(resp.Header),
+				Body:   jsonBody(respBody),
+			},
+		}
+		if len(interaction.Request.Query) == 0 {
+			interaction.Request.Query = nil
+		}
+		c.record(req.Context(), interaction)
+		return resp, nil
+	})
+}
+
+// record 不符合文档的交互直接让测试失败，不写入契约
+func (c *Consumer) record(ctx context.Context, interaction Interaction) {
+	c.mu.Lock()
+	interaction.ProviderStates = c.states
+	interaction.Description = c.description
+	c.description = ""
+	c.mu.Unlock()
+
+	id, err := c.validator.validate(ctx, interaction)
+	if err != nil {
+		c.t.Errorf("contract: interaction is not valid per the spec: %v", err)
+		return
+	}
+	c.mu.Lock()
+	c.contract.Interactions[id] = append(c.contract.Interactions[id], interaction)
+	c.mu.Unlock()
+}
+
+// Contract 返回目前记录的契约
+func (c *Consumer) Contract() *Contract {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copied := *c.contract
+	copied.Interactions = make(map[string][]Interaction, len(c.contract.Interactions))
+	for id, interactions := range c.contract.Interactions {
+		copied.Interactions[id] = append([]Interaction(nil), interactions...)
+	}
+	return &copied
+}
+
+// Write 把契约写入 path
+func (c *Consumer) Write(path string) error {
+	return c.Contract().Write(path)
+}
+
+func pick(header http.Header) http.Header {
+	picked := http.Header{}
+	for _, name := range recordedHeaders {
+		if value := header.Get(name); value != "" {
+			picked.Set(name, value)
+		}
+	}
+	if len(picked) == 0 {
+		return nil
+	}
+	return picked
+}
+
+// jsonBody 契约中的 body 以 JSON 保存，非 JSON 内容保存为 JSON 字符串
+func jsonBody(data []byte) json.RawMessage {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(bytes.TrimSpace(data))
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
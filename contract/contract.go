@@ -0,0 +1,194 @@
+// Package contract 实现消费者驱动的契约（pact 风格）。
+//
+// 消费者一侧用 Consumer.Doer 包装 ClientWithResponses 的传输，测试中发出的每个请求
+// 及其响应都会按 operationId 记入契约文件；提供者一侧用 Provider.Verify 在
+// RegisterHandlersWithBaseURL 注册的服务上逐条回放，先通过 provider state 钩子准备数据，
+// 再比较响应。两侧都会检查每条交互是否符合 OpenAPI 文档。
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Request 期望的请求，Path 不含服务挂载的前缀
+type Request struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  url.Values      `json:"query,omitempty"`
+	Header http.Header     `json:"headers,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Response 期望的响应。Body 中出现的字段必须存在且相等，实际响应可以多出字段
+type Response struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"headers,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Interaction 一条交互
+type Interaction struct {
+	Description string `json:"description,omitempty"`
+	// ProviderStates 回放前需要提供者准备的状态，例如 "pet 1 exists"
+	ProviderStates []string `json:"providerStates,omitempty"`
+	Request        Request  `json:"request"`
+	Response       Response `json:"response"`
+}
+
+// Contract 契约文件的内容
+type Contract struct {
+	Consumer string `json:"consumer"`
+	Provider string `json:"provider"`
+	// Interactions 按 operationId 分组，组内保持记录顺序
+	Interactions map[string][]Interaction `json:"interactions"`
+}
+
+// OperationIDs 按字母序返回契约涉及的 operation
+func (c *Contract) OperationIDs() []string {
+	ids := make([]string, 0, len(c.Interactions))
+	for id := range c.Interactions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Load 读取契约文件
+func Load(path string) (*Contract, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Contract{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("contract: decode %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Write 写入契约文件，必要时创建目录
+func (c *Contract) Write(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// validator 使用不带前缀的文档匹配交互中的 Path
+type validator struct {
+	swagger *openapi3.T
+	router  routers.Router
+}
+
+func newValidator(swagger *openapi3.T) (*validator, error) {
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		return nil, err
+	}
+	return &validator{swagger: swagger, router: router}, nil
+}
+
+// httpRequest 根据交互还原一个请求，只用于路由和校验
+func (i Interaction) httpRequest(ctx context.Context) (*http.Request, error) {
+	u := &url.URL{Path: i.Request.Path, RawQuery: i.Request.Query.Encode()}
+	var body io.Reader
+	if len(i.Request.Body) > 0 {
+		body = bytes.NewReader(rawBody(i.Request.Body, i.Request.Header))
+	}
+	req, err := http.NewRequestWithContext(ctx, i.Request.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = i.Request.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	return req, nil
+}
+
+// rawBody 还原实际发送的内容：非 JSON 媒体类型的 body 在契约中保存为 JSON 字符串
+func rawBody(body json.RawMessage, header http.Header) []byte {
+	if len(body) == 0 || strings.Contains(header.Get("Content-Type"), "json") {
+		return body
+	}
+	var text string
+	if json.Unmarshal(body, &text) == nil {
+		return []byte(text)
+	}
+	return body
+}
+
+// validate 检查请求和响应是否符合文档，返回对应的 operationId
+func (v *validator) validate(ctx context.Context, i Interaction) (string, error) {
+	req, err := i.httpRequest(ctx)
+	if err != nil {
+		return "", err
+	}
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", i.Request.Method, i.Request.Path, err)
+	}
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	}
+	if err := openapi3filter.ValidateRequest(ctx, requestInput); err != nil {
+		return route.Operation.OperationID, fmt.Errorf("request: %w", err)
+	}
+	responseInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 i.Response.Status,
+		Header:                 i.Response.Header,
+		Body:                   io.NopCloser(bytes.NewReader(rawBody(i.Response.Body, i.Response.Header))),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	if responseInput.Header == nil {
+		responseInput.Header = http.Header{}
+	}
+	if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+		return route.Operation.OperationID, fmt.Errorf("response: %w", err)
+	}
+	return route.Operation.OperationID, nil
+}
+
+// Validate 检查契约中的每条交互是否符合文档，并且记录在正确的 operationId 下
+func Validate(ctx context.Context, swagger *openapi3.T, c *Contract) []error {
+	v, err := newValidator(swagger)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, id := range c.OperationIDs() {
+		for n, interaction := range c.Interactions[id] {
+			got, err := v.validate(ctx, interaction)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s[%d]: %w", id, n, err))
+			case got != id:
+				errs = append(errs, fmt.Errorf("%s[%d]: request matches operation %s", id, n, got))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,142 @@
+package contract_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/contract"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// consume 模拟消费者团队的客户端测试，返回写出的契约文件
+func consume(t *testing.T) string {
+	spec, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	h := testkit.New(t, nil)
+	pact := contract.NewConsumer(t, "web", "petstore", spec)
+	client, err := codegenTest.NewClientWithResponses("http://petstore"+testkit.DefaultBaseURL,
+		codegenTest.WithHTTPClient(pact.Doer(h.Doer, testkit.DefaultBaseURL)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	h.SeedNames("baby")
+	pact.Given("pet 1 exists").Upon("fetch an existing pet")
+	found, err := client.FindPetByIdWithResponse(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "baby", found.JSON200.Name)
+
+	pact.Upon("list pets with limit")
+	listed, err := client.FindPetsWithResponse(ctx, &codegenTest.FindPetsParams{Limit: ptr(int32(12))})
+	require.NoError(t, err)
+	require.Len(t, *listed.JSON200, 1)
+
+	pact.Upon("add a second pet")
+	added, err := client.AddPetWithResponse(ctx, codegenTest.NewPet{Name: "kitty"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, added.StatusCode())
+
+	pact.Given().Upon("fetch a missing pet")
+	missing, err := client.FindPetByIdWithResponse(ctx, 99)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, missing.StatusCode())
+
+	path := filepath.Join(t.TempDir(), "pacts", "web-petstore.json")
+	require.NoError(t, pact.Write(path))
+	return path
+}
+
+func petstore(states map[string]contract.StateFunc) *contract.Provider {
+	return &contract.Provider{
+		BaseURL: "/api",
+		NewServer: func() codegenTest.ServerInterface {
+			return &app.EchoServer{Store: app.NewPetStore()}
+		},
+		States: states,
+		Setup: func(e *echo.Echo) {
+			e.HTTPErrorHandler = app.HTTPErrorHandler
+		},
+	}
+}
+
+var petExists = map[string]contract.StateFunc{
+	"pet 1 exists": func(server codegenTest.ServerInterface) error {
+		server.(*app.EchoServer).Store.Add(codegenTest.NewPet{Name: "baby"})
+		return nil
+	},
+}
+
+func TestConsumerProvider(t *testing.T) {
+	c, err := contract.Load(consume(t))
+	require.NoError(t, err)
+	assert.Equal(t, "web", c.Consumer)
+	require.Len(t, c.Interactions[findPetByID(t)], 2)
+	assert.Equal(t, []string{"pet 1 exists"}, c.Interactions[findPetByID(t)][0].ProviderStates)
+	assert.Empty(t, c.Interactions[findPetByID(t)][1].ProviderStates)
+
+	spec, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	assert.Empty(t, contract.Validate(context.Background(), spec, c))
+
+	verification, err := petstore(petExists).Verify(context.Background(), spec, c)
+	require.NoError(t, err)
+	assert.Len(t, verification.Results, 4)
+	verification.Check(t)
+}
+
+func TestProviderMismatches(t *testing.T) {
+	c, err := contract.Load(consume(t))
+	require.NoError(t, err)
+	spec, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+
+	// 没有实现 provider state
+	verification, err := petstore(nil).Verify(context.Background(), spec, c)
+	require.NoError(t, err)
+	failed := verification.Failed()
+	require.NotEmpty(t, failed)
+	assert.Contains(t, failed[0].Mismatches, `missing provider state "pet 1 exists"`)
+
+	// 期望与提供者的实际响应不一致
+	id := findPetByID(t)
+	c.Interactions[id][0].Response.Body = json.RawMessage(`{"id":1,"name":"kitty"}`)
+	verification, err = petstore(petExists).Verify(context.Background(), spec, c)
+	require.NoError(t, err)
+	failed = verification.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, []string{`$.name: expected "kitty", got "baby"`}, failed[0].Mismatches)
+}
+
+func TestValidateRejectsInvalidInteraction(t *testing.T) {
+	spec, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	c := &contract.Contract{Interactions: map[string][]contract.Interaction{
+		"AddPet": {{
+			Request: contract.Request{
+				Method: http.MethodPost,
+				Path:   "/pets",
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   json.RawMessage(`{"tag":"cat"}`),
+			},
+			Response: contract.Response{Status: http.StatusOK},
+		}},
+	}}
+	errs := contract.Validate(context.Background(), spec, c)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "request")
+}
+
+func findPetByID(t *testing.T) string {
+	spec, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	return spec.Paths["/pets/{id}"].Get.OperationID
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,94 @@
+package coverage_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/coverage"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportFromDoer(t *testing.T) {
+	recorder, err := testkit.NewCoverageRecorder("")
+	require.NoError(t, err)
+	h := testkit.New(t, &testkit.Options{Coverage: recorder})
+
+	h.AddPet(codegenTest.NewPet{Name: "baby"})
+	limit := int32(1)
+	h.FindPets(&codegenTest.FindPetsParams{Limit: &limit})
+
+	report := recorder.Report()
+	uncovered := report.Uncovered()
+	assert.NotContains(t, uncovered, "AddPet response 200 application/json")
+	assert.NotContains(t, uncovered, "AddPet param body:application/json")
+	assert.NotContains(t, uncovered, "FindPets response default application/json")
+	assert.NotContains(t, uncovered, "FindPets param query:limit")
+	assert.Contains(t, uncovered, "DeletePet response 204")
+	assert.Contains(t, uncovered, "FindPets response 200 application/json")
+	assert.Contains(t, uncovered, "FindPets param query:tags")
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "[x] response 200 application/json (1)")
+	assert.Contains(t, text.String(), fmt.Sprintf("API coverage: %d/%d", report.Covered(), report.Total()))
+}
+
+type recordingTB struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingTB) Errorf(string, ...interface{}) { r.failed = true }
+
+func TestCheckThreshold(t *testing.T) {
+	recorder, err := testkit.NewCoverageRecorder("")
+	require.NoError(t, err)
+	h := testkit.New(t, &testkit.Options{Coverage: recorder})
+	pet := h.AddPet(codegenTest.NewPet{Name: "baby"}).JSON200
+	h.DeletePet(pet.Id)
+
+	tb := &recordingTB{TB: t}
+	recorder.Report().Check(tb, 90)
+	assert.True(t, tb.failed)
+
+	// 每个响应按媒体类型分别统计，5 种格式只覆盖了 JSON
+	tb = &recordingTB{TB: t}
+	recorder.Report().Check(tb, 3)
+	assert.False(t, tb.failed)
+}
+
+func TestMiddleware(t *testing.T) {
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	recorder, err := coverage.NewRecorder(swagger)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = app.HTTPErrorHandler
+	e.Use(recorder.Middleware())
+	e.Use(middleware.OapiRequestValidator(swagger))
+	codegenTest.RegisterHandlers(e, &app.EchoServer{})
+
+	for _, target := range []string{"/pets/abc", "/pets/1", "/owners"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	hits := recorder.Hits()
+	require.Len(t, hits, 2)
+	assert.Equal(t, "FindPetById", hits[0].OperationID)
+	assert.Equal(t, http.StatusBadRequest, hits[0].Status)
+	assert.Equal(t, []string{"path:id"}, hits[0].Params)
+	assert.Equal(t, http.StatusNotFound, hits[1].Status)
+	assert.Equal(t, "application/json", hits[1].ContentType)
+	assert.Equal(t, []string{"GET /owners"}, recorder.Unmatched())
+	assert.NotContains(t, recorder.Report().Uncovered(), "FindPetById response default application/json")
+}
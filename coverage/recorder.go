@@ -0,0 +1,167 @@
+// Package coverage 统计测试实际覆盖了文档中的哪些 operation、状态码、
+// 响应媒体类型和参数，并生成覆盖率报告。
+//
+// 记录来源可以是测试用的内存 Doer（Recorder.Doer），也可以是 Echo 中间件
+// （Recorder.Middleware），两者都复用 gorillamux 路由把请求映射到 operation。
+package coverage
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/internal/httpdoer"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// Hit 一次请求对应的 (operationId, status, content type, 参数) 元组
+type Hit struct {
+	OperationID string
+	Method      string
+	Path        string
+	Status      int
+	ContentType string
+	// Params 请求中实际出现的参数，形如 "query:limit"、"path:id"、"body:application/json"
+	Params []string
+}
+
+// Recorder 并发安全，可以在整个测试包中共用一个
+type Recorder struct {
+	swagger *openapi3.T
+	router  routers.Router
+
+	mu        sync.Mutex
+	hits      []Hit
+	unmatched []string
+}
+
+// NewRecorder swagger 的 paths 需要与实际请求路径一致（例如 GetSwaggerWithPrefix 的结果）
+func NewRecorder(swagger *openapi3.T) (*Recorder, error) {
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{swagger: swagger, router: router}, nil
+}
+
+// Record 记录一次请求；找不到 operation 的请求单独记为 unmatched
+func (r *Recorder) Record(req *http.Request, status int, contentType string) {
+	route, pathParams, err := r.router.FindRoute(req)
+	if err != nil {
+		r.mu.Lock()
+		r.unmatched = append(r.unmatched, req.Method+" "+req.URL.Path)
+		r.mu.Unlock()
+		return
+	}
+	hit := Hit{
+		OperationID: route.Operation.OperationID,
+		Method:      route.Method,
+		Path:        route.Path,
+		Status:      status,
+		ContentType: mediaType(contentType),
+		Params:      usedParams(req, route, pathParams),
+	}
+	r.mu.Lock()
+	r.hits = append(r.hits, hit)
+	r.mu.Unlock()
+}
+
+// Hits 返回已记录的请求
+func (r *Recorder) Hits() []Hit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Hit(nil), r.hits...)
+}
+
+// Unmatched 返回文档中找不到对应 operation 的请求
+func (r *Recorder) Unmatched() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.unmatched...)
+}
+
+// Doer 包装客户端传输，记录每个请求及其响应
+func (r *Recorder) Doer(next codegenTest.HttpRequestDoer) codegenTest.HttpRequestDoer {
+	return httpdoer.Func(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.Do(req)
+		if err == nil {
+			r.Record(req, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		return resp, err
+	})
+}
+
+// Middleware 在服务端记录请求，需要放在校验中间件之前才能统计到 4xx
+func (r *Recorder) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if err != nil {
+				// 先交给错误处理函数写出响应，才能拿到最终状态码
+				c.Error(err)
+			}
+			r.Record(c.Request(), c.Response().Status, c.Response().Header().Get(echo.HeaderContentType))
+			return nil
+		}
+	}
+}
+
+func usedParams(req *http.Request, route *routers.Route, pathParams map[string]string) []string {
+	var used []string
+	query := req.URL.Query()
+	for _, ref := range operationParams(route.PathItem, route.Operation) {
+		p := ref.Value
+		present := false
+		switch p.In {
+		case openapi3.ParameterInPath:
+			_, present = pathParams[p.Name]
+		case openapi3.ParameterInQuery:
+			_, present = query[p.Name]
+		case openapi3.ParameterInHeader:
+			present = req.Header.Get(p.Name) != ""
+		case openapi3.ParameterInCookie:
+			_, err := req.Cookie(p.Name)
+			present = err == nil
+		}
+		if present {
+			used = append(used, p.In+":"+p.Name)
+		}
+	}
+	if route.Operation.RequestBody != nil && req.ContentLength != 0 && req.Body != nil && req.Body != http.NoBody {
+		used = append(used, "body:"+mediaType(req.Header.Get("Content-Type")))
+	}
+	sort.Strings(used)
+	return used
+}
+
+// operationParams 合并 path 级与 operation 级参数，operation 级优先
+func operationParams(pathItem *openapi3.PathItem, operation *openapi3.Operation) openapi3.Parameters {
+	var params openapi3.Parameters
+	for _, ref := range pathItem.Parameters {
+		if ref.Value != nil && operation.Parameters.GetByInAndName(ref.Value.In, ref.Value.Name) == nil {
+			params = append(params, ref)
+		}
+	}
+	for _, ref := range operation.Parameters {
+		if ref.Value != nil {
+			params = append(params, ref)
+		}
+	}
+	return params
+}
+
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
@@ -0,0 +1,227 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Item 报告中的一个可覆盖项
+type Item struct {
+	// Kind 为 "response" 或 "param"
+	Kind string
+	// Name 对于 response 是 "200 application/json"、"204"、"default application/json"，
+	// 对于 param 是 "query:limit"、"body:application/json"
+	Name string
+	Hits int
+}
+
+func (i Item) Covered() bool { return i.Hits > 0 }
+
+// OperationReport 单个 operation 的覆盖情况
+type OperationReport struct {
+	OperationID string
+	Method      string
+	Path        string
+	Items       []Item
+}
+
+// Report 覆盖率报告，operation 按 path、method 排序
+type Report struct {
+	Operations []OperationReport
+	// Unmatched 文档中不存在的请求
+	Unmatched []string
+}
+
+// Report 对照文档生成报告
+func (r *Recorder) Report() *Report {
+	type opKey struct{ method, path string }
+	hitsByOp := make(map[opKey][]Hit)
+	for _, hit := range r.Hits() {
+		k := opKey{hit.Method, hit.Path}
+		hitsByOp[k] = append(hitsByOp[k], hit)
+	}
+
+	report := &Report{Unmatched: r.Unmatched()}
+	paths := make([]string, 0, len(r.swagger.Paths))
+	for path := range r.swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := r.swagger.Paths[path]
+		operations := pathItem.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			operation := operations[method]
+			report.Operations = append(report.Operations,
+				operationReport(path, method, pathItem, operation, hitsByOp[opKey{method, path}]))
+		}
+	}
+	return report
+}
+
+func operationReport(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation, hits []Hit) OperationReport {
+	op := OperationReport{OperationID: operation.OperationID, Method: method, Path: path}
+
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		ref := operation.Responses[code]
+		var mediaTypes []string
+		if ref.Value != nil {
+			for mt := range ref.Value.Content {
+				mediaTypes = append(mediaTypes, mt)
+			}
+		}
+		sort.Strings(mediaTypes)
+		if len(mediaTypes) == 0 {
+			op.Items = append(op.Items, Item{Kind: "response", Name: code, Hits: countResponses(operation.Responses, hits, code, "")})
+			continue
+		}
+		for _, mt := range mediaTypes {
+			op.Items = append(op.Items, Item{Kind: "response", Name: code + " " + mt, Hits: countResponses(operation.Responses, hits, code, mt)})
+		}
+	}
+
+	var paramNames []string
+	for _, ref := range operationParams(pathItem, operation) {
+		paramNames = append(paramNames, ref.Value.In+":"+ref.Value.Name)
+	}
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		for mt := range operation.RequestBody.Value.Content {
+			paramNames = append(paramNames, "body:"+mt)
+		}
+	}
+	sort.Strings(paramNames)
+	for _, name := range paramNames {
+		n := 0
+		for _, hit := range hits {
+			for _, used := range hit.Params {
+				if used == name {
+					n++
+				}
+			}
+		}
+		op.Items = append(op.Items, Item{Kind: "param", Name: name, Hits: n})
+	}
+	return op
+}
+
+// countResponses 统计命中某个响应声明的次数；没有单独声明的状态码算到 nXX 或 default 上
+func countResponses(responses openapi3.Responses, hits []Hit, code, mediaType string) int {
+	n := 0
+	for _, hit := range hits {
+		if responseKey(responses, hit.Status) != code {
+			continue
+		}
+		if mediaType == "" || hit.ContentType == mediaType {
+			n++
+		}
+	}
+	return n
+}
+
+func responseKey(responses openapi3.Responses, status int) string {
+	if code := strconv.Itoa(status); responses[code] != nil {
+		return code
+	}
+	if rng := fmt.Sprintf("%dXX", status/100); responses[rng] != nil {
+		return rng
+	}
+	return "default"
+}
+
+// Covered 已覆盖项数
+func (r *Report) Covered() int {
+	n := 0
+	for _, op := range r.Operations {
+		for _, item := range op.Items {
+			if item.Covered() {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Total 可覆盖项总数
+func (r *Report) Total() int {
+	n := 0
+	for _, op := range r.Operations {
+		n += len(op.Items)
+	}
+	return n
+}
+
+// Percent 覆盖率，0~100
+func (r *Report) Percent() float64 {
+	total := r.Total()
+	if total == 0 {
+		return 100
+	}
+	return float64(r.Covered()) * 100 / float64(total)
+}
+
+// Uncovered 列出未覆盖的项，形如 "DeletePet response 204"
+func (r *Report) Uncovered() []string {
+	var result []string
+	for _, op := range r.Operations {
+		for _, item := range op.Items {
+			if !item.Covered() {
+				result = append(result, fmt.Sprintf("%s %s %s", operationName(op), item.Kind, item.Name))
+			}
+		}
+	}
+	return result
+}
+
+// WriteText 输出可读报告
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API coverage: %d/%d (%.1f%%)\n", r.Covered(), r.Total(), r.Percent())
+	for _, op := range r.Operations {
+		fmt.Fprintf(&b, "%s %s %s\n", operationName(op), op.Method, op.Path)
+		for _, item := range op.Items {
+			mark := " "
+			if item.Covered() {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "  [%s] %-8s %s (%d)\n", mark, item.Kind, item.Name, item.Hits)
+		}
+	}
+	for _, u := range r.Unmatched {
+		fmt.Fprintf(&b, "unmatched request: %s\n", u)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Check 覆盖率低于 threshold（0~100）时让测试失败，并输出未覆盖的项
+func (r *Report) Check(t testing.TB, threshold float64) {
+	t.Helper()
+	if r.Percent() >= threshold {
+		return
+	}
+	t.Errorf("API coverage %.1f%% is below %.1f%%; uncovered:\n  %s",
+		r.Percent(), threshold, strings.Join(r.Uncovered(), "\n  "))
+}
+
+func operationName(op OperationReport) string {
+	if op.OperationID != "" {
+		return op.OperationID
+	}
+	return op.Method + " " + op.Path
+}
@@ -0,0 +1,331 @@
+// Package example 根据 schema 生成示例值，供文档、mock、测试和造数使用。
+//
+// 生成顺序：文档中合法的 example/default，其次 enum，最后按类型、格式（date、email、
+// uuid 使用 pkg/types）、minimum/maximum、长度和 pattern 合成。allOf 会被合并，
+// oneOf/anyOf 会挑选一个分支并设置 discriminator。结果都经过 schema.VisitJSON 校验。
+//
+// 同一个 Seed 创建的 Generator 依次生成的值完全相同。
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Direction 决定跳过 readOnly 还是 writeOnly 属性
+type Direction int
+
+const (
+	Any Direction = iota
+	// Request 生成请求体，跳过 readOnly 属性
+	Request
+	// Response 生成响应体，跳过 writeOnly 属性
+	Response
+)
+
+// DefaultMaxDepth 自引用 schema 最多展开的层数
+const DefaultMaxDepth = 8
+
+// maxAttempts 生成的值没有通过校验时重试的次数
+const maxAttempts = 8
+
+// Options 控制生成行为，零值可用
+type Options struct {
+	Seed int64
+	// IgnoreExamples 为 true 时不使用文档中的 example/default，总是合成
+	IgnoreExamples bool
+	// RequiredOnly 只生成必填属性
+	RequiredOnly bool
+	Direction    Direction
+	// MaxDepth 为 0 时使用 DefaultMaxDepth
+	MaxDepth int
+}
+
+// Generator 不是并发安全的，每个 goroutine 使用自己的 Generator
+type Generator struct {
+	options Options
+	rnd     *rand.Rand
+}
+
+// New 创建 Generator
+func New(options Options) *Generator {
+	if options.MaxDepth == 0 {
+		options.MaxDepth = DefaultMaxDepth
+	}
+	return &Generator{options: options, rnd: rand.New(rand.NewSource(options.Seed))}
+}
+
+// Generate 用 seed 创建 Generator 并生成一个值
+func Generate(schema *openapi3.Schema, seed int64) (interface{}, error) {
+	return New(Options{Seed: seed}).Generate(schema)
+}
+
+// Generate 生成一个能通过 schema.VisitJSON 的值，schema 无法满足时返回错误
+func (g *Generator) Generate(schema *openapi3.Schema) (interface{}, error) {
+	if schema == nil {
+		return nil, errors.New("example: nil schema")
+	}
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		v := g.value(schema, "", 0)
+		if err = g.validate(schema, v); err == nil {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("example: no valid value after %d attempts: %w", maxAttempts, err)
+}
+
+// GenerateJSON 与 Generate 相同，返回编码后的 JSON
+func (g *Generator) GenerateJSON(schema *openapi3.Schema) (json.RawMessage, error) {
+	v, err := g.Generate(schema)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func (g *Generator) validate(schema *openapi3.Schema, v interface{}) error {
+	var opts []openapi3.SchemaValidationOption
+	switch g.options.Direction {
+	case Request:
+		opts = append(opts, openapi3.VisitAsRequest())
+	case Response:
+		opts = append(opts, openapi3.VisitAsResponse())
+	}
+	return schema.VisitJSON(v, opts...)
+}
+
+func (g *Generator) valid(schema *openapi3.Schema, v interface{}) bool {
+	return g.validate(schema, v) == nil
+}
+
+// value 生成 schema 的值，name 是所在属性的名称，用于挑选更贴近实际的字符串和数字
+func (g *Generator) value(schema *openapi3.Schema, name string, depth int) interface{} {
+	if schema == nil || depth > g.options.MaxDepth {
+		return nil
+	}
+	if !g.options.IgnoreExamples {
+		if schema.Example != nil && g.valid(schema, schema.Example) {
+			return schema.Example
+		}
+		if schema.Default != nil && g.valid(schema, schema.Default) {
+			return schema.Default
+		}
+	}
+	if len(schema.AllOf) > 0 {
+		return g.value(flatten(schema), name, depth)
+	}
+	if len(schema.Enum) > 0 {
+		return g.enum(schema)
+	}
+	if len(schema.OneOf) > 0 {
+		return g.oneOf(schema, schema.OneOf, name, depth, true)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.oneOf(schema, schema.AnyOf, name, depth, false)
+	}
+
+	switch schema.Type {
+	case openapi3.TypeObject:
+		return g.object(schema, depth)
+	case openapi3.TypeArray:
+		return g.array(schema, name, depth)
+	case openapi3.TypeInteger:
+		return g.integer(schema, name)
+	case openapi3.TypeNumber:
+		return g.number(schema)
+	case openapi3.TypeString:
+		return g.str(schema, name)
+	case openapi3.TypeBoolean:
+		return g.rnd.Intn(2) == 0
+	}
+	if len(schema.Properties) > 0 || schema.AdditionalProperties.Schema != nil {
+		return g.object(schema, depth)
+	}
+	if schema.Items != nil {
+		return g.array(schema, name, depth)
+	}
+	if schema.Nullable {
+		return nil
+	}
+	// 没有任何约束的 schema 接受任意值
+	return g.word(name)
+}
+
+func (g *Generator) enum(schema *openapi3.Schema) interface{} {
+	var candidates []interface{}
+	for _, v := range schema.Enum {
+		if v != nil || schema.Nullable {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[g.rnd.Intn(len(candidates))]
+}
+
+// oneOf 随机挑选分支；exclusive 为 true 时要求值只匹配一个分支
+func (g *Generator) oneOf(schema *openapi3.Schema, branches openapi3.SchemaRefs, name string, depth int, exclusive bool) interface{} {
+	var last interface{}
+	for _, i := range g.rnd.Perm(len(branches)) {
+		branch := branches[i]
+		if branch == nil || branch.Value == nil {
+			continue
+		}
+		v := g.value(branch.Value, name, depth+1)
+		if obj, ok := v.(map[string]interface{}); ok && schema.Discriminator != nil {
+			obj[schema.Discriminator.PropertyName] = discriminatorValue(schema.Discriminator, branch)
+		}
+		last = v
+		if !g.valid(branch.Value, v) {
+			continue
+		}
+		if !exclusive {
+			return v
+		}
+		matched := 0
+		for _, other := range branches {
+			if other != nil && other.Value != nil && g.valid(other.Value, v) {
+				matched++
+			}
+		}
+		if matched == 1 {
+			return v
+		}
+	}
+	return last
+}
+
+// discriminatorValue 优先使用 mapping 中指向该分支的名称，否则使用组件名
+func discriminatorValue(d *openapi3.Discriminator, branch *openapi3.SchemaRef) string {
+	keys := make([]string, 0, len(d.Mapping))
+	for k := range d.Mapping {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if d.Mapping[k] == branch.Ref {
+			return k
+		}
+	}
+	return branch.Ref[strings.LastIndexByte(branch.Ref, '/')+1:]
+}
+
+func (g *Generator) object(schema *openapi3.Schema, depth int) map[string]interface{} {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	obj := make(map[string]interface{}, len(names))
+	var optional []string
+	for _, name := range names {
+		prop := schema.Properties[name]
+		if prop == nil || prop.Value == nil || g.skip(prop.Value) {
+			continue
+		}
+		required := contains(schema.Required, name)
+		if !required && g.options.RequiredOnly {
+			continue
+		}
+		if !required && depth >= g.options.MaxDepth {
+			continue
+		}
+		v := g.value(prop.Value, name, depth+1)
+		if v == nil && !prop.Value.Nullable && !required {
+			continue
+		}
+		obj[name] = v
+		if !required {
+			optional = append(optional, name)
+		}
+	}
+	if schema.MaxProps != nil {
+		for len(obj) > int(*schema.MaxProps) && len(optional) > 0 {
+			delete(obj, optional[len(optional)-1])
+			optional = optional[:len(optional)-1]
+		}
+	}
+	if extra := schema.AdditionalProperties.Schema; extra != nil && extra.Value != nil {
+		n := int(schema.MinProps) - len(obj)
+		if n < 1 && len(schema.Properties) == 0 {
+			n = 1
+		}
+		if schema.MaxProps != nil && len(obj)+n > int(*schema.MaxProps) {
+			n = int(*schema.MaxProps) - len(obj)
+		}
+		for i := 1; i <= n; i++ {
+			obj[fmt.Sprintf("key%d", i)] = g.value(extra.Value, "", depth+1)
+		}
+	}
+	return obj
+}
+
+func (g *Generator) skip(schema *openapi3.Schema) bool {
+	switch g.options.Direction {
+	case Request:
+		return schema.ReadOnly
+	case Response:
+		return schema.WriteOnly
+	}
+	return false
+}
+
+func (g *Generator) array(schema *openapi3.Schema, name string, depth int) []interface{} {
+	lo := int(schema.MinItems)
+	if lo == 0 {
+		lo = 1
+	}
+	hi := lo + 2
+	if schema.MaxItems != nil && int(*schema.MaxItems) < hi {
+		hi = int(*schema.MaxItems)
+	}
+	if lo > hi {
+		lo = hi
+	}
+	n := lo + g.rnd.Intn(hi-lo+1)
+	if depth >= g.options.MaxDepth {
+		n = int(schema.MinItems)
+	}
+	item := &openapi3.Schema{}
+	if schema.Items != nil && schema.Items.Value != nil {
+		item = schema.Items.Value
+	}
+	items := make([]interface{}, 0, n)
+	seen := make(map[string]bool, n)
+	for len(items) < n {
+		var v interface{}
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			v = g.value(item, singular(name), depth+1)
+			key, _ := json.Marshal(v)
+			if !schema.UniqueItems || !seen[string(key)] {
+				seen[string(key)] = true
+				break
+			}
+		}
+		items = append(items, v)
+	}
+	return items
+}
+
+// singular 数组元素沿用属性名的单数形式，tags -> tag
+func singular(name string) string {
+	return strings.TrimSuffix(name, "s")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
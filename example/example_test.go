@@ -0,0 +1,279 @@
+package example_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/example"
+	"github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `
+openapi: "3.0.0"
+info: {title: examples, version: "1"}
+paths: {}
+components:
+  schemas:
+    Formats:
+      type: object
+      required: [day, at, email, id, host, addr, blob]
+      properties:
+        day: {type: string, format: date}
+        at: {type: string, format: date-time}
+        email: {type: string, format: email}
+        id: {type: string, format: uuid}
+        host: {type: string, format: hostname}
+        addr: {type: string, format: ipv4}
+        blob: {type: string, format: byte}
+    Bounded:
+      type: object
+      required: [small, ratio, even, size, short, padded, list, kind, sku]
+      properties:
+        small: {type: integer, minimum: 3, maximum: 5}
+        ratio: {type: number, minimum: 0, maximum: 1, exclusiveMinimum: true, exclusiveMaximum: true}
+        even: {type: integer, minimum: 1, maximum: 9, multipleOf: 2}
+        size: {type: integer, maximum: -10}
+        short: {type: string, maxLength: 2}
+        padded: {type: string, minLength: 20}
+        list: {type: array, minItems: 2, maxItems: 2, uniqueItems: true, items: {type: integer, minimum: 1, maximum: 3}}
+        kind: {type: string, enum: [small, large]}
+        sku: {type: string, pattern: '^[A-Z]{3}-\d{4}(-[a-z]+)?$', maxLength: 12}
+    Cat:
+      type: object
+      required: [petType, meows]
+      properties:
+        petType: {type: string}
+        meows: {type: boolean}
+    Dog:
+      type: object
+      required: [petType, barks]
+      properties:
+        petType: {type: string}
+        barks: {type: integer, minimum: 1}
+      additionalProperties: false
+    Animal:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: petType
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: '#/components/schemas/Dog'
+    Unmapped:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: petType
+    Either:
+      anyOf:
+        - {type: string, pattern: '^x+$'}
+        - {type: integer, maximum: 0}
+    Narrowed:
+      allOf:
+        - type: object
+          required: [n]
+          properties:
+            n: {type: integer, minimum: 10}
+        - type: object
+          properties:
+            n: {type: integer, maximum: 12}
+            label: {type: string}
+    Account:
+      type: object
+      required: [id, password]
+      properties:
+        id: {type: integer, readOnly: true}
+        password: {type: string, writeOnly: true}
+    Documented:
+      type: object
+      properties:
+        good: {type: integer, example: 42}
+        bad: {type: integer, maximum: 5, example: 99}
+    Impossible:
+      type: integer
+      minimum: 5
+      maximum: 1
+`
+
+func schemas(t *testing.T) openapi3.Schemas {
+	t.Helper()
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	return swagger.Components.Schemas
+}
+
+func generate(t *testing.T, schema *openapi3.Schema, options example.Options) map[string]interface{} {
+	t.Helper()
+	v, err := example.New(options).Generate(schema)
+	require.NoError(t, err)
+	require.NoError(t, visit(schema, v, options.Direction))
+	obj, ok := v.(map[string]interface{})
+	require.True(t, ok, "%#v", v)
+	return obj
+}
+
+func TestPetstoreSchemas(t *testing.T) {
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+
+	pet := generate(t, swagger.Components.Schemas["Pet"].Value, example.Options{Seed: 1})
+	assert.IsType(t, int64(0), pet["id"])
+	assert.Contains(t, []interface{}{"Baby", "Kitty", "Rex", "Luna", "Milo", "Bella"}, pet["name"])
+	assert.Contains(t, []interface{}{"cat", "dog", "bird", "fish"}, pet["tag"])
+
+	newPet := generate(t, swagger.Components.Schemas["NewPet"].Value, example.Options{Seed: 1, RequiredOnly: true})
+	assert.Equal(t, []string{"name"}, keys(newPet))
+
+	e := generate(t, swagger.Components.Schemas["Error"].Value, example.Options{Seed: 1})
+	assert.Contains(t, []interface{}{int64(400), int64(404), int64(409), int64(422), int64(500)}, e["code"])
+
+	// 生成的 JSON 可以直接解码为生成的类型
+	data, err := example.New(example.Options{Seed: 2}).GenerateJSON(swagger.Components.Schemas["Pet"].Value)
+	require.NoError(t, err)
+	var decoded codegenTest.Pet
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.NotEmpty(t, decoded.Name)
+}
+
+func TestDeterministic(t *testing.T) {
+	for name, ref := range schemas(t) {
+		if name == "Impossible" {
+			continue
+		}
+		a, err := example.New(example.Options{Seed: 7}).GenerateJSON(ref.Value)
+		require.NoError(t, err, name)
+		b, err := example.New(example.Options{Seed: 7}).GenerateJSON(ref.Value)
+		require.NoError(t, err, name)
+		assert.JSONEq(t, string(a), string(b), name)
+	}
+
+	bounded := schemas(t)["Bounded"].Value
+	seen := make(map[string]bool)
+	for seed := int64(0); seed < 10; seed++ {
+		data, err := example.New(example.Options{Seed: seed}).GenerateJSON(bounded)
+		require.NoError(t, err)
+		seen[string(data)] = true
+	}
+	assert.Greater(t, len(seen), 1, "different seeds should produce different values")
+}
+
+func TestEverySeedValidates(t *testing.T) {
+	for name, ref := range schemas(t) {
+		if name == "Impossible" {
+			continue
+		}
+		for _, direction := range []example.Direction{example.Any, example.Request, example.Response} {
+			g := example.New(example.Options{Seed: 42, Direction: direction, IgnoreExamples: true})
+			for i := 0; i < 50; i++ {
+				v, err := g.Generate(ref.Value)
+				require.NoError(t, err, "%s #%d", name, i)
+				require.NoError(t, visit(ref.Value, v, direction), "%s #%d: %#v", name, i, v)
+			}
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	obj := generate(t, schemas(t)["Formats"].Value, example.Options{Seed: 3})
+
+	var day types.Date
+	require.NoError(t, day.UnmarshalText([]byte(obj["day"].(string))))
+	_, err := time.Parse(time.RFC3339, obj["at"].(string))
+	assert.NoError(t, err)
+	_, err = json.Marshal(types.Email(obj["email"].(string)))
+	assert.NoError(t, err)
+	var id types.UUID
+	require.NoError(t, id.UnmarshalText([]byte(obj["id"].(string))))
+	assert.Equal(t, 4, int(id.Version()))
+	assert.Regexp(t, `^\w+\.example\.com$`, obj["host"])
+	assert.Regexp(t, `^192\.0\.2\.\d+$`, obj["addr"])
+}
+
+func TestConstraints(t *testing.T) {
+	g := example.New(example.Options{Seed: 5})
+	for i := 0; i < 20; i++ {
+		v, err := g.Generate(schemas(t)["Bounded"].Value)
+		require.NoError(t, err)
+		obj := v.(map[string]interface{})
+		assert.GreaterOrEqual(t, obj["small"], int64(3))
+		assert.LessOrEqual(t, obj["small"], int64(5))
+		assert.Zero(t, obj["even"].(int64)%2)
+		assert.LessOrEqual(t, obj["size"], int64(-10))
+		assert.LessOrEqual(t, len(obj["short"].(string)), 2)
+		assert.GreaterOrEqual(t, len(obj["padded"].(string)), 20)
+		assert.Len(t, obj["list"], 2)
+		assert.NotEqual(t, obj["list"].([]interface{})[0], obj["list"].([]interface{})[1])
+		assert.Regexp(t, regexp.MustCompile(`^[A-Z]{3}-\d{4}(-[a-z]+)?$`), obj["sku"])
+	}
+}
+
+func TestComposition(t *testing.T) {
+	s := schemas(t)
+	g := example.New(example.Options{Seed: 9})
+	petTypes := func(name string) map[interface{}]bool {
+		kinds := make(map[interface{}]bool)
+		for i := 0; i < 20; i++ {
+			v, err := g.Generate(s[name].Value)
+			require.NoError(t, err)
+			kinds[v.(map[string]interface{})["petType"]] = true
+		}
+		return kinds
+	}
+	// mapping 中的名称优先，没有 mapping 时使用组件名
+	assert.Equal(t, map[interface{}]bool{"cat": true, "dog": true}, petTypes("Animal"))
+	assert.Equal(t, map[interface{}]bool{"Cat": true, "Dog": true}, petTypes("Unmapped"))
+
+	for i := 0; i < 20; i++ {
+		v, err := g.Generate(s["Either"].Value)
+		require.NoError(t, err)
+		require.NoError(t, s["Either"].Value.VisitJSON(v))
+	}
+
+	narrowed := generate(t, s["Narrowed"].Value, example.Options{Seed: 9})
+	assert.GreaterOrEqual(t, narrowed["n"], int64(10))
+	assert.LessOrEqual(t, narrowed["n"], int64(12))
+	assert.Contains(t, narrowed, "label")
+}
+
+func TestDirectionAndExamples(t *testing.T) {
+	s := schemas(t)
+	request := generate(t, s["Account"].Value, example.Options{Direction: example.Request})
+	assert.Equal(t, []string{"password"}, keys(request))
+	response := generate(t, s["Account"].Value, example.Options{Direction: example.Response})
+	assert.Equal(t, []string{"id"}, keys(response))
+
+	// 合法的 example 原样使用，不合法的被忽略
+	documented := generate(t, s["Documented"].Value, example.Options{})
+	assert.Equal(t, float64(42), documented["good"])
+	assert.LessOrEqual(t, documented["bad"], int64(5))
+
+	_, err := example.Generate(s["Impossible"].Value, 1)
+	assert.ErrorContains(t, err, "no valid value")
+}
+
+// visit 按方向校验，readOnly/writeOnly 的必填属性只在对应方向上豁免
+func visit(schema *openapi3.Schema, v interface{}, direction example.Direction) error {
+	switch direction {
+	case example.Request:
+		return schema.VisitJSON(v, openapi3.VisitAsRequest())
+	case example.Response:
+		return schema.VisitJSON(v, openapi3.VisitAsResponse())
+	}
+	return schema.VisitJSON(v)
+}
+
+func keys(obj map[string]interface{}) []string {
+	var result []string
+	for k := range obj {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,313 @@
+package example
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"demo/oapi-codegen-go/internal/allof"
+	"github.com/deepmap/oapi-codegen/pkg/types"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// hints 按属性名挑选更像真实数据的字符串，不满足约束时回退到普通单词
+var hints = map[string][]string{
+	"name":        {"Baby", "Kitty", "Rex", "Luna", "Milo", "Bella"},
+	"tag":         {"cat", "dog", "bird", "fish"},
+	"message":     {"pet not found", "invalid request", "internal error"},
+	"description": {"a friendly pet", "loves long walks", "sleeps all day"},
+	"status":      {"available", "pending", "sold"},
+}
+
+var words = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel"}
+
+// codes Error.code 这类字段使用常见的 HTTP 状态码
+var codes = []int64{400, 404, 409, 422, 500}
+
+func (g *Generator) integer(schema *openapi3.Schema, name string) int64 {
+	lo, hi := int64(1), int64(100)
+	switch {
+	case schema.Min != nil && schema.Max != nil:
+		lo, hi = int64(math.Ceil(*schema.Min)), int64(math.Floor(*schema.Max))
+	case schema.Min != nil:
+		lo = int64(math.Ceil(*schema.Min))
+		hi = lo + 100
+	case schema.Max != nil:
+		hi = int64(math.Floor(*schema.Max))
+		lo = hi - 100
+		if hi > 0 && lo < 1 {
+			lo = 1
+		}
+	}
+	if schema.ExclusiveMin && schema.Min != nil && float64(lo) == *schema.Min {
+		lo++
+	}
+	if schema.ExclusiveMax && schema.Max != nil && float64(hi) == *schema.Max {
+		hi--
+	}
+	if name == "code" && schema.MultipleOf == nil {
+		code := codes[g.rnd.Intn(len(codes))]
+		if schema.Min == nil && schema.Max == nil || lo <= code && code <= hi {
+			return code
+		}
+	}
+	if lo > hi {
+		return lo
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf >= 1 {
+		m := int64(*schema.MultipleOf)
+		first, last := ceilDiv(lo, m), floorDiv(hi, m)
+		if first > last {
+			return first * m
+		}
+		return (first + g.rnd.Int63n(last-first+1)) * m
+	}
+	return lo + g.rnd.Int63n(hi-lo+1)
+}
+
+func ceilDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a > 0 {
+		q++
+	}
+	return q
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+func (g *Generator) number(schema *openapi3.Schema) float64 {
+	lo, hi := 0.0, 100.0
+	switch {
+	case schema.Min != nil && schema.Max != nil:
+		lo, hi = *schema.Min, *schema.Max
+	case schema.Min != nil:
+		lo = *schema.Min
+		hi = lo + 100
+	case schema.Max != nil:
+		hi = *schema.Max
+		lo = hi - 100
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		m := *schema.MultipleOf
+		first, last := math.Ceil(lo/m), math.Floor(hi/m)
+		if schema.ExclusiveMin && first*m == lo {
+			first++
+		}
+		if schema.ExclusiveMax && last*m == hi {
+			last--
+		}
+		if first > last {
+			return first * m
+		}
+		return (first + float64(g.rnd.Int63n(int64(last-first)+1))) * m
+	}
+	// 保留两位小数，端点按 exclusive 排除
+	v := math.Round((lo+g.rnd.Float64()*(hi-lo))*100) / 100
+	if v < lo || schema.ExclusiveMin && v == lo {
+		v = lo + (hi-lo)/2
+	}
+	if v > hi || schema.ExclusiveMax && v == hi {
+		v = lo + (hi-lo)/2
+	}
+	return v
+}
+
+func (g *Generator) str(schema *openapi3.Schema, name string) string {
+	if schema.Pattern != "" {
+		if s, ok := g.pattern(schema); ok {
+			return s
+		}
+	}
+	if s, ok := g.format(schema.Format); ok {
+		return s
+	}
+	if pool, ok := hints[strings.ToLower(name)]; ok {
+		if s := pool[g.rnd.Intn(len(pool))]; fitsLength(schema, s) {
+			return s
+		}
+	}
+	return fitLength(schema, g.word(name), g.letter)
+}
+
+func (g *Generator) word(name string) string {
+	if pool, ok := hints[strings.ToLower(name)]; ok {
+		return pool[g.rnd.Intn(len(pool))]
+	}
+	return words[g.rnd.Intn(len(words))]
+}
+
+func (g *Generator) letter() string {
+	return string(rune('a' + g.rnd.Intn(26)))
+}
+
+func fitsLength(schema *openapi3.Schema, s string) bool {
+	n := uint64(utf8.RuneCountInString(s))
+	return n >= schema.MinLength && (schema.MaxLength == nil || n <= *schema.MaxLength)
+}
+
+// fitLength 不足 minLength 时补字符，超过 maxLength 时截断
+func fitLength(schema *openapi3.Schema, s string, pad func() string) string {
+	for uint64(utf8.RuneCountInString(s)) < schema.MinLength {
+		s += pad()
+	}
+	if schema.MaxLength != nil && uint64(utf8.RuneCountInString(s)) > *schema.MaxLength {
+		s = string([]rune(s)[:*schema.MaxLength])
+	}
+	return s
+}
+
+// epoch 生成的日期都落在 2020 年之后的五年内
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (g *Generator) time() time.Time {
+	return epoch.Add(time.Duration(g.rnd.Int63n(5*365*24*3600)) * time.Second)
+}
+
+func (g *Generator) format(format string) (string, bool) {
+	switch format {
+	case "date":
+		return types.Date{Time: g.time()}.String(), true
+	case "date-time":
+		return g.time().Format(time.RFC3339), true
+	case "time":
+		return g.time().Format("15:04:05"), true
+	case "email":
+		email := types.Email(fmt.Sprintf("%s.%s@example.com", g.word(""), g.word("")))
+		if _, err := email.MarshalJSON(); err != nil {
+			email = "user@example.com"
+		}
+		return string(email), true
+	case "uuid":
+		var id types.UUID
+		g.rnd.Read(id[:])
+		id[6] = id[6]&0x0f | 0x40 // version 4
+		id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+		return id.String(), true
+	case "uri", "url":
+		return "https://example.com/" + g.word(""), true
+	case "hostname":
+		return g.word("") + ".example.com", true
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+g.rnd.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+g.rnd.Intn(0xfffe)), true
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(g.word(""))), true
+	case "password":
+		var b strings.Builder
+		for i := 0; i < 12; i++ {
+			b.WriteString(g.letter())
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// pattern 按正则的语法树生成字符串，长度不满足时放宽重复次数再试
+func (g *Generator) pattern(schema *openapi3.Schema) (string, bool) {
+	re, err := regexp.Compile(schema.Pattern)
+	if err != nil {
+		return "", false
+	}
+	tree, err := syntax.Parse(schema.Pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	tree = tree.Simplify()
+	for attempt := 0; attempt < maxAttempts*2; attempt++ {
+		var b strings.Builder
+		if !g.regex(&b, tree, 2+attempt) {
+			return "", false
+		}
+		if s := b.String(); re.MatchString(s) && fitsLength(schema, s) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func (g *Generator) regex(b *strings.Builder, re *syntax.Regexp, extra int) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(g.class(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteString(g.letter())
+	case syntax.OpCapture:
+		return g.regex(b, re.Sub[0], extra)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !g.regex(b, sub, extra) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return g.regex(b, re.Sub[g.rnd.Intn(len(re.Sub))], extra)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 {
+			hi = lo + extra
+		}
+		for n := lo + g.rnd.Intn(hi-lo+1); n > 0; n-- {
+			if !g.regex(b, re.Sub[0], extra) {
+				return false
+			}
+		}
+	}
+	// 锚点、单词边界和空匹配不产生字符
+	return true
+}
+
+// class 在字符类中挑一个字符，优先可打印的 ASCII
+func (g *Generator) class(ranges []rune) rune {
+	var printable [][2]rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x21 {
+			lo = 0x21
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, [2]rune{lo, hi})
+		}
+	}
+	if len(printable) == 0 {
+		if len(ranges) == 0 {
+			return 'x'
+		}
+		return ranges[0]
+	}
+	r := printable[g.rnd.Intn(len(printable))]
+	return r[0] + rune(g.rnd.Intn(int(r[1]-r[0]+1)))
+}
+
+// flatten 按 allof.Flatten 合并 allOf，生成时不再使用文档中的 example 与 default
+func flatten(schema *openapi3.Schema) *openapi3.Schema {
+	merged := *allof.Flatten(schema)
+	merged.Example, merged.Default = nil, nil
+	return &merged
+}
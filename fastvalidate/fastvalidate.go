@@ -0,0 +1,332 @@
+// Package fastvalidate 提供与 middleware.OapiRequestValidatorWithOptions 行为一致、
+// 但把准备工作放到启动阶段的请求校验中间件。
+//
+// 原中间件每个请求都要用 gorillamux 的正则逐条匹配 path、复制一份 Route、合并
+// path 级与 operation 级参数，schema 的 pattern 也是第一次用到时才编译（并发请求会
+// 同时写入同一个 Schema）。New 把 path 模板拆成分段，为每个 operation 准备好 Route、
+// 参数列表与安全要求，并预热 kin-openapi 的 pattern 缓存；请求时只做分段比较和取值校验，没有参数、
+// 请求体和安全要求的 operation 直接放行，没有 requestBody 的 operation 不读取请求体。
+//
+// Options、错误类型与状态码都沿用 middleware 包，可以直接替换。
+package fastvalidate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// OapiRequestValidator 与 middleware.OapiRequestValidator 相同
+func OapiRequestValidator(swagger *openapi3.T) echo.MiddlewareFunc {
+	return OapiRequestValidatorWithOptions(swagger, nil)
+}
+
+// OapiRequestValidatorWithOptions 与 middleware.OapiRequestValidatorWithOptions 相同：
+// servers 不为空时打印同样的警告，文档无法构建路由时 panic。
+func OapiRequestValidatorWithOptions(swagger *openapi3.T, options *middleware.Options) echo.MiddlewareFunc {
+	if swagger.Servers != nil && (options == nil || !options.SilenceServersWarning) {
+		log.Println("WARN: OapiRequestValidatorWithOptions called with an OpenAPI spec that has `Servers` set. This may lead to an HTTP 400 with `no matching operation was found` when sending a valid request, as the validator performs `Host` header validation. If you're expecting `Host` header validation, you can silence this warning by setting `Options.SilenceServersWarning = true`. See https://github.com/deepmap/oapi-codegen/issues/882 for more information.")
+	}
+	v, err := New(swagger, options)
+	if err != nil {
+		panic(err)
+	}
+	return v.Middleware()
+}
+
+// Validator 预处理后的文档，构建后只读，可以并发使用
+type Validator struct {
+	options   *middleware.Options
+	templates []*template
+	// router servers 不为空或 path 模板无法按段匹配时退回 gorillamux，
+	// 此时只复用预处理好的 operation
+	router     routers.Router
+	operations map[string]*operation
+	// base 已经带上 UserDataKey，请求时只需再加 EchoContextKey
+	base context.Context
+}
+
+// operation 一个 method + path 需要的全部校验信息
+type operation struct {
+	route    *routers.Route
+	params   []*openapi3.Parameter
+	security openapi3.SecurityRequirements
+	body     *openapi3.RequestBody
+}
+
+// empty 没有任何需要校验的内容
+func (op *operation) empty() bool {
+	return len(op.params) == 0 && len(op.security) == 0 && op.body == nil
+}
+
+// New 预处理 swagger。swagger 需要已经通过 Validate，之后不能再修改。
+func New(swagger *openapi3.T, options *middleware.Options) (*Validator, error) {
+	v := &Validator{
+		options:    options,
+		operations: make(map[string]*operation),
+		base:       context.Background(),
+	}
+	if options != nil {
+		v.base = context.WithValue(v.base, middleware.UserDataKey, options.UserData) //nolint:staticcheck
+	}
+
+	fallback := len(swagger.Servers) > 0
+	for _, path := range swagger.Paths.InMatchingOrder() {
+		item := swagger.Paths[path]
+		if len(item.Servers) > 0 {
+			fallback = true
+		}
+		t := &template{methods: make(map[string]*operation)}
+		var ok bool
+		if t.segments, ok = parseTemplate(path); !ok {
+			fallback = true
+		}
+		for _, seg := range t.segments {
+			if seg.name != "" {
+				t.vars++
+			}
+		}
+		for method, op := range item.Operations() {
+			prepared := prepare(swagger, path, item, method, op)
+			t.methods[method] = prepared
+			v.operations[method+" "+path] = prepared
+		}
+		v.templates = append(v.templates, t)
+	}
+
+	if fallback {
+		router, err := gorillamux.NewRouter(swagger)
+		if err != nil {
+			return nil, err
+		}
+		v.router = router
+		v.templates = nil
+	}
+	return v, nil
+}
+
+// prepare 按 openapi3filter.ValidateRequest 的顺序准备参数与安全要求
+func prepare(swagger *openapi3.T, path string, item *openapi3.PathItem, method string, op *openapi3.Operation) *operation {
+	prepared := &operation{
+		route: &routers.Route{
+			Spec:      swagger,
+			Path:      path,
+			PathItem:  item,
+			Method:    method,
+			Operation: op,
+		},
+	}
+	for _, ref := range item.Parameters {
+		if op.Parameters != nil && op.Parameters.GetByInAndName(ref.Value.In, ref.Value.Name) != nil {
+			continue
+		}
+		prepared.params = append(prepared.params, ref.Value)
+	}
+	for _, ref := range op.Parameters {
+		prepared.params = append(prepared.params, ref.Value)
+	}
+	if op.Security != nil {
+		prepared.security = *op.Security
+	} else {
+		prepared.security = swagger.Security
+	}
+	if op.RequestBody != nil {
+		prepared.body = op.RequestBody.Value
+	}
+
+	c := make(compiler)
+	for _, param := range prepared.params {
+		if param.Schema != nil {
+			c.compile(param.Schema.Value)
+		}
+		c.compileContent(param.Content)
+	}
+	if prepared.body != nil {
+		c.compileContent(prepared.body.Content)
+	}
+	return prepared
+}
+
+// Middleware 与 OapiRequestValidatorWithOptions 返回的中间件相同，但不打印警告
+func (v *Validator) Middleware() echo.MiddlewareFunc {
+	skipper := echomiddleware.DefaultSkipper
+	if v.options != nil && v.options.Skipper != nil {
+		skipper = v.options.Skipper
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+			if err := v.Validate(c); err != nil {
+				if v.options != nil && v.options.ErrorHandler != nil {
+					return v.options.ErrorHandler(c, err)
+				}
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// Validate 对应 middleware.ValidateRequestFromContext，返回的错误完全相同
+func (v *Validator) Validate(c echo.Context) *echo.HTTPError {
+	req := c.Request()
+	op, route, pathParams, err := v.find(req)
+	if err != nil {
+		var routeErr *routers.RouteError
+		if errors.As(err, &routeErr) {
+			return echo.NewHTTPError(http.StatusNotFound, routeErr.Reason)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError,
+			fmt.Sprintf("error validating route: %s", err.Error()))
+	}
+	if op.empty() {
+		return nil
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+	}
+	if v.options != nil {
+		input.Options = &v.options.Options
+		input.ParamDecoder = v.options.ParamDecoder
+	}
+	ctx := context.WithValue(v.base, middleware.EchoContextKey, c) //nolint:staticcheck
+	if err := op.validate(ctx, input); err != nil {
+		return v.httpError(err)
+	}
+	return nil
+}
+
+// validate 与 openapi3filter.ValidateRequest 相同，只是参数列表已经合并好
+func (op *operation) validate(ctx context.Context, input *openapi3filter.RequestValidationInput) error {
+	options := input.Options
+	if options == nil {
+		options = &openapi3filter.Options{}
+	}
+	var me openapi3.MultiError
+	check := func(err error) bool {
+		if err == nil {
+			return true
+		}
+		me = append(me, err)
+		return options.MultiError
+	}
+
+	if len(op.security) > 0 {
+		if !check(openapi3filter.ValidateSecurityRequirements(ctx, input, op.security)) {
+			return me[0]
+		}
+	}
+	for _, param := range op.params {
+		if !check(openapi3filter.ValidateParameter(ctx, input, param)) {
+			return me[len(me)-1]
+		}
+	}
+	if op.body != nil && !options.ExcludeRequestBody {
+		if !check(openapi3filter.ValidateRequestBody(ctx, input, op.body)) {
+			return me[len(me)-1]
+		}
+	}
+	if len(me) > 0 {
+		return me
+	}
+	return nil
+}
+
+// httpError 与 middleware.ValidateRequestFromContext 的错误转换相同
+func (v *Validator) httpError(err error) *echo.HTTPError {
+	me := openapi3.MultiError{}
+	if errors.As(err, &me) {
+		if v.options != nil && v.options.MultiErrorHandler != nil {
+			return v.options.MultiErrorHandler(me)
+		}
+		return &echo.HTTPError{
+			Code:     http.StatusBadRequest,
+			Message:  me.Error(),
+			Internal: me,
+		}
+	}
+
+	switch e := err.(type) {
+	case *openapi3filter.RequestError:
+		// 与原中间件一样只取第一行
+		errorLines := strings.Split(e.Error(), "\n")
+		return &echo.HTTPError{
+			Code:     http.StatusBadRequest,
+			Message:  errorLines[0],
+			Internal: err,
+		}
+	case *openapi3filter.SecurityRequirementsError:
+		for _, err := range e.Errors {
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				return httpErr
+			}
+		}
+		return &echo.HTTPError{
+			Code:     http.StatusForbidden,
+			Message:  e.Error(),
+			Internal: err,
+		}
+	default:
+		return &echo.HTTPError{
+			Code:     http.StatusInternalServerError,
+			Message:  fmt.Sprintf("error validating request: %s", err),
+			Internal: err,
+		}
+	}
+}
+
+// FindRoute 实现 routers.Router，与校验使用同一份路由，返回的 Route 调用方不能修改
+func (v *Validator) FindRoute(req *http.Request) (*routers.Route, map[string]string, error) {
+	_, route, pathParams, err := v.find(req)
+	return route, pathParams, err
+}
+
+// find 查找 operation；按段匹配时返回预先构建的 Route，调用方不能修改
+func (v *Validator) find(req *http.Request) (*operation, *routers.Route, map[string]string, error) {
+	if v.router != nil {
+		route, pathParams, err := v.router.FindRoute(req)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		op, ok := v.operations[route.Method+" "+route.Path]
+		if !ok {
+			return nil, nil, nil, routers.ErrMethodNotAllowed
+		}
+		return op, route, pathParams, nil
+	}
+
+	path := req.URL.EscapedPath()
+	for _, t := range v.templates {
+		if !t.match(path, nil) {
+			continue
+		}
+		// 与 gorillamux 一样，第一个匹配的 path 决定结果
+		op, ok := t.methods[req.Method]
+		if !ok {
+			return nil, nil, nil, routers.ErrMethodNotAllowed
+		}
+		var pathParams map[string]string
+		if t.vars > 0 {
+			pathParams = make(map[string]string, t.vars)
+			t.match(path, pathParams)
+		}
+		return op, op.route, pathParams, nil
+	}
+	return nil, nil, nil, routers.ErrPathNotFound
+}
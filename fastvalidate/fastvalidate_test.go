@@ -0,0 +1,294 @@
+package fastvalidate_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/fastvalidate"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extraSpec = `
+openapi: "3.0.0"
+info: {title: extra, version: "1"}
+paths:
+  /files/{name}.json:
+    get:
+      operationId: getFile
+      parameters:
+        - {name: name, in: path, required: true, schema: {type: string, pattern: "^[a-z]+$"}}
+      responses: {"200": {description: ok}}
+  /files/latest.json:
+    get:
+      operationId: latestFile
+      responses: {"200": {description: ok}}
+  /items/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer}}
+      - {name: verbose, in: query, schema: {type: boolean}}
+    get:
+      operationId: getItem
+      parameters:
+        - {name: verbose, in: query, schema: {type: string, enum: ["yes", "no"]}}
+      responses: {"200": {description: ok}}
+    put:
+      operationId: putItem
+      security: [{key: []}]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [code]
+              properties:
+                code: {type: string, pattern: "^[A-Z]{3}$"}
+                size: {type: integer, minimum: 1}
+      responses: {"200": {description: ok}}
+components:
+  securitySchemes:
+    key: {type: apiKey, in: header, name: X-Key}
+`
+
+// echoBody 校验通过后读出请求体，确认校验器读完后放回了 Body
+func echoBody(c echo.Context) error {
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	return c.String(http.StatusOK, "ok "+c.Request().URL.RawQuery+" "+string(data))
+}
+
+func newEcho(mw echo.MiddlewareFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(mw)
+	e.Any("/*", echoBody)
+	return e
+}
+
+func serve(e *echo.Echo, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+type request struct {
+	method, target, body string
+	header               http.Header
+	code                 int
+}
+
+// assertSameAsMiddleware 对每个请求比较两个中间件的状态码与响应体
+func assertSameAsMiddleware(t *testing.T, swagger *openapi3.T, options *middleware.Options, requests []request) {
+	t.Helper()
+	reference := newEcho(middleware.OapiRequestValidatorWithOptions(swagger, options))
+	fast := newEcho(fastvalidate.OapiRequestValidatorWithOptions(swagger, options))
+	for _, r := range requests {
+		want := serve(reference, r.method, r.target, r.body, r.header)
+		got := serve(fast, r.method, r.target, r.body, r.header)
+		assert.Equal(t, r.code, want.Code, "%s %s: %s", r.method, r.target, want.Body.String())
+		assert.Equal(t, want.Code, got.Code, "%s %s", r.method, r.target)
+		assert.Equal(t, want.Body.String(), got.Body.String(), "%s %s", r.method, r.target)
+	}
+}
+
+func TestSameAsMiddleware(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+
+	assertSameAsMiddleware(t, swagger, nil, []request{
+		{method: http.MethodGet, target: "/api/pets", code: http.StatusOK},
+		{method: http.MethodGet, target: "/api/pets?limit=15&tags=a&tags=b", code: http.StatusOK},
+		{method: http.MethodGet, target: "/api/pets?limit=3", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/api/pets?limit=abc", code: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/api/pets", body: `{"name":"cat"}`, code: http.StatusOK},
+		{method: http.MethodPost, target: "/api/pets", body: `{"tag":"x"}`, code: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/api/pets", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/api/pets/42", code: http.StatusOK},
+		{method: http.MethodGet, target: "/api/pets/abc", code: http.StatusBadRequest},
+		{method: http.MethodDelete, target: "/api/pets/42", code: http.StatusOK},
+		{method: http.MethodPut, target: "/api/pets/42", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/api/pets/", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/api/pets/42/toys", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/other", code: http.StatusNotFound},
+	})
+}
+
+func TestSameAsMiddlewareForTemplatesAndSecurity(t *testing.T) {
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(extraSpec))
+	require.NoError(t, err)
+	require.NoError(t, swagger.Validate(context.Background()))
+
+	requests := []request{
+		{method: http.MethodGet, target: "/files/report.json", code: http.StatusOK},
+		{method: http.MethodGet, target: "/files/latest.json", code: http.StatusOK},
+		{method: http.MethodGet, target: "/files/Report.json", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/files/.json", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/files/report.xml", code: http.StatusNotFound},
+		{method: http.MethodGet, target: "/items/7?verbose=yes", code: http.StatusOK},
+		{method: http.MethodGet, target: "/items/7?verbose=true", code: http.StatusBadRequest},
+		{method: http.MethodPut, target: "/items/7", body: `{"code":"ABC"}`, code: http.StatusForbidden},
+		{method: http.MethodPut, target: "/items/7", body: `{"code":"ABC","size":2}`, header: http.Header{"X-Key": {"secret"}}, code: http.StatusOK},
+		{method: http.MethodPut, target: "/items/7", body: `{"code":"abc","size":0}`, header: http.Header{"X-Key": {"secret"}}, code: http.StatusBadRequest},
+		{method: http.MethodPut, target: "/items/x", body: `{"code":"abc"}`, header: http.Header{"X-Key": {"secret"}}, code: http.StatusBadRequest},
+	}
+	auth := func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		if input.RequestValidationInput.Request.Header.Get("X-Key") != "secret" {
+			return echo.NewHTTPError(http.StatusForbidden, "bad key")
+		}
+		return nil
+	}
+
+	t.Run("default", func(t *testing.T) {
+		options := &middleware.Options{Options: openapi3filter.Options{AuthenticationFunc: auth}}
+		assertSameAsMiddleware(t, swagger, options, requests)
+	})
+	t.Run("multi error", func(t *testing.T) {
+		options := &middleware.Options{Options: openapi3filter.Options{AuthenticationFunc: auth, MultiError: true}}
+		// MultiError 时安全校验的错误也交给 MultiErrorHandler，默认返回 400
+		multi := append([]request(nil), requests...)
+		for i := range multi {
+			if multi[i].code == http.StatusForbidden {
+				multi[i].code = http.StatusBadRequest
+			}
+		}
+		assertSameAsMiddleware(t, swagger, options, multi)
+	})
+	t.Run("servers fall back to gorillamux", func(t *testing.T) {
+		swagger.Servers = openapi3.Servers{{URL: "http://example.com"}}
+		defer func() { swagger.Servers = nil }()
+		options := &middleware.Options{Options: openapi3filter.Options{AuthenticationFunc: auth}, SilenceServersWarning: true}
+		assertSameAsMiddleware(t, swagger, options, []request{
+			{method: http.MethodGet, target: "http://example.com/files/report.json", code: http.StatusOK},
+			{method: http.MethodGet, target: "http://other.com/files/report.json", code: http.StatusNotFound},
+			{method: http.MethodGet, target: "http://example.com/items/7?verbose=true", code: http.StatusBadRequest},
+		})
+	})
+}
+
+func TestOptionsHooks(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+
+	var handled *echo.HTTPError
+	var userData interface{}
+	options := &middleware.Options{
+		UserData: "tenant",
+		Skipper:  func(c echo.Context) bool { return c.Request().Header.Get("X-Skip") != "" },
+		ErrorHandler: func(c echo.Context, err *echo.HTTPError) error {
+			handled = err
+			return c.NoContent(http.StatusTeapot)
+		},
+		ParamDecoder: func(param *openapi3.Parameter, values []string) (interface{}, *openapi3.Schema, error) {
+			return values[0], param.Schema.Value, nil
+		},
+	}
+	options.Options.AuthenticationFunc = func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		userData = middleware.GetUserData(ctx)
+		return nil
+	}
+	e := newEcho(fastvalidate.OapiRequestValidatorWithOptions(swagger, options))
+
+	rec := serve(e, http.MethodGet, "/api/pets?limit=3", "", nil)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	require.NotNil(t, handled)
+	assert.Equal(t, http.StatusBadRequest, handled.Code)
+
+	rec = serve(e, http.MethodGet, "/api/pets?limit=3", "", http.Header{"X-Skip": {"1"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, userData, "demo.yaml 没有安全要求，不会调用 AuthenticationFunc")
+}
+
+func BenchmarkValidators(b *testing.B) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(b, err)
+	validators := []struct {
+		name string
+		mw   echo.MiddlewareFunc
+	}{
+		{"middleware", middleware.OapiRequestValidator(swagger)},
+		{"fastvalidate", fastvalidate.OapiRequestValidator(swagger)},
+	}
+	requests := []struct {
+		name, method, target, body string
+	}{
+		{"FindPets", http.MethodGet, "/api/pets?limit=15&tags=a&tags=b", ""},
+		{"AddPet", http.MethodPost, "/api/pets", `{"name":"cat","tag":"indoor"}`},
+		{"FindPetById", http.MethodGet, "/api/pets/42", ""},
+		{"NotFound", http.MethodGet, "/api/owners/42", ""},
+	}
+	ok := func(c echo.Context) error { return nil }
+
+	for _, r := range requests {
+		for _, v := range validators {
+			b.Run(r.name+"/"+v.name, func(b *testing.B) {
+				e := echo.New()
+				handler := v.mw(ok)
+				req := httptest.NewRequest(r.method, r.target, nil)
+				if r.body != "" {
+					req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				}
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if r.body != "" {
+						req.Body = io.NopCloser(strings.NewReader(r.body))
+					}
+					_ = handler(c)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkFirstRequest 每次迭代重新加载文档，只计第一个请求的耗时：
+// middleware 在这个请求里编译 pattern，fastvalidate 已经在 New 中预热
+func BenchmarkFirstRequest(b *testing.B) {
+	validators := []struct {
+		name string
+		new  func(*openapi3.T) echo.MiddlewareFunc
+	}{
+		{"middleware", middleware.OapiRequestValidator},
+		{"fastvalidate", fastvalidate.OapiRequestValidator},
+	}
+	ok := func(c echo.Context) error { return nil }
+
+	for _, v := range validators {
+		b.Run(v.name, func(b *testing.B) {
+			e := echo.New()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				swagger, err := openapi3.NewLoader().LoadFromData([]byte(extraSpec))
+				require.NoError(b, err)
+				handler := v.new(swagger)(ok)
+				c := e.NewContext(httptest.NewRequest(http.MethodGet, "/files/report.json", nil), httptest.NewRecorder())
+				b.StartTimer()
+				_ = handler(c)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package fastvalidate
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// template 拆分后的 path 模板，例如 /pets/{id}.json 拆为 "pets" 与 {id}+".json"
+type template struct {
+	segments []segment
+	vars     int
+	methods  map[string]*operation
+}
+
+// segment path 中两个 / 之间的部分。name 为空时整段与 literal 比较，
+// 否则去掉 prefix 与 suffix 后剩下的非空部分就是变量的值，与 gorillamux 默认的 [^/]+ 一致。
+type segment struct {
+	literal string
+	name    string
+	prefix  string
+	suffix  string
+}
+
+// parseTemplate 只支持每段至多一个不带正则的变量，其余写法交给 gorillamux
+func parseTemplate(path string) ([]segment, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	parts := strings.Split(path[1:], "/")
+	segments := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		open := strings.IndexByte(part, '{')
+		if open < 0 {
+			if strings.IndexByte(part, '}') >= 0 {
+				return nil, false
+			}
+			segments = append(segments, segment{literal: part})
+			continue
+		}
+		end := strings.IndexByte(part, '}')
+		if end < open || strings.ContainsAny(part[end+1:], "{}") {
+			return nil, false
+		}
+		name := part[open+1 : end]
+		if name == "" || strings.ContainsAny(name, ":{") {
+			return nil, false
+		}
+		segments = append(segments, segment{name: name, prefix: part[:open], suffix: part[end+1:]})
+	}
+	return segments, true
+}
+
+// match 按段比较已转义的 path，params 不为 nil 时写入变量的值
+func (t *template) match(path string, params map[string]string) bool {
+	if len(path) == 0 || path[0] != '/' {
+		return false
+	}
+	rest := path[1:]
+	for i, seg := range t.segments {
+		var part string
+		slash := strings.IndexByte(rest, '/')
+		if i == len(t.segments)-1 {
+			if slash >= 0 {
+				return false
+			}
+			part = rest
+		} else {
+			if slash < 0 {
+				return false
+			}
+			part, rest = rest[:slash], rest[slash+1:]
+		}
+
+		if seg.name == "" {
+			if part != seg.literal {
+				return false
+			}
+			continue
+		}
+		if len(part) <= len(seg.prefix)+len(seg.suffix) ||
+			!strings.HasPrefix(part, seg.prefix) || !strings.HasSuffix(part, seg.suffix) {
+			return false
+		}
+		if params != nil {
+			params[seg.name] = part[len(seg.prefix) : len(part)-len(seg.suffix)]
+		}
+	}
+	return true
+}
+
+// compiler 遍历 schema，预热 kin-openapi 缓存在每个 Schema 上的 pattern 正则。
+// 缓存字段不导出，校验时也只会用它，所以这里不自己持有 *regexp.Regexp，
+// 而是用一个探测值走一遍 VisitJSONString 让它编译并写入；目的只是把编译挪到启动阶段，
+// 避免第一次请求时才编译、多个请求并发写入同一个 Schema。收益见 BenchmarkFirstRequest
+type compiler map[*openapi3.Schema]bool
+
+func (c compiler) compileContent(content openapi3.Content) {
+	for _, mediaType := range content {
+		if mediaType != nil && mediaType.Schema != nil {
+			c.compile(mediaType.Schema.Value)
+		}
+	}
+}
+
+func (c compiler) compile(schema *openapi3.Schema) {
+	if schema == nil || c[schema] {
+		return
+	}
+	c[schema] = true
+
+	// 探测值只为触发编译，校验结果不重要。编译发生在长度检查之后，用满足 minLength 的值才能走到 pattern；
+	// 类型不是 string 的 schema 在请求时也不会编译
+	if schema.Pattern != "" && (schema.Type == "" || schema.Type == openapi3.TypeString) && schema.MinLength <= maxProbeLength {
+		_ = schema.VisitJSONString(strings.Repeat("a", int(schema.MinLength)))
+	}
+
+	for _, ref := range schema.Properties {
+		c.compileRef(ref)
+	}
+	c.compileRef(schema.Items)
+	c.compileRef(schema.AdditionalProperties.Schema)
+	c.compileRef(schema.Not)
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, ref := range refs {
+			c.compileRef(ref)
+		}
+	}
+}
+
+func (c compiler) compileRef(ref *openapi3.SchemaRef) {
+	if ref != nil {
+		c.compile(ref.Value)
+	}
+}
+
+// maxProbeLength 超过这个 minLength 的 schema 不预编译，留给请求时处理
+const maxProbeLength = 1 << 12
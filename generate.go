@@ -0,0 +1,5 @@
+package codegen_test
+
+// gen.go 由 oapi-codegen v1.14.0 按 oapi-codegen.yaml 从 demo.yaml 生成，不要手工修改；
+// 需要调整生成代码时修改 templates/ 中覆盖的模板后重新生成
+//go:generate oapi-codegen -config oapi-codegen.yaml demo.yaml
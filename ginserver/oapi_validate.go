@@ -0,0 +1,127 @@
+package ginserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"demo/oapi-codegen-go/internal/oapivalidate"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	GinContextKey = "oapi-codegen/gin-context"
+	UserDataKey   = oapivalidate.UserDataKey
+)
+
+// HTTPError 对应 echo.HTTPError，携带状态码，校验失败时交给 ErrorHandler
+type HTTPError = oapivalidate.HTTPError
+
+// OapiValidatorFromYamlFile 从 YAML 文件创建校验中间件
+func OapiValidatorFromYamlFile(path string) (gin.HandlerFunc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	swagger, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s as Swagger YAML: %w", path, err)
+	}
+	return OapiRequestValidator(swagger), nil
+}
+
+// OapiRequestValidator creates a validator from a swagger object.
+func OapiRequestValidator(swagger *openapi3.T) gin.HandlerFunc {
+	return OapiRequestValidatorWithOptions(swagger, nil)
+}
+
+// ErrorHandler is called when there is an error in validation
+type ErrorHandler func(c *gin.Context, err *HTTPError)
+
+// MultiErrorHandler is called when oapi returns a MultiError type
+type MultiErrorHandler = oapivalidate.MultiErrorHandler
+
+// Options 与 middleware.Options 一一对应
+type Options struct {
+	ErrorHandler      ErrorHandler
+	Options           openapi3filter.Options
+	ParamDecoder      openapi3filter.ContentParameterDecoder
+	UserData          interface{}
+	Skipper           func(c *gin.Context) bool
+	MultiErrorHandler MultiErrorHandler
+	// SilenceServersWarning allows silencing a warning for https://github.com/deepmap/oapi-codegen/issues/882 that reports when an OpenAPI spec has `spec.Servers != nil`
+	SilenceServersWarning bool
+}
+
+// shared 返回与框架无关的部分，options 为 nil 时返回 nil
+func (options *Options) shared() *oapivalidate.Options {
+	if options == nil {
+		return nil
+	}
+	return &oapivalidate.Options{
+		Options:           &options.Options,
+		ParamDecoder:      options.ParamDecoder,
+		UserData:          options.UserData,
+		MultiErrorHandler: options.MultiErrorHandler,
+	}
+}
+
+// OapiRequestValidatorWithOptions creates a validator from a swagger object, with validation options
+func OapiRequestValidatorWithOptions(swagger *openapi3.T, options *Options) gin.HandlerFunc {
+	router, err := oapivalidate.NewRouter(swagger, options != nil && options.SilenceServersWarning)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(c *gin.Context) {
+		if options != nil && options.Skipper != nil && options.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		err := ValidateRequestFromContext(c, router, options)
+		if err != nil {
+			if options != nil && options.ErrorHandler != nil {
+				options.ErrorHandler(c, err)
+				// 错误处理函数没有中止时由这里中止，保证后续 handler 不会执行
+				if !c.IsAborted() {
+					c.Abort()
+				}
+			} else {
+				c.AbortWithStatusJSON(err.Code, gin.H{"message": err.Message})
+			}
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateRequestFromContext 与 middleware.ValidateRequestFromContext 行为一致
+func ValidateRequestFromContext(c *gin.Context, router routers.Router, options *Options) *HTTPError {
+	// Pass the gin context into the request validator, so that any callbacks
+	// which it invokes make it available.
+	requestContext := context.WithValue(context.Background(), GinContextKey, c) //nolint:staticcheck
+	return oapivalidate.ValidateRequest(requestContext, c.Request, router, options.shared())
+}
+
+// GetGinContext gets the gin context from within requests. It returns
+// nil if not found or wrong type.
+func GetGinContext(c context.Context) *gin.Context {
+	iface := c.Value(GinContextKey)
+	if iface == nil {
+		return nil
+	}
+	ginCtx, ok := iface.(*gin.Context)
+	if !ok {
+		return nil
+	}
+	return ginCtx
+}
+
+func GetUserData(c context.Context) interface{} {
+	return oapivalidate.GetUserData(c)
+}
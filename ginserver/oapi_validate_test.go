@@ -0,0 +1,94 @@
+package ginserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newValidatedEngine(t *testing.T, options *Options) *gin.Engine {
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(OapiRequestValidatorWithOptions(swagger, options))
+	r.GET("/pets", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.POST("/pets", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r
+}
+
+func doRequest(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOapiRequestValidatorDefaults(t *testing.T) {
+	r := newValidatedEngine(t, nil)
+
+	rec := doRequest(r, http.MethodGet, "/pets?limit=12", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(r, http.MethodGet, "/pets?limit=100", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"parameter \"limit\" in query has an error`)
+
+	rec = doRequest(r, http.MethodGet, "/owners", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestOapiRequestValidatorSkipper(t *testing.T) {
+	r := newValidatedEngine(t, &Options{
+		Skipper: func(c *gin.Context) bool { return c.Query("skip") == "1" },
+	})
+
+	rec := doRequest(r, http.MethodGet, "/pets?limit=100&skip=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestOapiRequestValidatorErrorHandler(t *testing.T) {
+	var got *HTTPError
+	r := newValidatedEngine(t, &Options{
+		ErrorHandler: func(c *gin.Context, err *HTTPError) {
+			got = err
+			c.String(http.StatusTeapot, "custom")
+		},
+	})
+
+	rec := doRequest(r, http.MethodGet, "/pets?limit=100", "")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "custom", rec.Body.String())
+	require.NotNil(t, got)
+	assert.Equal(t, http.StatusBadRequest, got.Code)
+}
+
+func TestOapiRequestValidatorMultiErrorHandler(t *testing.T) {
+	var count int
+	r := newValidatedEngine(t, &Options{
+		Options: openapi3filter.Options{MultiError: true},
+		MultiErrorHandler: func(me openapi3.MultiError) *HTTPError {
+			count = len(me)
+			return &HTTPError{Code: http.StatusUnprocessableEntity, Message: "multi"}
+		},
+	})
+
+	rec := doRequest(r, http.MethodGet, "/pets?limit=100&tags=a", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, count)
+
+	rec = doRequest(r, http.MethodPost, "/pets", `{"tag":1}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, `{"message":"multi"}`, rec.Body.String())
+}
@@ -0,0 +1,548 @@
+// Package ginserver 提供与根包 Echo 版本对应的 gin 服务端：ServerInterface、
+// RegisterHandlersWithOptions、strict 适配器以及请求校验中间件。
+// 模型、请求/响应对象和 StrictServerInterface 直接复用根包。
+package ginserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/safebind"
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/gin-gonic/gin"
+)
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (DELETE /operations/{id})
+	CancelOperation(c *gin.Context, id string)
+
+	// (GET /operations/{id})
+	GetOperation(c *gin.Context, id string)
+
+	// (GET /pets)
+	FindPets(c *gin.Context, params codegenTest.FindPetsParams)
+
+	// (POST /pets)
+	AddPet(c *gin.Context)
+
+	// (DELETE /pets/{id})
+	DeletePet(c *gin.Context, id int64)
+
+	// (GET /pets/{id})
+	FindPetById(c *gin.Context, id int64)
+
+	// (POST /pets:bulk)
+	BulkImportPets(c *gin.Context, params codegenTest.BulkImportPetsParams)
+
+	// (GET /pets:export)
+	ExportPets(c *gin.Context, params codegenTest.ExportPetsParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandler       func(*gin.Context, error, int)
+}
+
+type MiddlewareFunc func(c *gin.Context)
+
+// CancelOperation operation middleware
+func (siw *ServerInterfaceWrapper) CancelOperation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = safebind.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, c.Param("id"), &id)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CancelOperation(c, id)
+}
+
+// GetOperation operation middleware
+func (siw *ServerInterfaceWrapper) GetOperation(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = safebind.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, c.Param("id"), &id)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetOperation(c, id)
+}
+
+// FindPets operation middleware
+func (siw *ServerInterfaceWrapper) FindPets(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params codegenTest.FindPetsParams
+
+	// ------------- Optional query parameter "tags" -------------
+
+	err = safebind.BindQueryParameter("form", true, false, "tags", c.Request.URL.Query(), &params.Tags)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tags: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = safebind.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.FindPets(c, params)
+}
+
+// AddPet operation middleware
+func (siw *ServerInterfaceWrapper) AddPet(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.AddPet(c)
+}
+
+// DeletePet operation middleware
+func (siw *ServerInterfaceWrapper) DeletePet(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int64
+
+	err = safebind.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, c.Param("id"), &id)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeletePet(c, id)
+}
+
+// FindPetById operation middleware
+func (siw *ServerInterfaceWrapper) FindPetById(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int64
+
+	err = safebind.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, c.Param("id"), &id)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.FindPetById(c, id)
+}
+
+// BulkImportPets operation middleware
+func (siw *ServerInterfaceWrapper) BulkImportPets(c *gin.Context) {
+	if !customMethod(c, "bulk") {
+		return
+	}
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params codegenTest.BulkImportPetsParams
+
+	// ------------- Optional query parameter "mode" -------------
+
+	err = safebind.BindQueryParameter("form", true, false, "mode", c.Request.URL.Query(), &params.Mode)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter mode: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.BulkImportPets(c, params)
+}
+
+// ExportPets operation middleware
+func (siw *ServerInterfaceWrapper) ExportPets(c *gin.Context) {
+	if !customMethod(c, "export") {
+		return
+	}
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params codegenTest.ExportPetsParams
+
+	// ------------- Optional query parameter "tags" -------------
+
+	err = safebind.BindQueryParameter("form", true, false, "tags", c.Request.URL.Query(), &params.Tags)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter tags: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ExportPets(c, params)
+}
+
+// customMethod gin 没有转义冒号的写法，"/pets:bulk" 注册为 "/pets" 之后的参数 bulk，
+// "/petsxyz" 也会命中；参数不是 ":bulk" 本身时按 404 处理
+func customMethod(c *gin.Context, name string) bool {
+	if c.Param(name) != ":"+name {
+		c.AbortWithStatus(http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router gin.IRouter, si ServerInterface) {
+	RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options GinServerOptions) {
+	errorHandler := options.ErrorHandler
+	if errorHandler == nil {
+		// 与 Echo 默认的 HTTPErrorHandler 输出保持一致
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.AbortWithStatusJSON(statusCode, gin.H{"message": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandler:       errorHandler,
+	}
+
+	router.DELETE(options.BaseURL+"/operations/:id", wrapper.CancelOperation)
+	router.GET(options.BaseURL+"/operations/:id", wrapper.GetOperation)
+	router.GET(options.BaseURL+"/pets", wrapper.FindPets)
+	router.POST(options.BaseURL+"/pets", wrapper.AddPet)
+	router.DELETE(options.BaseURL+"/pets/:id", wrapper.DeletePet)
+	router.GET(options.BaseURL+"/pets/:id", wrapper.FindPetById)
+	router.POST(options.BaseURL+"/pets:bulk", wrapper.BulkImportPets)
+	router.GET(options.BaseURL+"/pets:export", wrapper.ExportPets)
+}
+
+type StrictHandlerFunc = runtime.StrictGinHandlerFunc
+type StrictMiddlewareFunc = runtime.StrictGinMiddlewareFunc
+
+func NewStrictHandler(ssi codegenTest.StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         codegenTest.StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// CancelOperation operation middleware
+func (sh *strictHandler) CancelOperation(ctx *gin.Context, id string) {
+	var request codegenTest.CancelOperationRequestObject
+
+	request.Id = id
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.CancelOperation(ctx, request.(codegenTest.CancelOperationRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CancelOperation")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.CancelOperationResponseObject); ok {
+		if err := validResponse.VisitCancelOperationResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// GetOperation operation middleware
+func (sh *strictHandler) GetOperation(ctx *gin.Context, id string) {
+	var request codegenTest.GetOperationRequestObject
+
+	request.Id = id
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetOperation(ctx, request.(codegenTest.GetOperationRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetOperation")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.GetOperationResponseObject); ok {
+		if err := validResponse.VisitGetOperationResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// FindPets operation middleware
+func (sh *strictHandler) FindPets(ctx *gin.Context, params codegenTest.FindPetsParams) {
+	var request codegenTest.FindPetsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.FindPets(ctx, request.(codegenTest.FindPetsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "FindPets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.FindPetsResponseObject); ok {
+		if err := validResponse.VisitFindPetsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// AddPet operation middleware
+func (sh *strictHandler) AddPet(ctx *gin.Context) {
+	var request codegenTest.AddPetRequestObject
+
+	var body codegenTest.AddPetJSONRequestBody
+	if err := ctx.ShouldBind(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.AddPet(ctx, request.(codegenTest.AddPetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AddPet")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.AddPetResponseObject); ok {
+		if err := validResponse.VisitAddPetResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// DeletePet operation middleware
+func (sh *strictHandler) DeletePet(ctx *gin.Context, id int64) {
+	var request codegenTest.DeletePetRequestObject
+
+	request.Id = id
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.DeletePet(ctx, request.(codegenTest.DeletePetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeletePet")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.DeletePetResponseObject); ok {
+		if err := validResponse.VisitDeletePetResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// FindPetById operation middleware
+func (sh *strictHandler) FindPetById(ctx *gin.Context, id int64) {
+	var request codegenTest.FindPetByIdRequestObject
+
+	request.Id = id
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.FindPetById(ctx, request.(codegenTest.FindPetByIdRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "FindPetById")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.FindPetByIdResponseObject); ok {
+		if err := validResponse.VisitFindPetByIdResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// BulkImportPets operation middleware
+func (sh *strictHandler) BulkImportPets(ctx *gin.Context, params codegenTest.BulkImportPetsParams) {
+	var request codegenTest.BulkImportPetsRequestObject
+
+	request.Params = params
+
+	if strings.HasPrefix(ctx.GetHeader("Content-Type"), "application/json") {
+		var body codegenTest.BulkImportPetsJSONRequestBody
+		if err := ctx.ShouldBind(&body); err != nil {
+			ctx.Status(http.StatusBadRequest)
+			ctx.Error(err)
+			return
+		}
+		request.JSONBody = &body
+	}
+	if strings.HasPrefix(ctx.GetHeader("Content-Type"), "application/x-ndjson") {
+		request.Body = ctx.Request.Body
+	}
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.BulkImportPets(ctx, request.(codegenTest.BulkImportPetsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "BulkImportPets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.BulkImportPetsResponseObject); ok {
+		if err := validResponse.VisitBulkImportPetsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
+
+// ExportPets operation middleware
+func (sh *strictHandler) ExportPets(ctx *gin.Context, params codegenTest.ExportPetsParams) {
+	var request codegenTest.ExportPetsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ExportPets(ctx, request.(codegenTest.ExportPetsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ExportPets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(codegenTest.ExportPetsResponseObject); ok {
+		if err := validResponse.VisitExportPetsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("Unexpected response type: %T", response))
+	}
+}
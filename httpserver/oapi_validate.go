@@ -0,0 +1,106 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"demo/oapi-codegen-go/internal/oapivalidate"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+const (
+	UserDataKey = oapivalidate.UserDataKey
+)
+
+// HTTPError 对应 echo.HTTPError，携带状态码，校验失败时交给 ErrorHandler
+type HTTPError = oapivalidate.HTTPError
+
+// OapiValidatorFromYamlFile 从 YAML 文件创建校验中间件
+func OapiValidatorFromYamlFile(path string) (MiddlewareFunc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	swagger, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s as Swagger YAML: %w", path, err)
+	}
+	return OapiRequestValidator(swagger), nil
+}
+
+// OapiRequestValidator creates a validator from a swagger object.
+func OapiRequestValidator(swagger *openapi3.T) MiddlewareFunc {
+	return OapiRequestValidatorWithOptions(swagger, nil)
+}
+
+// ErrorHandler is called when there is an error in validation
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err *HTTPError)
+
+// MultiErrorHandler is called when oapi returns a MultiError type
+type MultiErrorHandler = oapivalidate.MultiErrorHandler
+
+// Options 与 middleware.Options 一一对应
+type Options struct {
+	ErrorHandler      ErrorHandler
+	Options           openapi3filter.Options
+	ParamDecoder      openapi3filter.ContentParameterDecoder
+	UserData          interface{}
+	Skipper           func(r *http.Request) bool
+	MultiErrorHandler MultiErrorHandler
+	// SilenceServersWarning allows silencing a warning for https://github.com/deepmap/oapi-codegen/issues/882 that reports when an OpenAPI spec has `spec.Servers != nil`
+	SilenceServersWarning bool
+}
+
+// shared 返回与框架无关的部分，options 为 nil 时返回 nil
+func (options *Options) shared() *oapivalidate.Options {
+	if options == nil {
+		return nil
+	}
+	return &oapivalidate.Options{
+		Options:           &options.Options,
+		ParamDecoder:      options.ParamDecoder,
+		UserData:          options.UserData,
+		MultiErrorHandler: options.MultiErrorHandler,
+	}
+}
+
+// OapiRequestValidatorWithOptions creates a validator from a swagger object, with validation options
+func OapiRequestValidatorWithOptions(swagger *openapi3.T, options *Options) MiddlewareFunc {
+	router, err := oapivalidate.NewRouter(swagger, options != nil && options.SilenceServersWarning)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if options != nil && options.Skipper != nil && options.Skipper(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := ValidateRequest(r, router, options); err != nil {
+				if options != nil && options.ErrorHandler != nil {
+					options.ErrorHandler(w, r, err)
+				} else {
+					writeMessage(w, err.Code, err.Message)
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ValidateRequest 与 middleware.ValidateRequestFromContext 行为一致
+func ValidateRequest(req *http.Request, router routers.Router, options *Options) *HTTPError {
+	return oapivalidate.ValidateRequest(req.Context(), req, router, options.shared())
+}
+
+func GetUserData(c context.Context) interface{} {
+	return oapivalidate.GetUserData(c)
+}
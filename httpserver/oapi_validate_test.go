@@ -0,0 +1,163 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer 把调用的 operation 与绑定好的参数写回响应
+type recordingServer struct{}
+
+func (recordingServer) reply(w http.ResponseWriter, format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+}
+
+func (s recordingServer) CancelOperation(w http.ResponseWriter, r *http.Request, id string) {
+	s.reply(w, "CancelOperation %s", id)
+}
+
+func (s recordingServer) GetOperation(w http.ResponseWriter, r *http.Request, id string) {
+	s.reply(w, "GetOperation %s", id)
+}
+
+func (s recordingServer) FindPets(w http.ResponseWriter, r *http.Request, params codegenTest.FindPetsParams) {
+	limit := int32(0)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	s.reply(w, "FindPets %d", limit)
+}
+
+func (s recordingServer) AddPet(w http.ResponseWriter, r *http.Request) { s.reply(w, "AddPet") }
+
+func (s recordingServer) DeletePet(w http.ResponseWriter, r *http.Request, id int64) {
+	s.reply(w, "DeletePet %d", id)
+}
+
+func (s recordingServer) FindPetById(w http.ResponseWriter, r *http.Request, id int64) {
+	s.reply(w, "FindPetById %d", id)
+}
+
+func (s recordingServer) BulkImportPets(w http.ResponseWriter, r *http.Request, params codegenTest.BulkImportPetsParams) {
+	s.reply(w, "BulkImportPets")
+}
+
+func (s recordingServer) ExportPets(w http.ResponseWriter, r *http.Request, params codegenTest.ExportPetsParams) {
+	s.reply(w, "ExportPets")
+}
+
+func newValidatedHandler(t *testing.T, options *Options) http.Handler {
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	return OapiRequestValidatorWithOptions(swagger, options)(Handler(recordingServer{}))
+}
+
+func doRequest(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeMuxPatterns(t *testing.T) {
+	h := Handler(recordingServer{})
+	for _, tc := range []struct {
+		method, target string
+		code           int
+		body           string
+	}{
+		{http.MethodGet, "/pets?limit=12", http.StatusOK, "FindPets 12"},
+		{http.MethodPost, "/pets", http.StatusOK, "AddPet"},
+		{http.MethodGet, "/pets/7", http.StatusOK, "FindPetById 7"},
+		{http.MethodDelete, "/pets/7", http.StatusOK, "DeletePet 7"},
+		{http.MethodPost, "/pets:bulk", http.StatusOK, "BulkImportPets"},
+		{http.MethodGet, "/pets:export", http.StatusOK, "ExportPets"},
+		{http.MethodGet, "/operations/op-1", http.StatusOK, "GetOperation op-1"},
+		{http.MethodDelete, "/operations/op-1", http.StatusOK, "CancelOperation op-1"},
+		// : 是字面量，不是参数
+		{http.MethodGet, "/pets:import", http.StatusNotFound, ""},
+		{http.MethodPut, "/pets/7", http.StatusMethodNotAllowed, ""},
+		// 参数绑定失败交给 ErrorHandlerFunc，默认输出与 Echo 一致
+		{http.MethodGet, "/pets/x", http.StatusBadRequest, `{"message":"Invalid format for parameter id`},
+	} {
+		rec := doRequest(h, tc.method, tc.target, "")
+		assert.Equal(t, tc.code, rec.Code, "%s %s", tc.method, tc.target)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), tc.body), "%s %s: %s", tc.method, tc.target, rec.Body.String())
+	}
+
+	// BaseURL 加在每个模式前
+	mux := HandlerFromMuxWithBaseURL(recordingServer{}, http.NewServeMux(), "/api")
+	assert.Equal(t, "FindPetById 7", doRequest(mux, http.MethodGet, "/api/pets/7", "").Body.String())
+	assert.Equal(t, http.StatusNotFound, doRequest(mux, http.MethodGet, "/pets/7", "").Code)
+}
+
+func TestOapiRequestValidatorDefaults(t *testing.T) {
+	h := newValidatedHandler(t, nil)
+
+	rec := doRequest(h, http.MethodGet, "/pets?limit=12", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(h, http.MethodGet, "/pets?limit=100", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"parameter \"limit\" in query has an error`)
+
+	rec = doRequest(h, http.MethodGet, "/owners", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestOapiRequestValidatorSkipper(t *testing.T) {
+	h := newValidatedHandler(t, &Options{
+		Skipper: func(r *http.Request) bool { return r.URL.Query().Get("skip") == "1" },
+	})
+
+	rec := doRequest(h, http.MethodGet, "/pets?limit=100&skip=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestOapiRequestValidatorErrorHandler(t *testing.T) {
+	var got *HTTPError
+	h := newValidatedHandler(t, &Options{
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err *HTTPError) {
+			got = err
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("custom"))
+		},
+	})
+
+	rec := doRequest(h, http.MethodGet, "/pets?limit=100", "")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "custom", rec.Body.String())
+	require.NotNil(t, got)
+	assert.Equal(t, http.StatusBadRequest, got.Code)
+}
+
+func TestOapiRequestValidatorMultiErrorHandler(t *testing.T) {
+	var count int
+	h := newValidatedHandler(t, &Options{
+		Options: openapi3filter.Options{MultiError: true},
+		MultiErrorHandler: func(me openapi3.MultiError) *HTTPError {
+			count = len(me)
+			return &HTTPError{Code: http.StatusUnprocessableEntity, Message: "multi"}
+		},
+	})
+
+	rec := doRequest(h, http.MethodGet, "/pets?limit=100&tags=a", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, count)
+
+	rec = doRequest(h, http.MethodPost, "/pets", `{"tag":1}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, `{"message":"multi"}`, strings.TrimSpace(rec.Body.String()))
+}
@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// 消息的 key 就是英文原文（与 kin-openapi 的措辞一致）。某个语言缺少翻译时
+// message.Printer 直接按 key 格式化，英文因此总是兜底。
+const (
+	msgParameter = "parameter %q in %s has an error: %s"
+	msgBody      = "request body has an error: %s"
+	msgBinding   = "Invalid format for parameter %s: %s"
+
+	msgMinimum          = "number must be at least %v"
+	msgExclusiveMinimum = "number must be more than %v"
+	msgMaximum          = "number must be at most %v"
+	msgExclusiveMaximum = "number must be less than %v"
+	msgMultipleOf       = "number must be a multiple of %v"
+	msgMinLength        = "minimum string length is %v"
+	msgMaxLength        = "maximum string length is %v"
+	msgMinItems         = "minimum number of items is %v"
+	msgMaxItems         = "maximum number of items is %v"
+	msgMinProperties    = "there must be at least %v properties"
+	msgMaxProperties    = "there must be at most %v properties"
+	msgPattern          = "string doesn't match the regular expression %q"
+	msgEnum             = "value is not one of the allowed values %v"
+	msgType             = "value must be of type %s"
+	msgFormat           = "value is not a valid %v"
+	msgUniqueItems      = "duplicate items found"
+	msgPropertyMissing  = "property %q is missing"
+	msgRequired         = "value is required but missing"
+	msgEmptyValue       = "empty value is not allowed"
+	msgSecurity         = "security requirements failed"
+)
+
+// 整条替换的消息，来自路由与 Echo
+var plainMessages = []string{
+	"no matching operation was found",
+	"method not allowed",
+	"Not Found",
+	"Method Not Allowed",
+	"Bad Request",
+	"Internal Server Error",
+}
+
+// schema 类型名，msgType 的参数
+var typeNames = []string{"integer", "number", "string", "boolean", "array", "object"}
+
+var (
+	// English 兜底语言
+	English = language.English
+	// ChineseSimplified zh-CN
+	ChineseSimplified = language.MustParse("zh-CN")
+)
+
+var zhCN = map[string]string{
+	msgParameter: "参数 %q（%s）有误：%s",
+	msgBody:      "请求体有误：%s",
+	msgBinding:   "参数 %s 格式错误：%s",
+
+	msgMinimum:          "数值不能小于 %v",
+	msgExclusiveMinimum: "数值必须大于 %v",
+	msgMaximum:          "数值不能大于 %v",
+	msgExclusiveMaximum: "数值必须小于 %v",
+	msgMultipleOf:       "数值必须是 %v 的倍数",
+	msgMinLength:        "字符串长度不能少于 %v",
+	msgMaxLength:        "字符串长度不能超过 %v",
+	msgMinItems:         "至少需要 %v 项",
+	msgMaxItems:         "最多只能有 %v 项",
+	msgMinProperties:    "至少需要 %v 个属性",
+	msgMaxProperties:    "最多只能有 %v 个属性",
+	msgPattern:          "字符串不匹配正则表达式 %q",
+	msgEnum:             "取值必须是 %v 之一",
+	msgType:             "取值必须是%s",
+	msgFormat:           "不是合法的 %v",
+	msgUniqueItems:      "存在重复的项",
+	msgPropertyMissing:  "缺少属性 %q",
+	msgRequired:         "缺少必填的值",
+	msgEmptyValue:       "不允许为空",
+	msgSecurity:         "安全校验未通过",
+
+	"no matching operation was found": "找不到匹配的接口",
+	"method not allowed":              "不支持该请求方法",
+	"Not Found":                       "未找到",
+	"Method Not Allowed":              "不支持该请求方法",
+	"Bad Request":                     "请求有误",
+	"Internal Server Error":           "服务器内部错误",
+
+	"integer": "整数",
+	"number":  "数字",
+	"string":  "字符串",
+	"boolean": "布尔值",
+	"array":   "数组",
+	"object":  "对象",
+}
+
+// NewCatalog 返回包含 en 与 zh-CN 默认消息的 catalog。项目可以继续调用 SetString
+// 覆盖已有消息或增加语言，key 使用英文原文，例如：
+//
+//	c := i18n.NewCatalog()
+//	_ = c.SetString(language.Japanese, "number must be at least %v", "%v 以上である必要があります")
+func NewCatalog() *catalog.Builder {
+	c := catalog.NewBuilder(catalog.Fallback(English))
+	for _, key := range allKeys() {
+		_ = c.SetString(English, key, key)
+	}
+	for key, msg := range zhCN {
+		_ = c.SetString(ChineseSimplified, key, msg)
+	}
+	return c
+}
+
+func allKeys() []string {
+	keys := []string{
+		msgParameter, msgBody, msgBinding,
+		msgMinimum, msgExclusiveMinimum, msgMaximum, msgExclusiveMaximum, msgMultipleOf,
+		msgMinLength, msgMaxLength, msgMinItems, msgMaxItems, msgMinProperties, msgMaxProperties,
+		msgPattern, msgEnum, msgType, msgFormat, msgUniqueItems, msgPropertyMissing,
+		msgRequired, msgEmptyValue, msgSecurity,
+	}
+	keys = append(keys, plainMessages...)
+	return append(keys, typeNames...)
+}
@@ -0,0 +1,230 @@
+// Package i18n 按 Accept-Language 翻译请求校验与参数绑定的错误信息。
+//
+// kin-openapi 与生成代码的错误都是英文。Localizer 根据 violations 展开的结构化信息
+// （位置、关键字、期望值）用协商出的语言重新组织每条消息，内置 en 与 zh-CN，
+// 无法协商或缺少翻译时使用英文。
+package i18n
+
+import (
+	"regexp"
+	"strings"
+
+	"demo/oapi-codegen-go/internal/pointer"
+	"demo/oapi-codegen-go/violations"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+const headerAcceptLanguage = "Accept-Language"
+
+// Options 控制使用的 catalog
+type Options struct {
+	// Catalog 为 nil 时使用 NewCatalog()。支持的语言以 catalog 中出现的语言为准
+	Catalog *catalog.Builder
+	// Violations 展开 HTTPError.Internal 中的校验错误，为 nil 时使用 violations.New(violations.Options{})
+	Violations *violations.Renderer
+}
+
+// Localizer 协商语言并翻译错误，可以并发使用
+type Localizer struct {
+	catalog  *catalog.Builder
+	tags     []language.Tag
+	matcher  language.Matcher
+	renderer *violations.Renderer
+}
+
+// New 创建 Localizer，English 总是第一个候选语言
+func New(options Options) *Localizer {
+	if options.Catalog == nil {
+		options.Catalog = NewCatalog()
+	}
+	if options.Violations == nil {
+		options.Violations = violations.New(violations.Options{})
+	}
+	tags := []language.Tag{English}
+	for _, tag := range options.Catalog.Languages() {
+		if tag != English {
+			tags = append(tags, tag)
+		}
+	}
+	return &Localizer{
+		catalog:  options.Catalog,
+		tags:     tags,
+		matcher:  language.NewMatcher(tags),
+		renderer: options.Violations,
+	}
+}
+
+// Negotiate 按 Accept-Language 选择语言，无法解析或没有匹配时返回 English
+func (l *Localizer) Negotiate(acceptLanguage string) language.Tag {
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return English
+	}
+	_, index, confidence := l.matcher.Match(accepted...)
+	if confidence == language.No {
+		return English
+	}
+	return l.tags[index]
+}
+
+// Printer 返回 tag 对应的 Printer
+func (l *Localizer) Printer(tag language.Tag) *message.Printer {
+	return message.NewPrinter(tag, message.Catalog(l.catalog))
+}
+
+// Middleware 翻译后续中间件与 handler 返回的 *echo.HTTPError，需要注册在校验中间件之前。
+// 只有消息确实按目录重新生成时才带上 Content-Language 与 Vary: Accept-Language。
+func (l *Localizer) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			he, ok := err.(*echo.HTTPError)
+			if !ok {
+				return err
+			}
+			tag := l.Negotiate(c.Request().Header.Get(headerAcceptLanguage))
+			localized, translated := l.localize(l.Printer(tag), he)
+			if translated {
+				header := c.Response().Header()
+				header.Add(echo.HeaderVary, headerAcceptLanguage)
+				header.Set("Content-Language", tag.String())
+			}
+			return localized
+		}
+	}
+}
+
+// bindingError 生成的 wrapper 绑定参数失败时的消息
+var bindingError = regexp.MustCompile(`(?s)^Invalid format for parameter (\S+): (.*)$`)
+
+// Localize 返回翻译后的副本，不修改 err（echo.ErrNotFound 等是全局变量）。
+// 无法识别的消息原样保留。
+func (l *Localizer) Localize(p *message.Printer, err *echo.HTTPError) *echo.HTTPError {
+	localized, _ := l.localize(p, err)
+	return localized
+}
+
+// localize 同 Localize，另外返回是否至少有一条消息按目录重新生成
+func (l *Localizer) localize(p *message.Printer, err *echo.HTTPError) (*echo.HTTPError, bool) {
+	localized := &echo.HTTPError{Code: err.Code, Message: err.Message, Internal: err.Internal}
+	translated := false
+	switch msg := err.Message.(type) {
+	case *violations.Body:
+		body := &violations.Body{Code: msg.Code, Violations: make([]violations.Violation, len(msg.Violations))}
+		messages := make([]string, 0, len(msg.Violations))
+		for i, v := range msg.Violations {
+			var ok bool
+			v.Message, ok = l.violationMessage(p, v)
+			translated = translated || ok
+			body.Violations[i] = v
+			messages = append(messages, v.Message)
+		}
+		body.Message = strings.Join(messages, " | ")
+		localized.Message = body
+	case string:
+		switch {
+		case bindingError.MatchString(msg):
+			m := bindingError.FindStringSubmatch(msg)
+			localized.Message = p.Sprintf(msgBinding, m[1], m[2])
+			translated = true
+		case validationError(err.Internal):
+			list := l.renderer.Violations(err.Internal)
+			messages := make([]string, 0, len(list))
+			for _, v := range list {
+				message, ok := l.violationMessage(p, v)
+				translated = translated || ok
+				messages = append(messages, message)
+			}
+			localized.Message = strings.Join(messages, " | ")
+		case containsString(plainMessages, msg):
+			localized.Message = p.Sprintf(msg)
+			translated = true
+		}
+	}
+	return localized, translated
+}
+
+func validationError(err error) bool {
+	switch err.(type) {
+	case openapi3.MultiError, *openapi3filter.RequestError, *openapi3filter.SecurityRequirementsError:
+		return true
+	}
+	return false
+}
+
+// violationMessage 按关键字重新生成消息；不认识的关键字保留原消息并返回 false
+func (l *Localizer) violationMessage(p *message.Printer, v violations.Violation) (string, bool) {
+	reason, ok := l.reason(p, v)
+	if !ok {
+		return v.Message, false
+	}
+	if v.Pointer != "" {
+		reason = v.Pointer + ": " + reason
+	}
+	switch {
+	case v.Location == violations.LocationBody:
+		return p.Sprintf(msgBody, reason), true
+	case v.Parameter != "":
+		return p.Sprintf(msgParameter, v.Parameter, v.Location, reason), true
+	}
+	return reason, true
+}
+
+func (l *Localizer) reason(p *message.Printer, v violations.Violation) (string, bool) {
+	switch v.Keyword {
+	case "type":
+		name, _ := v.Expected.(string)
+		if !containsString(typeNames, name) {
+			return "", false
+		}
+		return p.Sprintf(msgType, p.Sprintf(name)), true
+	case "required":
+		// 请求体中缺少的属性是 pointer 的最后一段，参数与整个请求体则没有 pointer
+		if v.Pointer != "" {
+			return p.Sprintf(msgPropertyMissing, pointer.Last(v.Pointer)), true
+		}
+		return p.Sprintf(msgRequired), true
+	case "uniqueItems":
+		return p.Sprintf(msgUniqueItems), true
+	case "allowEmptyValue":
+		return p.Sprintf(msgEmptyValue), true
+	case "security":
+		return p.Sprintf(msgSecurity), true
+	}
+	if key, ok := keywordMessages[v.Keyword]; ok && v.Expected != nil {
+		return p.Sprintf(key, v.Expected), true
+	}
+	return "", false
+}
+
+// keywordMessages 以期望值为唯一参数的关键字
+var keywordMessages = map[string]string{
+	"minimum":          msgMinimum,
+	"exclusiveMinimum": msgExclusiveMinimum,
+	"maximum":          msgMaximum,
+	"exclusiveMaximum": msgExclusiveMaximum,
+	"multipleOf":       msgMultipleOf,
+	"minLength":        msgMinLength,
+	"maxLength":        msgMaxLength,
+	"minItems":         msgMinItems,
+	"maxItems":         msgMaxItems,
+	"minProperties":    msgMinProperties,
+	"maxProperties":    msgMaxProperties,
+	"pattern":          msgPattern,
+	"enum":             msgEnum,
+	"format":           msgFormat,
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
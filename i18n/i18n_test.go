@@ -0,0 +1,161 @@
+package i18n_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/i18n"
+	"demo/oapi-codegen-go/violations"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+// newEcho 与 cmd/server.go 相同的组合：翻译在最外层，校验失败按 violations 输出
+func newEcho(t *testing.T, localizer *i18n.Localizer, structured bool) *echo.Echo {
+	t.Helper()
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+	options := &middleware.Options{}
+	if structured {
+		r := violations.New(violations.Options{})
+		options = &middleware.Options{
+			Options:           openapi3filter.Options{MultiError: true},
+			MultiErrorHandler: r.MultiErrorHandler,
+			ErrorHandler:      r.ErrorHandler,
+		}
+	}
+	e := echo.New()
+	e.HTTPErrorHandler = app.HTTPErrorHandler
+	e.Use(localizer.Middleware())
+	e.Use(middleware.OapiRequestValidatorWithOptions(swagger, options))
+	codegenTest.RegisterHandlersWithBaseURL(e, &app.EchoServer{Store: app.NewPetStore()}, "/api")
+	return e
+}
+
+func send(e *echo.Echo, method, target, body, acceptLanguage string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) violations.Body {
+	t.Helper()
+	var body violations.Body
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return body
+}
+
+func TestNegotiate(t *testing.T) {
+	l := i18n.New(i18n.Options{})
+	for header, want := range map[string]language.Tag{
+		"":                     i18n.English,
+		"zh-CN":                i18n.ChineseSimplified,
+		"zh":                   i18n.ChineseSimplified,
+		"fr-FR, zh;q=0.8":      i18n.ChineseSimplified,
+		"en-US,zh-CN;q=0.9":    i18n.English,
+		"fr":                   i18n.English,
+		"not a language ;;;;=": i18n.English,
+	} {
+		assert.Equal(t, want, l.Negotiate(header), header)
+	}
+}
+
+func TestStructuredViolations(t *testing.T) {
+	e := newEcho(t, i18n.New(i18n.Options{}), true)
+
+	rec := send(e, http.MethodPost, "/api/pets", `{"tag":1}`, "zh-CN,zh;q=0.9")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "zh-CN", rec.Header().Get("Content-Language"))
+	assert.Contains(t, rec.Header().Values(echo.HeaderVary), "Accept-Language")
+	body := decode(t, rec)
+	var messages []string
+	for _, v := range body.Violations {
+		messages = append(messages, v.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		`请求体有误：/name: 缺少属性 "name"`,
+		`请求体有误：/tag: 取值必须是字符串`,
+	}, messages)
+	assert.Equal(t, int32(http.StatusBadRequest), body.Code)
+
+	// 英文与 kin-openapi 的措辞一致
+	body = decode(t, send(e, http.MethodGet, "/api/pets?limit=30", "", "en"))
+	assert.Equal(t, `parameter "limit" in query has an error: number must be at most 20`, body.Message)
+	body = decode(t, send(e, http.MethodGet, "/api/pets?limit=30", "", "zh"))
+	assert.Equal(t, `参数 "limit"（query）有误：数值不能大于 20`, body.Message)
+}
+
+func TestPlainMessages(t *testing.T) {
+	e := newEcho(t, i18n.New(i18n.Options{}), false)
+
+	// 默认的 MultiErrorHandler 之外，RequestError 只有字符串消息，按 Internal 重新生成
+	body := decode(t, send(e, http.MethodGet, "/api/pets?limit=3", "", "zh-CN"))
+	assert.Equal(t, `参数 "limit"（query）有误：数值不能小于 12`, body.Message)
+
+	body = decode(t, send(e, http.MethodGet, "/api/owners", "", "zh-CN"))
+	assert.Equal(t, "找不到匹配的接口", body.Message)
+
+	// 没有匹配的语言时使用英文
+	body = decode(t, send(e, http.MethodGet, "/api/owners", "", "ja"))
+	assert.Equal(t, "no matching operation was found", body.Message)
+}
+
+func TestBindingErrors(t *testing.T) {
+	l := i18n.New(i18n.Options{})
+	err := echo.NewHTTPError(http.StatusBadRequest, "Invalid format for parameter id: error binding string parameter: strconv.ParseInt: parsing \"x\": invalid syntax")
+
+	localized := l.Localize(l.Printer(i18n.ChineseSimplified), err)
+	assert.Equal(t, "参数 id 格式错误：error binding string parameter: strconv.ParseInt: parsing \"x\": invalid syntax", localized.Message)
+	assert.Equal(t, http.StatusBadRequest, localized.Code)
+	assert.Contains(t, err.Message, "Invalid format", "原错误不变")
+
+	unknown := echo.NewHTTPError(http.StatusConflict, "pet already exists")
+	assert.Equal(t, "pet already exists", l.Localize(l.Printer(i18n.ChineseSimplified), unknown).Message)
+}
+
+func TestUntranslatedHeaders(t *testing.T) {
+	e := echo.New()
+	e.Use(i18n.New(i18n.Options{}).Middleware())
+	e.GET("/conflict", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "pet already exists")
+	})
+
+	// 原样返回的消息不声明语言，也不按 Accept-Language 区分缓存
+	rec := send(e, http.MethodGet, "/conflict", "", "zh-CN")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Language"))
+	assert.Empty(t, rec.Header().Values(echo.HeaderVary))
+
+	rec = send(e, http.MethodGet, "/missing", "", "zh-CN")
+	assert.Equal(t, "zh-CN", rec.Header().Get("Content-Language"))
+	assert.Contains(t, rec.Header().Values(echo.HeaderVary), "Accept-Language")
+}
+
+func TestExtendCatalog(t *testing.T) {
+	c := i18n.NewCatalog()
+	require.NoError(t, c.SetString(language.Japanese, "no matching operation was found", "一致する操作が見つかりません"))
+	require.NoError(t, c.SetString(i18n.ChineseSimplified, "no matching operation was found", "接口不存在"))
+	e := newEcho(t, i18n.New(i18n.Options{Catalog: c}), true)
+
+	assert.Equal(t, "一致する操作が見つかりません", decode(t, send(e, http.MethodGet, "/api/owners", "", "ja")).Message)
+	assert.Equal(t, "接口不存在", decode(t, send(e, http.MethodGet, "/api/owners", "", "zh-CN")).Message)
+	// 日语缺少的消息回退到英文
+	body := decode(t, send(e, http.MethodGet, "/api/pets?limit=3", "", "ja"))
+	assert.Equal(t, `parameter "limit" in query has an error: number must be at least 12`, body.Message)
+}
@@ -0,0 +1,97 @@
+// Package allof 合并 schema 中的 allOf，供 example 与 oasdiff 共用
+package allof
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// Flatten 合并 allOf：属性与 required 取并集，数值和长度约束取更严格的一侧。
+// 没有 allOf 时原样返回 schema，否则返回新的 schema，不修改原文档
+func Flatten(schema *openapi3.Schema) *openapi3.Schema {
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = make(openapi3.Schemas, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		merged.Properties[name] = prop
+	}
+	merged.Required = append([]string(nil), schema.Required...)
+	for _, sub := range schema.AllOf {
+		if sub == nil || sub.Value == nil {
+			continue
+		}
+		merge(&merged, Flatten(sub.Value))
+	}
+	return &merged
+}
+
+func merge(dst, src *openapi3.Schema) {
+	if dst.Type == "" {
+		dst.Type = src.Type
+	}
+	if dst.Format == "" {
+		dst.Format = src.Format
+	}
+	if dst.Pattern == "" {
+		dst.Pattern = src.Pattern
+	}
+	if len(dst.Enum) == 0 {
+		dst.Enum = src.Enum
+	}
+	if dst.Items == nil {
+		dst.Items = src.Items
+	}
+	if dst.AdditionalProperties.Schema == nil {
+		dst.AdditionalProperties = src.AdditionalProperties
+	}
+	if len(dst.OneOf) == 0 {
+		dst.OneOf, dst.Discriminator = src.OneOf, src.Discriminator
+	}
+	if len(dst.AnyOf) == 0 {
+		dst.AnyOf = src.AnyOf
+	}
+	dst.ReadOnly = dst.ReadOnly || src.ReadOnly
+	dst.WriteOnly = dst.WriteOnly || src.WriteOnly
+	if src.Min != nil && (dst.Min == nil || *src.Min > *dst.Min) {
+		dst.Min, dst.ExclusiveMin = src.Min, src.ExclusiveMin
+	}
+	if src.Max != nil && (dst.Max == nil || *src.Max < *dst.Max) {
+		dst.Max, dst.ExclusiveMax = src.Max, src.ExclusiveMax
+	}
+	if src.MultipleOf != nil && dst.MultipleOf == nil {
+		dst.MultipleOf = src.MultipleOf
+	}
+	if src.MinLength > dst.MinLength {
+		dst.MinLength = src.MinLength
+	}
+	if src.MaxLength != nil && (dst.MaxLength == nil || *src.MaxLength < *dst.MaxLength) {
+		dst.MaxLength = src.MaxLength
+	}
+	if src.MinItems > dst.MinItems {
+		dst.MinItems = src.MinItems
+	}
+	if src.MaxItems != nil && (dst.MaxItems == nil || *src.MaxItems < *dst.MaxItems) {
+		dst.MaxItems = src.MaxItems
+	}
+	for name, prop := range src.Properties {
+		if existing, ok := dst.Properties[name]; ok && existing != prop {
+			// 两侧都声明了同一个属性，合并为同时满足两者的 allOf
+			prop = &openapi3.SchemaRef{Value: &openapi3.Schema{AllOf: openapi3.SchemaRefs{existing, prop}}}
+		}
+		dst.Properties[name] = prop
+	}
+	for _, name := range src.Required {
+		if !contains(dst.Required, name) {
+			dst.Required = append(dst.Required, name)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
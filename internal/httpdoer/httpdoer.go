@@ -0,0 +1,30 @@
+// Package httpdoer 放包装 HttpRequestDoer 时共用的小工具，供 cassette、coverage、contract 与 operations 使用
+package httpdoer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// CredentialHeaders 携带凭证的 header，录制或保存请求之前应当删除或脱敏
+var CredentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// Func 让普通函数实现 HttpRequestDoer
+type Func func(req *http.Request) (*http.Response, error)
+
+func (f Func) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+// ReadBody 读出 body 并放回一个可以再次读取的副本
+func ReadBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,156 @@
+// Package oapivalidate 放 ginserver 与 httpserver 请求校验中间件共用的、与框架无关的部分：
+// 错误类型、路由构建和 middleware.ValidateRequestFromContext 的校验与错误映射。
+package oapivalidate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const UserDataKey = "oapi-codegen/user-data"
+
+// HTTPError 对应 echo.HTTPError，携带状态码，校验失败时交给 ErrorHandler
+type HTTPError struct {
+	Code     int
+	Message  string
+	Internal error
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// MultiErrorHandler is called when oapi returns a MultiError type
+type MultiErrorHandler func(openapi3.MultiError) *HTTPError
+
+// Options 各框架 Options 中与框架无关的字段
+type Options struct {
+	Options           *openapi3filter.Options
+	ParamDecoder      openapi3filter.ContentParameterDecoder
+	UserData          interface{}
+	MultiErrorHandler MultiErrorHandler
+}
+
+// NewRouter 构建 gorillamux 路由，文档带 servers 且没有关闭警告时提示 oapi-codegen#882
+func NewRouter(swagger *openapi3.T, silenceServersWarning bool) (routers.Router, error) {
+	if swagger.Servers != nil && !silenceServersWarning {
+		log.Println("WARN: OapiRequestValidatorWithOptions called with an OpenAPI spec that has `Servers` set. This may lead to an HTTP 400 with `no matching operation was found` when sending a valid request, as the validator performs `Host` header validation. If you're expecting `Host` header validation, you can silence this warning by setting `Options.SilenceServersWarning = true`. See https://github.com/deepmap/oapi-codegen/issues/882 for more information.")
+	}
+	return gorillamux.NewRouter(swagger)
+}
+
+// ValidateRequest 与 middleware.ValidateRequestFromContext 行为一致。
+// ctx 由调用方放入各自框架的 context，options 为 nil 时不设置 UserDataKey
+func ValidateRequest(ctx context.Context, req *http.Request, router routers.Router, options *Options) *HTTPError {
+	route, pathParams, err := router.FindRoute(req)
+
+	// We failed to find a matching route for the request.
+	if err != nil {
+		switch e := err.(type) {
+		case *routers.RouteError:
+			// We've got a bad request, the path requested doesn't match
+			// either server, or path, or something.
+			return &HTTPError{Code: http.StatusNotFound, Message: e.Reason, Internal: err}
+		default:
+			// This should never happen today, but if our upstream code changes,
+			// we don't want to crash the server, so handle the unexpected error.
+			return &HTTPError{
+				Code:     http.StatusInternalServerError,
+				Message:  fmt.Sprintf("error validating route: %s", err.Error()),
+				Internal: err,
+			}
+		}
+	}
+
+	validationInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+	}
+
+	if options != nil {
+		validationInput.Options = options.Options
+		validationInput.ParamDecoder = options.ParamDecoder
+		ctx = context.WithValue(ctx, UserDataKey, options.UserData) //nolint:staticcheck
+	}
+
+	err = openapi3filter.ValidateRequest(ctx, validationInput)
+	if err != nil {
+		me := openapi3.MultiError{}
+		if errors.As(err, &me) {
+			errFunc := getMultiErrorHandlerFromOptions(options)
+			return errFunc(me)
+		}
+
+		switch e := err.(type) {
+		case *openapi3filter.RequestError:
+			// We've got a bad request
+			// Split up the verbose error by lines and return the first one
+			// openapi errors seem to be multi-line with a decent message on the first
+			errorLines := strings.Split(e.Error(), "\n")
+			return &HTTPError{
+				Code:     http.StatusBadRequest,
+				Message:  errorLines[0],
+				Internal: err,
+			}
+		case *openapi3filter.SecurityRequirementsError:
+			for _, err := range e.Errors {
+				var httpErr *HTTPError
+				if errors.As(err, &httpErr) {
+					return httpErr
+				}
+			}
+			return &HTTPError{
+				Code:     http.StatusForbidden,
+				Message:  e.Error(),
+				Internal: err,
+			}
+		default:
+			// This should never happen today, but if our upstream code changes,
+			// we don't want to crash the server, so handle the unexpected error.
+			return &HTTPError{
+				Code:     http.StatusInternalServerError,
+				Message:  fmt.Sprintf("error validating request: %s", err),
+				Internal: err,
+			}
+		}
+	}
+	return nil
+}
+
+func GetUserData(c context.Context) interface{} {
+	return c.Value(UserDataKey)
+}
+
+// attempt to get the MultiErrorHandler from the options. If it is not set,
+// return a default handler
+func getMultiErrorHandlerFromOptions(options *Options) MultiErrorHandler {
+	if options == nil || options.MultiErrorHandler == nil {
+		return defaultMultiErrorHandler
+	}
+	return options.MultiErrorHandler
+}
+
+// defaultMultiErrorHandler returns a StatusBadRequest (400) and a list
+// of all of the errors. This method is called if there are no other
+// methods defined on the options.
+func defaultMultiErrorHandler(me openapi3.MultiError) *HTTPError {
+	return &HTTPError{
+		Code:     http.StatusBadRequest,
+		Message:  me.Error(),
+		Internal: me,
+	}
+}
@@ -0,0 +1,24 @@
+// Package pointer 按 RFC 6901 拼接与拆分 JSON Pointer，供 lint、violations 与 i18n 使用
+package pointer
+
+import "strings"
+
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Join 转义每一段并拼接，没有任何一段时返回 ""（指向根）
+func Join(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(escaper.Replace(token))
+	}
+	return b.String()
+}
+
+// Last 取最后一段并还原转义
+func Last(pointer string) string {
+	return unescaper.Replace(pointer[strings.LastIndexByte(pointer, '/')+1:])
+}
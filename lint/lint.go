@@ -0,0 +1,280 @@
+// Package lint 按项目约定检查 OpenAPI 文档。
+//
+// 每条规则有默认的严重程度，可以通过 Config 调整或关闭；结果可以输出为 JSON，
+// 位置使用 JSON Pointer（RFC 6901），便于 CI 和编辑器定位。
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"demo/oapi-codegen-go/internal/pointer"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity 规则的严重程度，Off 表示关闭
+type Severity int
+
+const (
+	Off Severity = iota
+	Info
+	Warning
+	Error
+)
+
+var severityNames = map[Severity]string{Off: "off", Info: "info", Warning: "warning", Error: "error"}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity 解析 "off"、"info"、"warning"、"error"
+func ParseSeverity(s string) (Severity, error) {
+	for severity, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return severity, nil
+		}
+	}
+	return Off, fmt.Errorf("unknown severity %q", s)
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// Rule 一条检查规则
+type Rule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	check       func(l *linter)
+}
+
+// Rules 返回全部内置规则及其默认严重程度
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// DefaultOperationIDPattern 生成的方法名直接来自 operationId，只允许字母和数字
+const DefaultOperationIDPattern = `^[A-Za-z][A-Za-z0-9]*$`
+
+// Config 规则配置，可以从 YAML 或 JSON 文件读取：
+//
+//	rules:
+//	  response-4xx-missing: off
+//	  description-missing: error
+//	operationIdPattern: ^[a-z][A-Za-z0-9]*$
+type Config struct {
+	// Rules 覆盖规则的严重程度
+	Rules map[string]Severity `json:"rules" yaml:"rules"`
+	// OperationIDPattern 为空时使用 DefaultOperationIDPattern
+	OperationIDPattern string `json:"operationIdPattern" yaml:"operationIdPattern"`
+}
+
+// LoadConfig 按扩展名读取 YAML 或 JSON 配置
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, config)
+	} else {
+		err = yaml.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Finding 一条检查结果
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Location 指向文档中出问题的节点，例如 "/paths/~1pets~1{id}/get/operationId"
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// Report 全部检查结果，按 Location 排序
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Count 返回指定严重程度的结果数
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Max 返回最高的严重程度，没有结果时返回 Off
+func (r *Report) Max() Severity {
+	max := Off
+	for _, f := range r.Findings {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max
+}
+
+// WriteJSON 输出 {"summary": {...}, "findings": [...]}
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	findings := r.Findings
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return enc.Encode(struct {
+		Summary  map[string]int `json:"summary"`
+		Findings []Finding      `json:"findings"`
+	}{
+		Summary: map[string]int{
+			Error.String():   r.Count(Error),
+			Warning.String(): r.Count(Warning),
+			Info.String():    r.Count(Info),
+		},
+		Findings: findings,
+	})
+}
+
+// WriteText 每行一个结果
+func (r *Report) WriteText(w io.Writer) error {
+	for _, f := range r.Findings {
+		if _, err := fmt.Fprintf(w, "%-7s %s: %s (%s)\n", f.Severity, f.Location, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d info\n", r.Count(Error), r.Count(Warning), r.Count(Info))
+	return err
+}
+
+// Lint 按 config 检查 swagger，config 可以为 nil
+func Lint(swagger *openapi3.T, config *Config) (*Report, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name] = true
+	}
+	for name := range config.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	pattern := config.OperationIDPattern
+	if pattern == "" {
+		pattern = DefaultOperationIDPattern
+	}
+	operationID, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("operationIdPattern: %w", err)
+	}
+
+	l := &linter{swagger: swagger, operationID: operationID}
+	for _, rule := range rules {
+		severity := rule.Severity
+		if override, ok := config.Rules[rule.Name]; ok {
+			severity = override
+		}
+		if severity == Off {
+			continue
+		}
+		l.rule, l.severity = rule.Name, severity
+		rule.check(l)
+	}
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Rule < b.Rule
+	})
+	return &Report{Findings: l.findings}, nil
+}
+
+type linter struct {
+	swagger     *openapi3.T
+	operationID *regexp.Regexp
+	findings    []Finding
+
+	rule     string
+	severity Severity
+}
+
+func (l *linter) report(location, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{
+		Rule:     l.rule,
+		Severity: l.severity,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// operation 按 path、method 排序遍历
+type operation struct {
+	path     string
+	method   string
+	item     *openapi3.PathItem
+	value    *openapi3.Operation
+	location string
+}
+
+func (l *linter) operations() []operation {
+	paths := make([]string, 0, len(l.swagger.Paths))
+	for path := range l.swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var ops []operation
+	for _, path := range paths {
+		item := l.swagger.Paths[path]
+		if item == nil {
+			continue
+		}
+		operations := item.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			ops = append(ops, operation{
+				path:     path,
+				method:   method,
+				item:     item,
+				value:    operations[method],
+				location: pointer.Join("paths", path, strings.ToLower(method)),
+			})
+		}
+	}
+	return ops
+}
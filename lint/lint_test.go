@@ -0,0 +1,225 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"demo/oapi-codegen-go/lint"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, data []byte) *openapi3.T {
+	t.Helper()
+	swagger, err := openapi3.NewLoader().LoadFromData(data)
+	require.NoError(t, err)
+	return swagger
+}
+
+func rules(report *lint.Report) map[string][]string {
+	result := make(map[string][]string)
+	for _, f := range report.Findings {
+		result[f.Rule] = append(result[f.Rule], f.Location)
+	}
+	return result
+}
+
+func TestDemo(t *testing.T) {
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	report, err := lint.Lint(load(t, data), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"operation-id-format": {"/paths/~1pets~1{id}/get/operationId"},
+		"response-4xx-missing": {
+			"/paths/~1pets/get/responses",
+			"/paths/~1pets/post/responses",
+			"/paths/~1pets~1{id}/delete/responses",
+			"/paths/~1pets~1{id}/get/responses",
+		},
+	}, rules(report))
+	assert.Equal(t, lint.Error, report.Max())
+	assert.Contains(t, report.Findings[3].Message, "FindPetById")
+}
+
+const spec = `
+openapi: "3.0.0"
+info: {title: t, version: "1"}
+servers:
+  - url: https://petstore.example.com/v1
+  - url: /api
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema: {type: integer}
+        - name: verbose
+          in: query
+          description: more fields
+          schema: {type: boolean}
+          example: yes please
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+              examples:
+                good: {value: {id: 1, name: cat}}
+                bad: {value: {id: one}}
+        '404':
+          description: missing
+  /pets/{petId}/photos:
+    get:
+      summary: photos
+      operationId: get-pet
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          description: the pet
+          schema: {type: integer}
+      responses:
+        '400':
+          description: bad
+  /owners:
+    get:
+      summary: owners
+      responses:
+        '400':
+          description: bad
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+        name: {type: string, example: 7}
+    Orphan:
+      type: string
+  parameters:
+    Unused:
+      name: q
+      in: query
+      schema: {type: string}
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-Key}
+`
+
+func TestRules(t *testing.T) {
+	report, err := lint.Lint(load(t, []byte(spec)), nil)
+	require.NoError(t, err)
+	got := rules(report)
+
+	assert.Equal(t, []string{"/paths/~1owners/get", "/paths/~1pets~1{petId}~1photos/get/operationId"}, got["operation-id-format"])
+	assert.Equal(t, []string{"/paths/~1pets~1{petId}~1photos/get/operationId"}, got["operation-id-unique"])
+	assert.ElementsMatch(t, []string{
+		"/components/parameters/Unused",
+		"/paths/~1pets~1{id}/get",
+		"/paths/~1pets~1{id}/get/parameters/0",
+	}, got["description-missing"])
+	assert.ElementsMatch(t, []string{
+		"/components/schemas/Pet/properties/name/example",
+		"/paths/~1pets~1{id}/get/parameters/1/example",
+		"/paths/~1pets~1{id}/get/responses/200/content/application~1json/examples/bad/value",
+	}, got["example-invalid"])
+	assert.ElementsMatch(t, []string{
+		"/components/parameters/Unused",
+		"/components/schemas/Orphan",
+		"/components/securitySchemes/apiKey",
+	}, got["component-unused"])
+	assert.Empty(t, got["response-4xx-missing"])
+	assert.Equal(t, []string{"/paths/~1pets~1{petId}~1photos"}, got["path-parameter-names"])
+	assert.Equal(t, []string{"/servers/0/url", "/servers/1/url"}, got["servers-host"])
+}
+
+func TestPathTemplateMismatch(t *testing.T) {
+	swagger := load(t, []byte(`
+openapi: "3.0.0"
+info: {title: t, version: "1"}
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      summary: get
+      parameters:
+        - {name: petId, in: path, required: true, description: d, schema: {type: integer}}
+      responses:
+        '404': {description: missing}
+`))
+	report, err := lint.Lint(swagger, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, "path parameter {id} is not declared", report.Findings[0].Message)
+	assert.Equal(t, "path parameter petId is declared but not in the path template", report.Findings[1].Message)
+}
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lint.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  response-4xx-missing: off
+  servers-host: info
+operationIdPattern: ^[a-z][A-Za-z0-9]*$
+`), 0o644))
+	config, err := lint.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, lint.Off, config.Rules["response-4xx-missing"])
+
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	swagger := load(t, data)
+	swagger.AddServer(&openapi3.Server{URL: "http://localhost:8080"})
+	report, err := lint.Lint(swagger, config)
+	require.NoError(t, err)
+	got := rules(report)
+	assert.NotContains(t, got, "response-4xx-missing")
+	assert.Len(t, got["operation-id-format"], 1)
+	assert.Equal(t, 1, report.Count(lint.Info))
+
+	_, err = lint.Lint(swagger, &lint.Config{Rules: map[string]lint.Severity{"no-such-rule": lint.Error}})
+	assert.EqualError(t, err, `unknown rule "no-such-rule"`)
+
+	_, err = lint.LoadConfig(writeFile(t, "bad.json", `{"rules": {"servers-host": "fatal"}}`))
+	assert.ErrorContains(t, err, `unknown severity "fatal"`)
+}
+
+func TestWriteJSON(t *testing.T) {
+	report, err := lint.Lint(load(t, []byte(spec)), nil)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+
+	var decoded struct {
+		Summary  map[string]int `json:"summary"`
+		Findings []struct {
+			Rule     string `json:"rule"`
+			Severity string `json:"severity"`
+			Location string `json:"location"`
+			Message  string `json:"message"`
+		} `json:"findings"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report.Count(lint.Error), decoded.Summary["error"])
+	assert.Equal(t, report.Count(lint.Warning), decoded.Summary["warning"])
+	require.Len(t, decoded.Findings, len(report.Findings))
+	assert.Equal(t, report.Findings[0].Severity.String(), decoded.Findings[0].Severity)
+	assert.Equal(t, report.Findings[0].Location, decoded.Findings[0].Location)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"strings"
+
+	"demo/oapi-codegen-go/internal/pointer"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// refs 从 paths 出发收集可达的 components，"#/components/schemas/Pet" 形式
+type refs struct {
+	used    map[string]bool
+	visited map[*openapi3.Schema]bool
+}
+
+func (l *linter) components() *openapi3.Components {
+	if l.swagger.Components == nil {
+		return &openapi3.Components{}
+	}
+	return l.swagger.Components
+}
+
+// mark 记录一个引用，第一次出现时返回 true，调用方随后遍历它的值
+func (r *refs) mark(ref string) bool {
+	if ref == "" {
+		return true
+	}
+	if !strings.HasPrefix(ref, "#/components/") || r.used[ref] {
+		return false
+	}
+	r.used[ref] = true
+	return true
+}
+
+func (r *refs) schema(ref *openapi3.SchemaRef) {
+	if ref == nil || !r.mark(ref.Ref) || ref.Value == nil || r.visited[ref.Value] {
+		return
+	}
+	s := ref.Value
+	r.visited[s] = true
+	for _, prop := range s.Properties {
+		r.schema(prop)
+	}
+	r.schema(s.Items)
+	r.schema(s.Not)
+	if s.AdditionalProperties.Schema != nil {
+		r.schema(s.AdditionalProperties.Schema)
+	}
+	for _, subs := range []openapi3.SchemaRefs{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, sub := range subs {
+			r.schema(sub)
+		}
+	}
+}
+
+func (r *refs) parameter(ref *openapi3.ParameterRef) {
+	if ref == nil || !r.mark(ref.Ref) || ref.Value == nil {
+		return
+	}
+	r.schema(ref.Value.Schema)
+	r.content(ref.Value.Content)
+	r.examples(ref.Value.Examples)
+}
+
+func (r *refs) header(ref *openapi3.HeaderRef) {
+	if ref == nil || !r.mark(ref.Ref) || ref.Value == nil {
+		return
+	}
+	r.schema(ref.Value.Schema)
+	r.content(ref.Value.Content)
+	r.examples(ref.Value.Examples)
+}
+
+func (r *refs) requestBody(ref *openapi3.RequestBodyRef) {
+	if ref == nil || !r.mark(ref.Ref) || ref.Value == nil {
+		return
+	}
+	r.content(ref.Value.Content)
+}
+
+func (r *refs) response(ref *openapi3.ResponseRef) {
+	if ref == nil || !r.mark(ref.Ref) || ref.Value == nil {
+		return
+	}
+	r.content(ref.Value.Content)
+	for _, header := range ref.Value.Headers {
+		r.header(header)
+	}
+	for _, link := range ref.Value.Links {
+		if link != nil {
+			r.mark(link.Ref)
+		}
+	}
+}
+
+func (r *refs) content(content openapi3.Content) {
+	for _, mt := range content {
+		if mt == nil {
+			continue
+		}
+		r.schema(mt.Schema)
+		r.examples(mt.Examples)
+		for _, encoding := range mt.Encoding {
+			if encoding != nil {
+				for _, header := range encoding.Headers {
+					r.header(header)
+				}
+			}
+		}
+	}
+}
+
+func (r *refs) examples(examples openapi3.Examples) {
+	for _, example := range examples {
+		if example != nil {
+			r.mark(example.Ref)
+		}
+	}
+}
+
+func (r *refs) pathItem(item *openapi3.PathItem) {
+	if item == nil {
+		return
+	}
+	for _, param := range item.Parameters {
+		r.parameter(param)
+	}
+	for _, op := range item.Operations() {
+		for _, param := range op.Parameters {
+			r.parameter(param)
+		}
+		r.requestBody(op.RequestBody)
+		for _, resp := range op.Responses {
+			r.response(resp)
+		}
+		for _, callback := range op.Callbacks {
+			if callback == nil || !r.mark(callback.Ref) || callback.Value == nil {
+				continue
+			}
+			for _, item := range *callback.Value {
+				r.pathItem(item)
+			}
+		}
+	}
+}
+
+func checkUnusedComponents(l *linter) {
+	r := &refs{used: make(map[string]bool), visited: make(map[*openapi3.Schema]bool)}
+	for _, item := range l.swagger.Paths {
+		r.pathItem(item)
+	}
+	securityNames := make(map[string]bool)
+	requirements := append(openapi3.SecurityRequirements(nil), l.swagger.Security...)
+	for _, op := range l.operations() {
+		if op.value.Security != nil {
+			requirements = append(requirements, *op.value.Security...)
+		}
+	}
+	for _, requirement := range requirements {
+		for name := range requirement {
+			securityNames[name] = true
+		}
+	}
+
+	c := l.components()
+	unused := func(kind string, names []string) {
+		for _, name := range names {
+			if !r.used["#/components/"+kind+"/"+name] {
+				l.report(pointer.Join("components", kind, name), "%s %s is never referenced", kind, name)
+			}
+		}
+	}
+	unused("schemas", sortedKeys(c.Schemas))
+	unused("parameters", sortedKeys(c.Parameters))
+	unused("headers", sortedKeys(c.Headers))
+	unused("requestBodies", sortedKeys(c.RequestBodies))
+	unused("responses", sortedKeys(c.Responses))
+	unused("examples", sortedKeys(c.Examples))
+	unused("links", sortedKeys(c.Links))
+	unused("callbacks", sortedKeys(c.Callbacks))
+	for _, name := range sortedKeys(c.SecuritySchemes) {
+		if !securityNames[name] {
+			l.report(pointer.Join("components", "securitySchemes", name), "securitySchemes %s is not used by any security requirement", name)
+		}
+	}
+}
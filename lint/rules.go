@@ -0,0 +1,325 @@
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"demo/oapi-codegen-go/internal/pointer"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var rules = []Rule{
+	{
+		Name:        "operation-id-format",
+		Description: "每个 operation 都有 operationId，且符合 operationIdPattern；否则生成的方法名会被静默改写",
+		Severity:    Error,
+		check:       checkOperationIDFormat,
+	},
+	{
+		Name:        "operation-id-unique",
+		Description: "operationId 在转换成 Go 方法名后仍然唯一",
+		Severity:    Error,
+		check:       checkOperationIDUnique,
+	},
+	{
+		Name:        "description-missing",
+		Description: "operation 有 summary 或 description，参数有 description",
+		Severity:    Warning,
+		check:       checkDescriptions,
+	},
+	{
+		Name:        "example-invalid",
+		Description: "example/examples 能通过对应 schema 的校验",
+		Severity:    Error,
+		check:       checkExamples,
+	},
+	{
+		Name:        "component-unused",
+		Description: "components 中的每一项都被某个 operation 直接或间接引用",
+		Severity:    Warning,
+		check:       checkUnusedComponents,
+	},
+	{
+		Name:        "response-4xx-missing",
+		Description: "operation 声明了至少一个 4xx 响应，default 不算",
+		Severity:    Warning,
+		check:       checkClientErrors,
+	},
+	{
+		Name:        "path-parameter-names",
+		Description: "path 参数与模板一致，同一位置的参数在不同 path 中同名",
+		Severity:    Warning,
+		check:       checkPathParameters,
+	},
+	{
+		Name:        "servers-host",
+		Description: "servers 会让请求校验器按 Host 和前缀匹配，见 oapi-codegen#882",
+		Severity:    Warning,
+		check:       checkServers,
+	},
+}
+
+func checkOperationIDFormat(l *linter) {
+	for _, op := range l.operations() {
+		id := op.value.OperationID
+		switch {
+		case id == "":
+			l.report(op.location, "%s %s has no operationId", op.method, op.path)
+		case !l.operationID.MatchString(id):
+			l.report(pointer.Join("paths", op.path, strings.ToLower(op.method), "operationId"),
+				"operationId %q does not match %s, codegen renames it to %s", id, l.operationID, goName(id))
+		}
+	}
+}
+
+func checkOperationIDUnique(l *linter) {
+	seen := make(map[string]operation)
+	for _, op := range l.operations() {
+		id := op.value.OperationID
+		if id == "" {
+			continue
+		}
+		name := goName(id)
+		if first, ok := seen[name]; ok {
+			l.report(pointer.Join("paths", op.path, strings.ToLower(op.method), "operationId"),
+				"operationId %q collides with %q (%s %s), both generate %s",
+				id, first.value.OperationID, first.method, first.path, name)
+			continue
+		}
+		seen[name] = op
+	}
+}
+
+// goName 与 oapi-codegen 的 ToCamelCase 一致：按非字母数字切分，每段首字母大写
+func goName(id string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range id {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func checkDescriptions(l *linter) {
+	for _, op := range l.operations() {
+		if op.value.Summary == "" && op.value.Description == "" {
+			l.report(op.location, "%s %s has neither summary nor description", op.method, op.path)
+		}
+		for i, ref := range op.value.Parameters {
+			if ref.Ref == "" && ref.Value != nil && ref.Value.Description == "" {
+				l.report(pointer.Join("paths", op.path, strings.ToLower(op.method), "parameters", fmt.Sprint(i)),
+					"parameter %s.%s has no description", ref.Value.In, ref.Value.Name)
+			}
+		}
+	}
+	for _, name := range sortedKeys(l.components().Parameters) {
+		ref := l.components().Parameters[name]
+		if ref.Value != nil && ref.Value.Description == "" {
+			l.report(pointer.Join("components", "parameters", name), "parameter %s.%s has no description", ref.Value.In, ref.Value.Name)
+		}
+	}
+}
+
+func checkExamples(l *linter) {
+	for _, name := range sortedKeys(l.components().Schemas) {
+		l.schemaExamples(pointer.Join("components", "schemas", name), l.components().Schemas[name], map[*openapi3.Schema]bool{})
+	}
+	for _, op := range l.operations() {
+		for i, ref := range op.value.Parameters {
+			if ref.Ref == "" && ref.Value != nil {
+				location := pointer.Join("paths", op.path, strings.ToLower(op.method), "parameters", fmt.Sprint(i))
+				l.examples(location, ref.Value.Schema, ref.Value.Example, ref.Value.Examples)
+			}
+		}
+		if body := op.value.RequestBody; body != nil && body.Ref == "" && body.Value != nil {
+			l.contentExamples(pointer.Join("paths", op.path, strings.ToLower(op.method), "requestBody", "content"), body.Value.Content)
+		}
+		for _, status := range sortedKeys(op.value.Responses) {
+			resp := op.value.Responses[status]
+			if resp.Ref == "" && resp.Value != nil {
+				l.contentExamples(pointer.Join("paths", op.path, strings.ToLower(op.method), "responses", status, "content"), resp.Value.Content)
+			}
+		}
+	}
+}
+
+func (l *linter) contentExamples(location string, content openapi3.Content) {
+	for _, mediaType := range sortedKeys(content) {
+		mt := content[mediaType]
+		if mt != nil {
+			l.examples(location+pointer.Join(mediaType), mt.Schema, mt.Example, mt.Examples)
+		}
+	}
+}
+
+func (l *linter) examples(location string, schema *openapi3.SchemaRef, example interface{}, examples openapi3.Examples) {
+	if schema == nil || schema.Value == nil {
+		return
+	}
+	if example != nil {
+		l.validateExample(location+pointer.Join("example"), schema.Value, example)
+	}
+	for _, name := range sortedKeys(examples) {
+		ref := examples[name]
+		if ref != nil && ref.Value != nil && ref.Value.Value != nil {
+			l.validateExample(location+pointer.Join("examples", name, "value"), schema.Value, ref.Value.Value)
+		}
+	}
+	if schema.Ref == "" {
+		l.schemaExamples(location+pointer.Join("schema"), schema, map[*openapi3.Schema]bool{})
+	}
+}
+
+// schemaExamples 检查 schema 自身及内联子 schema 的 example，$ref 指向的 schema 在 components 中检查
+func (l *linter) schemaExamples(location string, ref *openapi3.SchemaRef, visited map[*openapi3.Schema]bool) {
+	if ref == nil || ref.Value == nil || visited[ref.Value] {
+		return
+	}
+	schema := ref.Value
+	visited[schema] = true
+	if schema.Example != nil {
+		l.validateExample(location+pointer.Join("example"), schema, schema.Example)
+	}
+	inline := func(location string, sub *openapi3.SchemaRef) {
+		if sub != nil && sub.Ref == "" {
+			l.schemaExamples(location, sub, visited)
+		}
+	}
+	for _, name := range sortedKeys(schema.Properties) {
+		inline(location+pointer.Join("properties", name), schema.Properties[name])
+	}
+	inline(location+pointer.Join("items"), schema.Items)
+	for kind, subs := range map[string]openapi3.SchemaRefs{"allOf": schema.AllOf, "oneOf": schema.OneOf, "anyOf": schema.AnyOf} {
+		for i, sub := range subs {
+			inline(location+pointer.Join(kind, fmt.Sprint(i)), sub)
+		}
+	}
+}
+
+func (l *linter) validateExample(location string, schema *openapi3.Schema, example interface{}) {
+	if err := schema.VisitJSON(example, openapi3.MultiErrors()); err != nil {
+		l.report(location, "example does not match the schema: %s", strings.ReplaceAll(err.Error(), "\n", " "))
+	}
+}
+
+func checkClientErrors(l *linter) {
+	for _, op := range l.operations() {
+		found := false
+		for status := range op.value.Responses {
+			found = found || strings.HasPrefix(status, "4")
+		}
+		if !found {
+			l.report(pointer.Join("paths", op.path, strings.ToLower(op.method), "responses"),
+				"%s %s declares no 4xx response, default alone does not document client errors", op.method, op.path)
+		}
+	}
+}
+
+func checkPathParameters(l *linter) {
+	// 同一位置（按前缀归一化）上出现过的参数名
+	names := make(map[string]map[string][]string)
+	for _, path := range sortedKeys(l.swagger.Paths) {
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if name, ok := templateParam(segment); ok {
+				key := normalize(segments[:i+1])
+				if names[key] == nil {
+					names[key] = make(map[string][]string)
+				}
+				names[key][name] = append(names[key][name], path)
+			}
+		}
+	}
+	for _, key := range sortedKeys(names) {
+		if len(names[key]) < 2 {
+			continue
+		}
+		variants := sortedKeys(names[key])
+		for _, name := range variants[1:] {
+			for _, path := range names[key][name] {
+				l.report(pointer.Join("paths", path), "path parameter {%s} is named {%s} in %s",
+					name, variants[0], strings.Join(names[key][variants[0]], ", "))
+			}
+		}
+	}
+
+	for _, op := range l.operations() {
+		declared := make(map[string]bool)
+		for _, refs := range []openapi3.Parameters{op.item.Parameters, op.value.Parameters} {
+			for _, ref := range refs {
+				if ref.Value != nil && ref.Value.In == openapi3.ParameterInPath {
+					declared[ref.Value.Name] = true
+				}
+			}
+		}
+		inTemplate := make(map[string]bool)
+		for _, segment := range strings.Split(op.path, "/") {
+			if name, ok := templateParam(segment); ok {
+				inTemplate[name] = true
+				if !declared[name] {
+					l.report(op.location, "path parameter {%s} is not declared", name)
+				}
+			}
+		}
+		for _, name := range sortedKeys(declared) {
+			if !inTemplate[name] {
+				l.report(op.location, "path parameter %s is declared but not in the path template", name)
+			}
+		}
+	}
+}
+
+func templateParam(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func normalize(segments []string) string {
+	normalized := make([]string, len(segments))
+	for i, segment := range segments {
+		if _, ok := templateParam(segment); ok {
+			segment = "{}"
+		}
+		normalized[i] = segment
+	}
+	return strings.Join(normalized, "/")
+}
+
+func checkServers(l *linter) {
+	for i, server := range l.swagger.Servers {
+		if server == nil {
+			continue
+		}
+		location := pointer.Join("servers", fmt.Sprint(i), "url")
+		u, err := url.Parse(server.URL)
+		if err != nil || u.Host == "" {
+			l.report(location, "server %q makes the request validator require the path prefix; "+
+				"mount the routes under it or clear swagger.Servers", server.URL)
+			continue
+		}
+		l.report(location, "server %q makes the request validator reject requests whose Host is not %s "+
+			"with \"no matching operation was found\"; clear swagger.Servers before OapiRequestValidator", server.URL, u.Host)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,247 @@
+// Package mock 根据任意 OpenAPI 文档启动一个假的服务端：每个 operation 都返回
+// 文档中声明的 example/examples，没有示例时按 schema 合成。
+// 请求先经过 middleware.OapiRequestValidator 校验。
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/example"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	PreferHeader            = "Prefer"
+	PreferenceAppliedHeader = "Preference-Applied"
+)
+
+// Options 控制 mock 行为
+type Options struct {
+	// KeepServers 为 false 时清空 servers，所有 path 直接挂在根路径下，
+	// 避免校验器按 Host 匹配 servers 导致 404；为 true 时路由挂在 servers[0] 的 path 下，
+	// servers 只保留 scheme 与 host，规则同 codegenTest.MountOptions
+	KeepServers bool
+	// DisableValidation 关闭请求校验
+	DisableValidation bool
+}
+
+// New 为 swagger 中的每个 operation 注册路由，路由与校验使用同一份挂载后的文档。
+// swagger 不会被修改。
+func New(swagger *openapi3.T, options Options) (*echo.Echo, error) {
+	baseURL := ""
+	if options.KeepServers && len(swagger.Servers) > 0 {
+		var err error
+		if baseURL, err = swagger.Servers[0].BasePath(); err != nil {
+			return nil, fmt.Errorf("server %q: %w", swagger.Servers[0].URL, err)
+		}
+	}
+	mounted := *swagger
+	if err := codegenTest.Mount(&mounted, baseURL, &codegenTest.MountOptions{KeepServers: options.KeepServers}); err != nil {
+		return nil, err
+	}
+	e := echo.New()
+	e.HideBanner = true
+	if !options.DisableValidation {
+		e.Use(middleware.OapiRequestValidatorWithOptions(&mounted, &middleware.Options{SilenceServersWarning: true}))
+	}
+
+	paths := make([]string, 0, len(mounted.Paths))
+	for path := range mounted.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for method, operation := range mounted.Paths[path].Operations() {
+			e.Add(method, echoPath(path), handler(operation))
+		}
+	}
+	return e, nil
+}
+
+// echoPath 把 /pets/{id} 转换成 Echo 的 /pets/:id，字面量中的 : 转义为 \:，
+// 否则 /pets:bulk 会被当作 /pets 后面跟着参数 bulk
+func echoPath(path string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(escapeColon(path))
+			return b.String()
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			b.WriteString(escapeColon(path))
+			return b.String()
+		}
+		b.WriteString(escapeColon(path[:start]))
+		b.WriteByte(':')
+		b.WriteString(path[start+1 : start+end])
+		path = path[start+end+1:]
+	}
+}
+
+func escapeColon(s string) string {
+	return strings.ReplaceAll(s, ":", "\\:")
+}
+
+func handler(operation *openapi3.Operation) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		prefer := parsePrefer(c.Request().Header.Values(PreferHeader))
+		var applied []string
+
+		status, response, ok := selectResponse(operation.Responses, prefer["code"])
+		if ok {
+			applied = append(applied, "code="+prefer["code"])
+		}
+		var contentType string
+		var media *openapi3.MediaType
+		var body interface{}
+		if response != nil {
+			contentType, media = selectMediaType(response.Content, c.Request().Header.Get(echo.HeaderAccept))
+		}
+		if media != nil {
+			if body, ok = selectExample(media, prefer["example"]); ok {
+				applied = append(applied, "example="+prefer["example"])
+			}
+		}
+		if len(applied) > 0 {
+			c.Response().Header().Set(PreferenceAppliedHeader, strings.Join(applied, ", "))
+		}
+
+		if media == nil {
+			return c.NoContent(status)
+		}
+		if s, isString := body.(string); isString && !strings.Contains(contentType, "json") {
+			return c.Blob(status, contentType, []byte(s))
+		}
+		co, ok := codec.Lookup(contentType)
+		if codec.IsNDJSON(contentType) {
+			co, ok = codec.NDJSON, true
+		}
+		if ok && co.MediaType() != codec.MIMEApplicationJSON {
+			data, err := co.Marshal(body)
+			if err != nil {
+				return err
+			}
+			return c.Blob(status, contentType, data)
+		}
+		c.Response().Header().Set(echo.HeaderContentType, contentType)
+		return c.JSON(status, body)
+	}
+}
+
+// parsePrefer 解析 Prefer: code=404, example=cat，值可带引号
+func parsePrefer(values []string) map[string]string {
+	prefs := make(map[string]string)
+	for _, value := range values {
+		for _, token := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			k, v, _ := strings.Cut(strings.TrimSpace(token), "=")
+			prefs[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return prefs
+}
+
+// selectResponse 选择响应：Prefer 指定的状态码优先（未声明时退回 default），
+// 否则取最小的 2xx，再否则取 default。返回的 response 为 nil 表示没有可用响应。
+func selectResponse(responses openapi3.Responses, preferCode string) (int, *openapi3.Response, bool) {
+	if code, err := strconv.Atoi(preferCode); err == nil && code >= 100 && code <= 599 {
+		if ref := responses.Get(code); ref != nil {
+			return code, ref.Value, true
+		}
+		if ref := responses.Default(); ref != nil {
+			return code, ref.Value, true
+		}
+	}
+
+	codes := make([]int, 0, len(responses))
+	for key := range responses {
+		if code, err := strconv.Atoi(key); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, responses.Get(code).Value, false
+		}
+	}
+	if len(codes) > 0 {
+		return codes[0], responses.Get(codes[0]).Value, false
+	}
+	if ref := responses.Default(); ref != nil {
+		return http.StatusOK, ref.Value, false
+	}
+	return http.StatusNotImplemented, nil, false
+}
+
+// selectMediaType 按 Accept 选择媒体类型，不匹配时优先 JSON
+func selectMediaType(content openapi3.Content, accept string) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if mediaRange == "" || mediaRange == "*/*" {
+			continue
+		}
+		if media := content.Get(mediaRange); media != nil {
+			return mediaRange, media
+		}
+	}
+	if media := content.Get(echo.MIMEApplicationJSON); media != nil {
+		return echo.MIMEApplicationJSON, media
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types[0], content[types[0]]
+}
+
+// selectExample 优先使用 Prefer 指定名称的 example，其次 example、
+// 按名称排序的第一个 examples，最后按 schema 合成
+func selectExample(media *openapi3.MediaType, name string) (interface{}, bool) {
+	if name != "" {
+		if ref, ok := media.Examples[name]; ok && ref.Value != nil {
+			return ref.Value.Value, true
+		}
+	}
+	if media.Example != nil {
+		return media.Example, false
+	}
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for n := range media.Examples {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if ref := media.Examples[n]; ref.Value != nil {
+				return ref.Value.Value, false
+			}
+		}
+	}
+	return synthesize(media.Schema), false
+}
+
+// synthesize 按 schema 生成示例值，固定 seed 使同一个 operation 的响应保持不变
+func synthesize(ref *openapi3.SchemaRef) interface{} {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	v, err := example.New(example.Options{Direction: example.Response}).Generate(ref.Value)
+	if err != nil {
+		return nil
+	}
+	return v
+}
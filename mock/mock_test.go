@@ -0,0 +1,198 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadDemo(t *testing.T) *openapi3.T {
+	swagger, err := openapi3.NewLoader().LoadFromFile("../demo.yaml")
+	require.NoError(t, err)
+	return swagger
+}
+
+// serveAndValidate 发起请求，并用 openapi3filter 校验 mock 返回的响应符合文档
+func serveAndValidate(t *testing.T, swagger *openapi3.T, req *http.Request) *httptest.ResponseRecorder {
+	e, err := New(swagger, Options{})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	// 与 New 一样按清空 servers 后的文档查找 operation
+	mounted := *swagger
+	mounted.Servers = nil
+	router, err := gorillamux.NewRouter(&mounted)
+	require.NoError(t, err)
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err)
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: pathParams, Route: route},
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(strings.NewReader(rec.Body.String())),
+	})
+	require.NoError(t, err, rec.Body.String())
+	return rec
+}
+
+func TestSynthesizedResponses(t *testing.T) {
+	swagger := loadDemo(t)
+
+	rec := serveAndValidate(t, swagger, httptest.NewRequest(http.MethodGet, "/pets?limit=15", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var pets []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pets))
+	require.Len(t, pets, 1)
+	// allOf 合并了 NewPet 与 id
+	assert.Contains(t, pets[0], "id")
+	assert.Contains(t, pets[0], "name")
+
+	req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"baby"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = serveAndValidate(t, swagger, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveAndValidate(t, swagger, httptest.NewRequest(http.MethodDelete, "/pets/1", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestPreferCode(t *testing.T) {
+	swagger := loadDemo(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+	req.Header.Set(PreferHeader, "code=404")
+	rec := serveAndValidate(t, swagger, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "code=404", rec.Header().Get(PreferenceAppliedHeader))
+	var body struct {
+		Code    int32  `json:"code"`
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotZero(t, body.Code)
+	assert.NotEmpty(t, body.Message)
+	// 同一个 operation 每次返回相同的合成结果
+	again := serveAndValidate(t, swagger, req)
+	assert.Equal(t, rec.Body.String(), again.Body.String())
+}
+
+func TestRequestValidation(t *testing.T) {
+	e, err := New(loadDemo(t), Options{})
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pets?limit=100", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+const examplesSpec = `
+openapi: "3.0.0"
+info: {title: examples, version: "1.0"}
+servers:
+  - url: https://petstore.example.com/v1
+paths:
+  /pets/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: pet
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name: {type: string}
+              examples:
+                cat: {value: {name: Tom}}
+                dog: {value: {name: Spike}}
+        "404":
+          description: missing
+          content:
+            application/json:
+              example: {message: gone}
+`
+
+func TestPreferExample(t *testing.T) {
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(examplesSpec))
+	require.NoError(t, err)
+
+	// servers 默认被清空，路径直接挂在根路径下
+	rec := serveAndValidate(t, swagger, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+	assert.JSONEq(t, `{"name":"Tom"}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+	req.Header.Set(PreferHeader, `example="dog"`)
+	rec = serveAndValidate(t, swagger, req)
+	assert.JSONEq(t, `{"name":"Spike"}`, rec.Body.String())
+	assert.Equal(t, "example=dog", rec.Header().Get(PreferenceAppliedHeader))
+
+	req = httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+	req.Header.Set(PreferHeader, "code=404")
+	rec = serveAndValidate(t, swagger, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"message":"gone"}`, rec.Body.String())
+}
+
+func TestCustomMethodPaths(t *testing.T) {
+	swagger := loadDemo(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/pets:bulk", strings.NewReader(`[{"name":"rex"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serveAndValidate(t, swagger, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "created")
+
+	req = httptest.NewRequest(http.MethodGet, "/pets:export", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = serveAndValidate(t, swagger, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// : 是字面量，不能当作参数匹配任意后缀
+	e, err := New(swagger, Options{DisableValidation: true})
+	require.NoError(t, err)
+	for _, target := range []string{"/petsXYZ", "/pets:import"} {
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+}
+
+func TestKeepServers(t *testing.T) {
+	swagger := loadDemo(t)
+	swagger.Servers = openapi3.Servers{{
+		URL:       "https://example.com/{basePath}",
+		Variables: map[string]*openapi3.ServerVariable{"basePath": {Default: "v2"}},
+	}}
+	e, err := New(swagger, Options{KeepServers: true})
+	require.NoError(t, err)
+
+	// 路由与校验器都在 servers[0] 的 path 下
+	for target, want := range map[string]int{
+		"https://example.com/v2/pets/1":      http.StatusOK,
+		"https://example.com/v2/pets:export": http.StatusOK,
+		"https://example.com/pets/1":         http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, want, rec.Code, target)
+	}
+
+	// 调用方的文档不变
+	assert.Equal(t, "https://example.com/{basePath}", swagger.Servers[0].URL)
+	assert.Contains(t, swagger.Paths, "/pets/{id}")
+	assert.NotContains(t, swagger.Paths, "/v2/pets/{id}")
+}
@@ -0,0 +1,124 @@
+package codegen_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MountOptions 控制 MountSwagger 如何改写 servers
+type MountOptions struct {
+	// KeepServers 为 true 时保留文档中的 servers：变量按 ServerVariables 或默认值展开，
+	// 去掉 path 部分（已经由 baseURL 接管），只保留 scheme 与 host。
+	// 为 false 时清空 servers，校验器不再检查 Host，见 oapi-codegen#882。
+	// baseURL 带 host 时忽略此项，servers 只包含 baseURL 的 scheme 与 host。
+	KeepServers bool
+	// ServerVariables 覆盖 servers[].variables 的默认值，值必须在 enum 中
+	ServerVariables map[string]string
+}
+
+// MountSwagger 返回挂载在 baseURL 下的文档副本，baseURL 可以是 "/api" 或
+// "https://petstore.example.com/api"。paths 加上 baseURL 的 path 前缀，与
+// RegisterHandlersWithBaseURL(router, si, "/api") 注册的路由一致；servers 按
+// MountOptions 改写。每次调用返回独立的文档，并已通过 Validate。
+func MountSwagger(baseURL string, options *MountOptions) (*openapi3.T, error) {
+	// 每次从已经解码的 rawSpec 重新加载，副本之间不共享任何数据（包括 Schema 内部编译 pattern 等缓存）
+	swagger, err := GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+	if err := Mount(swagger, baseURL, options); err != nil {
+		return nil, err
+	}
+	return swagger, nil
+}
+
+// GetSwaggerWithPrefix 等价于 MountSwagger(pathPrefix, nil)：paths 加前缀、清空 servers，
+// 返回独立的文档
+func GetSwaggerWithPrefix(pathPrefix string) (swagger *openapi3.T, err error) {
+	return MountSwagger(pathPrefix, nil)
+}
+
+// Mount 按 MountSwagger 的规则改写任意文档并重新 Validate。只替换 swagger.Paths 与
+// swagger.Servers，不修改 PathItem 等内容，传入浅拷贝（copied := *swagger）即可保持原文档不变。
+func Mount(swagger *openapi3.T, baseURL string, options *MountOptions) error {
+	if options == nil {
+		options = &MountOptions{}
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+
+	prefix := strings.TrimSuffix(base.Path, "/")
+	paths := make(openapi3.Paths, len(swagger.Paths))
+	for key, item := range swagger.Paths {
+		paths[prefix+key] = item
+	}
+	swagger.Paths = paths
+
+	switch {
+	case base.Host != "":
+		swagger.Servers = openapi3.Servers{{URL: base.Scheme + "://" + base.Host}}
+	case options.KeepServers:
+		swagger.Servers, err = serverOrigins(swagger.Servers, options.ServerVariables)
+		if err != nil {
+			return err
+		}
+	default:
+		swagger.Servers = nil
+	}
+
+	if err := swagger.Validate(context.Background()); err != nil {
+		return fmt.Errorf("mounted spec is invalid: %w", err)
+	}
+	return nil
+}
+
+// serverOrigins 展开变量并只保留 scheme://host，相同的 origin 只保留一个
+func serverOrigins(servers openapi3.Servers, variables map[string]string) (openapi3.Servers, error) {
+	var origins openapi3.Servers
+	seen := make(map[string]bool)
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		expanded := server.URL
+		for name, variable := range server.Variables {
+			value := variable.Default
+			if override, ok := variables[name]; ok {
+				if len(variable.Enum) > 0 && !containsString(variable.Enum, override) {
+					return nil, fmt.Errorf("server %q: variable %s must be one of %v, got %q", server.URL, name, variable.Enum, override)
+				}
+				value = override
+			}
+			expanded = strings.ReplaceAll(expanded, "{"+name+"}", value)
+		}
+		u, err := url.Parse(expanded)
+		if err != nil {
+			return nil, fmt.Errorf("server %q: %w", server.URL, err)
+		}
+		if u.Host == "" {
+			// 相对地址只有 path 部分，挂载后没有需要保留的内容
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, &openapi3.Server{URL: origin, Description: server.Description})
+		}
+	}
+	return origins, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
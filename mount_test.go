@@ -0,0 +1,103 @@
+package codegen_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountSwagger(t *testing.T) {
+	swagger, err := MountSwagger("/api/", nil)
+	require.NoError(t, err)
+	assert.Contains(t, swagger.Paths, "/api/pets")
+	assert.Contains(t, swagger.Paths, "/api/pets/{id}")
+	assert.Nil(t, swagger.Servers)
+
+	swagger, err = MountSwagger("https://petstore.example.com/v2", nil)
+	require.NoError(t, err)
+	assert.Contains(t, swagger.Paths, "/v2/pets")
+	require.Len(t, swagger.Servers, 1)
+	assert.Equal(t, "https://petstore.example.com", swagger.Servers[0].URL)
+
+	_, err = MountSwagger("http://[::1", nil)
+	assert.Error(t, err)
+}
+
+func TestMountShallowCopy(t *testing.T) {
+	original, err := GetSwagger()
+	require.NoError(t, err)
+	original.Servers = openapi3.Servers{{URL: "https://petstore.example.com/v1"}}
+
+	mounted := *original
+	require.NoError(t, Mount(&mounted, "/v1", &MountOptions{KeepServers: true}))
+	assert.Contains(t, mounted.Paths, "/v1/pets")
+	assert.Equal(t, "https://petstore.example.com", mounted.Servers[0].URL)
+	assert.Contains(t, original.Paths, "/pets")
+	assert.Equal(t, "https://petstore.example.com/v1", original.Servers[0].URL)
+}
+
+func TestMountSwaggerIsolation(t *testing.T) {
+	a, err := MountSwagger("/a", nil)
+	require.NoError(t, err)
+	b, err := MountSwagger("/b", nil)
+	require.NoError(t, err)
+
+	max := 1.0
+	a.Components.Schemas["NewPet"].Value.Properties["name"].Value.MaxLength = new(uint64)
+	a.Paths["/a/pets"].Get.Parameters[1].Value.Schema.Value.Max = &max
+	a.Paths["/a/pets"].Get.OperationID = "changed"
+
+	assert.Nil(t, b.Components.Schemas["NewPet"].Value.Properties["name"].Value.MaxLength)
+	assert.NotEqual(t, &max, b.Paths["/b/pets"].Get.Parameters[1].Value.Schema.Value.Max)
+	assert.Equal(t, "FindPets", b.Paths["/b/pets"].Get.OperationID)
+
+	original, err := GetSwagger()
+	require.NoError(t, err)
+	assert.Equal(t, "FindPets", original.Paths["/pets"].Get.OperationID)
+	assert.Nil(t, original.Components.Schemas["NewPet"].Value.Properties["name"].Value.MaxLength)
+
+	// 副本内部保持 $ref 的共享：Pet 的 allOf 指向同一个 NewPet
+	assert.Same(t, a.Components.Schemas["NewPet"].Value, a.Components.Schemas["Pet"].Value.AllOf[0].Value)
+	assert.NotSame(t, a.Components.Schemas["NewPet"].Value, b.Components.Schemas["NewPet"].Value)
+}
+
+func TestMountSwaggerConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, prefix := range []string{"/a", "/b", "/c", "/d"} {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			swagger, err := MountSwagger(prefix, nil)
+			if assert.NoError(t, err) {
+				swagger.Paths[prefix+"/pets"].Get.Description = prefix
+			}
+		}(prefix)
+	}
+	wg.Wait()
+}
+
+func TestServerOrigins(t *testing.T) {
+	servers := openapi3.Servers{
+		{
+			URL: "{scheme}://{region}.petstore.example.com/{basePath}",
+			Variables: map[string]*openapi3.ServerVariable{
+				"scheme":   {Default: "https", Enum: []string{"https", "http"}},
+				"region":   {Default: "eu"},
+				"basePath": {Default: "v2"},
+			},
+		},
+		{URL: "https://eu.petstore.example.com/v1"},
+		{URL: "/v2"},
+	}
+	origins, err := serverOrigins(servers, map[string]string{"region": "us"})
+	require.NoError(t, err)
+	require.Len(t, origins, 2)
+	assert.Equal(t, "https://us.petstore.example.com", origins[0].URL)
+	assert.Equal(t, "https://eu.petstore.example.com", origins[1].URL)
+
+	_, err = serverOrigins(servers, map[string]string{"scheme": "ftp"})
+	assert.ErrorContains(t, err, "variable scheme must be one of")
+}
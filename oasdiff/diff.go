@@ -0,0 +1,274 @@
+// Package oasdiff 比较两个 OpenAPI 文档，并从已生成客户端的角度把每个变化分为
+// breaking 与 non-breaking。
+//
+// 基本原则：请求一侧收紧约束（新增必填参数、缩小 minimum/maximum、删除枚举值）会让
+// 原来合法的请求被拒绝；响应一侧放宽约束（删除字段、必填变可选、新增枚举值）会让
+// 客户端收到无法处理的数据；类型变化和删除 operation 在两侧都是 breaking。
+package oasdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Level 变化的严重程度
+type Level int
+
+const (
+	NonBreaking Level = iota
+	Breaking
+)
+
+func (l Level) String() string {
+	if l == Breaking {
+		return "breaking"
+	}
+	return "non-breaking"
+}
+
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case Breaking.String():
+		*l = Breaking
+	case NonBreaking.String():
+		*l = NonBreaking
+	default:
+		return fmt.Errorf("unknown level %q", s)
+	}
+	return nil
+}
+
+// Change 一处变化
+type Change struct {
+	// ID 变化的种类，例如 "operation-removed"、"request-parameter-became-required"
+	ID          string `json:"id"`
+	Level       Level  `json:"level"`
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path,omitempty"`
+	OperationID string `json:"operationId,omitempty"`
+	// Location 变化在 operation 内的位置，例如 "query.limit"、"response.200.body.id"
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report 全部变化，按 path、method、location 排序
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking 返回 breaking 的变化
+func (r *Report) Breaking() []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Level == Breaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// HasBreaking 是否存在 breaking 的变化
+func (r *Report) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// Compare 比较 base 与 revision
+func Compare(base, revision *openapi3.T) *Report {
+	d := &differ{}
+	for _, path := range unionKeys(base.Paths, revision.Paths) {
+		d.comparePath(path, base.Paths[path], revision.Paths[path])
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		a, b := d.changes[i], d.changes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Location < b.Location
+	})
+	return &Report{Changes: d.changes}
+}
+
+type differ struct {
+	changes []Change
+}
+
+// op 当前正在比较的 operation，用于填充 Change
+type op struct {
+	d           *differ
+	method      string
+	path        string
+	operationID string
+}
+
+func (o op) add(level Level, id, location, format string, args ...interface{}) {
+	o.d.changes = append(o.d.changes, Change{
+		ID:          id,
+		Level:       level,
+		Method:      o.method,
+		Path:        o.path,
+		OperationID: o.operationID,
+		Location:    location,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) comparePath(path string, base, revision *openapi3.PathItem) {
+	var baseOps, revisionOps map[string]*openapi3.Operation
+	if base != nil {
+		baseOps = base.Operations()
+	}
+	if revision != nil {
+		revisionOps = revision.Operations()
+	}
+	for _, method := range unionKeys(baseOps, revisionOps) {
+		b, r := baseOps[method], revisionOps[method]
+		o := op{d: d, method: method, path: path}
+		switch {
+		case r == nil:
+			o.operationID = b.OperationID
+			o.add(Breaking, "operation-removed", "", "operation %s was removed", b.OperationID)
+		case b == nil:
+			o.operationID = r.OperationID
+			o.add(NonBreaking, "operation-added", "", "operation %s was added", r.OperationID)
+		default:
+			o.operationID = r.OperationID
+			o.compareOperation(parameters(base, b), parameters(revision, r), b, r)
+		}
+	}
+}
+
+func (o op) compareOperation(baseParams, revisionParams map[string]*openapi3.Parameter, b, r *openapi3.Operation) {
+	if b.OperationID != r.OperationID {
+		o.add(Breaking, "operation-id-changed", "", "operationId changed from %q to %q, generated method names change", b.OperationID, r.OperationID)
+	}
+
+	for _, key := range unionKeys(baseParams, revisionParams) {
+		bp, rp := baseParams[key], revisionParams[key]
+		switch {
+		case rp == nil:
+			o.add(Breaking, "request-parameter-removed", key, "parameter %s was removed", key)
+		case bp == nil && rp.Required:
+			o.add(Breaking, "new-required-request-parameter", key, "required parameter %s was added", key)
+		case bp == nil:
+			o.add(NonBreaking, "new-optional-request-parameter", key, "optional parameter %s was added", key)
+		default:
+			if !bp.Required && rp.Required {
+				o.add(Breaking, "request-parameter-became-required", key, "parameter %s became required", key)
+			} else if bp.Required && !rp.Required {
+				o.add(NonBreaking, "request-parameter-became-optional", key, "parameter %s became optional", key)
+			}
+			o.compareSchema(request, key, bp.Schema, rp.Schema)
+		}
+	}
+
+	o.compareRequestBody(b.RequestBody, r.RequestBody)
+
+	for _, status := range unionKeys(b.Responses, r.Responses) {
+		br, rr := b.Responses[status], r.Responses[status]
+		location := "response." + status
+		switch {
+		case rr == nil || rr.Value == nil:
+			o.add(Breaking, "response-status-removed", location, "response %s was removed", status)
+		case br == nil || br.Value == nil:
+			o.add(NonBreaking, "response-status-added", location, "response %s was added", status)
+		default:
+			o.compareContent(response, location, br.Value.Content, rr.Value.Content)
+		}
+	}
+}
+
+func (o op) compareRequestBody(b, r *openapi3.RequestBodyRef) {
+	const location = "request.body"
+	bodyOf := func(ref *openapi3.RequestBodyRef) *openapi3.RequestBody {
+		if ref == nil {
+			return nil
+		}
+		return ref.Value
+	}
+	bb, rb := bodyOf(b), bodyOf(r)
+	switch {
+	case bb == nil && rb == nil:
+		return
+	case rb == nil:
+		o.add(Breaking, "request-body-removed", location, "request body was removed")
+		return
+	case bb == nil && rb.Required:
+		o.add(Breaking, "new-required-request-body", location, "required request body was added")
+		return
+	case bb == nil:
+		o.add(NonBreaking, "new-optional-request-body", location, "optional request body was added")
+		return
+	}
+	if !bb.Required && rb.Required {
+		o.add(Breaking, "request-body-became-required", location, "request body became required")
+	} else if bb.Required && !rb.Required {
+		o.add(NonBreaking, "request-body-became-optional", location, "request body became optional")
+	}
+	o.compareContent(request, "request", bb.Content, rb.Content)
+}
+
+func (o op) compareContent(dir direction, location string, b, r openapi3.Content) {
+	for _, mediaType := range unionKeys(b, r) {
+		bm, rm := b[mediaType], r[mediaType]
+		switch {
+		case rm == nil:
+			o.add(Breaking, dir.prefix()+"-media-type-removed", location, "media type %s was removed", mediaType)
+		case bm == nil:
+			o.add(NonBreaking, dir.prefix()+"-media-type-added", location, "media type %s was added", mediaType)
+		default:
+			o.compareSchema(dir, location+".body", bm.Schema, rm.Schema)
+		}
+	}
+}
+
+// parameters 合并 path 级与 operation 级参数，以 "in.name" 为 key
+func parameters(item *openapi3.PathItem, operation *openapi3.Operation) map[string]*openapi3.Parameter {
+	params := make(map[string]*openapi3.Parameter)
+	for _, refs := range []openapi3.Parameters{item.Parameters, operation.Parameters} {
+		for _, ref := range refs {
+			if ref.Value != nil {
+				params[ref.Value.In+"."+ref.Value.Name] = ref.Value
+			}
+		}
+	}
+	return params
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteAll(values []interface{}) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("%v", v))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,218 @@
+package oasdiff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"demo/oapi-codegen-go/oasdiff"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, data string) *openapi3.T {
+	t.Helper()
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(data))
+	require.NoError(t, err)
+	return swagger
+}
+
+func demo(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	return string(data)
+}
+
+// revise 对 demo.yaml 做一次文本替换，old 必须存在
+func revise(t *testing.T, spec, old, new string) string {
+	t.Helper()
+	require.Contains(t, spec, old)
+	return strings.Replace(spec, old, new, 1)
+}
+
+func ids(report *oasdiff.Report) map[string]oasdiff.Level {
+	result := make(map[string]oasdiff.Level)
+	for _, c := range report.Changes {
+		result[c.ID] = c.Level
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	base := demo(t)
+	tests := []struct {
+		name     string
+		old, new string
+		want     map[string]oasdiff.Level
+	}{
+		{
+			name: "identical",
+			want: map[string]oasdiff.Level{},
+		},
+		{
+			name: "operation removed",
+			old:  "    delete:\n      description: deletes a single pet based on the ID supplied\n",
+			new:  "    x-delete:\n      description: deletes a single pet based on the ID supplied\n",
+			want: map[string]oasdiff.Level{"operation-removed": oasdiff.Breaking},
+		},
+		{
+			name: "operationId renamed",
+			old:  "operationId: deletePet",
+			new:  "operationId: removePet",
+			want: map[string]oasdiff.Level{"operation-id-changed": oasdiff.Breaking},
+		},
+		{
+			name: "new required parameter",
+			old:  "      parameters:\n        - name: tags\n",
+			new:  "      parameters:\n        - name: owner\n          in: query\n          required: true\n          schema:\n            type: string\n        - name: tags\n",
+			want: map[string]oasdiff.Level{"new-required-request-parameter": oasdiff.Breaking},
+		},
+		{
+			name: "new optional parameter",
+			old:  "      parameters:\n        - name: tags\n",
+			new:  "      parameters:\n        - name: owner\n          in: query\n          schema:\n            type: string\n        - name: tags\n",
+			want: map[string]oasdiff.Level{"new-optional-request-parameter": oasdiff.NonBreaking},
+		},
+		{
+			name: "request maximum narrowed",
+			old:  "maximum: 20",
+			new:  "maximum: 15",
+			want: map[string]oasdiff.Level{"request-maximum-decreased": oasdiff.Breaking},
+		},
+		{
+			name: "request maximum widened",
+			old:  "maximum: 20",
+			new:  "maximum: 50",
+			want: map[string]oasdiff.Level{"request-maximum-increased": oasdiff.NonBreaking},
+		},
+		{
+			name: "parameter type changed",
+			old:  "            type: integer\n            format: int64\n      responses:\n        '200':",
+			new:  "            type: string\n      responses:\n        '200':",
+			want: map[string]oasdiff.Level{"request-type-changed": oasdiff.Breaking},
+		},
+		{
+			name: "response property removed",
+			old:  "          properties:\n            id:\n              type: integer\n              format: int64\n",
+			new:  "          properties:\n            uid:\n              type: integer\n              format: int64\n",
+			want: map[string]oasdiff.Level{
+				"response-property-removed": oasdiff.Breaking,
+				"response-property-added":   oasdiff.NonBreaking,
+			},
+		},
+		{
+			name: "request property became required",
+			old:  "      required:\n        - name\n      properties:\n        name:",
+			new:  "      required:\n        - name\n        - tag\n      properties:\n        name:",
+			want: map[string]oasdiff.Level{
+				"request-property-became-required":  oasdiff.Breaking,
+				"response-property-became-required": oasdiff.NonBreaking,
+			},
+		},
+		{
+			name: "response enum added",
+			old:  "        tag:\n          type: string\n",
+			new:  "        tag:\n          type: string\n          enum: [cat, dog]\n",
+			want: map[string]oasdiff.Level{
+				"request-enum-added":  oasdiff.Breaking,
+				"response-enum-added": oasdiff.NonBreaking,
+			},
+		},
+		{
+			name: "request body became optional",
+			old:  "        description: Pet to add to the store\n        required: true\n",
+			new:  "        description: Pet to add to the store\n        required: false\n",
+			want: map[string]oasdiff.Level{"request-body-became-optional": oasdiff.NonBreaking},
+		},
+		{
+			name: "response status removed",
+			old:  "        '204':\n          description: pet deleted\n",
+			new:  "        '200':\n          description: pet deleted\n",
+			want: map[string]oasdiff.Level{
+				"response-status-removed": oasdiff.Breaking,
+				"response-status-added":   oasdiff.NonBreaking,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision := base
+			if tt.old != "" {
+				revision = revise(t, base, tt.old, tt.new)
+			}
+			report := oasdiff.Compare(load(t, base), load(t, revision))
+			assert.Equal(t, tt.want, ids(report))
+		})
+	}
+}
+
+func TestCompareEnumValues(t *testing.T) {
+	base := revise(t, demo(t), "        tag:\n          type: string\n", "        tag:\n          type: string\n          enum: [cat, dog]\n")
+	revision := revise(t, base, "enum: [cat, dog]", "enum: [cat, bird]")
+	report := oasdiff.Compare(load(t, base), load(t, revision))
+
+	// NewPet 同时出现在请求与响应中，删除 dog 只对请求是 breaking，新增 bird 只对响应是 breaking。
+	// 每个请求体与响应都声明了 5 种媒体类型，各报告一次；findPets 的 200 另有 NDJSON，
+	// exportPets 的 200 只有 JSON（CSV 没有 schema）
+	var requestRemoved, responseAdded int
+	for _, c := range report.Changes {
+		switch c.ID {
+		case "request-enum-value-removed":
+			requestRemoved++
+			assert.Equal(t, oasdiff.Breaking, c.Level)
+			assert.Equal(t, "request.body.tag", c.Location)
+		case "response-enum-value-added":
+			responseAdded++
+			assert.Equal(t, oasdiff.Breaking, c.Level)
+		}
+	}
+	assert.Equal(t, 1*5, requestRemoved)
+	assert.Equal(t, 3*5+1+1, responseAdded)
+}
+
+func TestReport(t *testing.T) {
+	base := demo(t)
+	revision := revise(t, base, "maximum: 20", "maximum: 15")
+	revision = revise(t, revision, "      parameters:\n        - name: tags\n",
+		"      parameters:\n        - name: owner\n          in: query\n          schema:\n            type: string\n        - name: tags\n")
+	report := oasdiff.Compare(load(t, base), load(t, revision))
+	require.Len(t, report.Changes, 2)
+	assert.True(t, report.HasBreaking())
+	require.Len(t, report.Breaking(), 1)
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Equal(t, "breaking     GET /pets [query.limit] maximum decreased from 20 to 15 (request-maximum-decreased)\n"+
+		"non-breaking GET /pets [query.owner] optional parameter query.owner was added (new-optional-request-parameter)\n"+
+		"2 changes, 1 breaking\n", text.String())
+
+	var js bytes.Buffer
+	require.NoError(t, report.WriteJSON(&js))
+	var decoded struct {
+		Breaking int `json:"breaking"`
+		Changes  []struct {
+			ID          string `json:"id"`
+			Level       string `json:"level"`
+			OperationID string `json:"operationId"`
+		} `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, 1, decoded.Breaking)
+	assert.Equal(t, "breaking", decoded.Changes[0].Level)
+	assert.Equal(t, "findPets", decoded.Changes[0].OperationID)
+
+	var md bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&md))
+	assert.Equal(t, "## API changes\n\n### Breaking changes\n\n"+
+		"- `GET /pets` `query.limit`: maximum decreased from 20 to 15\n\n"+
+		"### Non-breaking changes\n\n"+
+		"- `GET /pets` `query.owner`: optional parameter query.owner was added\n", md.String())
+
+	md.Reset()
+	require.NoError(t, oasdiff.Compare(load(t, base), load(t, base)).WriteMarkdown(&md))
+	assert.Equal(t, "## API changes\n\nNo changes.\n", md.String())
+}
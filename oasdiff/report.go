@@ -0,0 +1,92 @@
+package oasdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText 每行一个变化，breaking 在前
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	for _, level := range []Level{Breaking, NonBreaking} {
+		for _, c := range r.Changes {
+			if c.Level != level {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%-12s %s\n", level, c.describe()); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d changes, %d breaking\n", len(r.Changes), len(r.Breaking()))
+	return err
+}
+
+// WriteJSON 输出 {"breaking": n, "changes": [...]}
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	changes := r.Changes
+	if changes == nil {
+		changes = []Change{}
+	}
+	return enc.Encode(struct {
+		Breaking int      `json:"breaking"`
+		Changes  []Change `json:"changes"`
+	}{len(r.Breaking()), changes})
+}
+
+// WriteMarkdown 生成可以直接贴进 CHANGELOG 的 Markdown
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("## API changes\n")
+	if len(r.Changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	for _, section := range []struct {
+		level Level
+		title string
+	}{{Breaking, "Breaking changes"}, {NonBreaking, "Non-breaking changes"}} {
+		var lines []string
+		for _, c := range r.Changes {
+			if c.Level == section.level {
+				lines = append(lines, "- "+c.markdown())
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", section.title, strings.Join(lines, "\n"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (c Change) describe() string {
+	var b strings.Builder
+	if c.Method != "" {
+		fmt.Fprintf(&b, "%s %s ", c.Method, c.Path)
+	}
+	if c.Location != "" {
+		fmt.Fprintf(&b, "[%s] ", c.Location)
+	}
+	fmt.Fprintf(&b, "%s (%s)", c.Message, c.ID)
+	return b.String()
+}
+
+func (c Change) markdown() string {
+	var b strings.Builder
+	if c.Method != "" {
+		fmt.Fprintf(&b, "`%s %s` ", c.Method, c.Path)
+	}
+	if c.Location != "" {
+		fmt.Fprintf(&b, "`%s`: ", c.Location)
+	}
+	b.WriteString(c.Message)
+	return b.String()
+}
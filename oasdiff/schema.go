@@ -0,0 +1,199 @@
+package oasdiff
+
+import (
+	"reflect"
+
+	"demo/oapi-codegen-go/internal/allof"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// direction 数据流向：请求由客户端构造，响应由客户端解析
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+func (d direction) prefix() string {
+	if d == request {
+		return "request"
+	}
+	return "response"
+}
+
+// narrowed 收紧约束：原来合法的请求可能被拒绝
+func (d direction) narrowed() Level {
+	if d == request {
+		return Breaking
+	}
+	return NonBreaking
+}
+
+// widened 放宽约束：客户端可能收到原来不会出现的数据
+func (d direction) widened() Level {
+	if d == request {
+		return NonBreaking
+	}
+	return Breaking
+}
+
+const maxSchemaDepth = 16
+
+func (o op) compareSchema(dir direction, location string, b, r *openapi3.SchemaRef) {
+	o.compareSchemaDepth(dir, location, b, r, 0)
+}
+
+func (o op) compareSchemaDepth(dir direction, location string, b, r *openapi3.SchemaRef, depth int) {
+	if b == nil || r == nil || b.Value == nil || r.Value == nil || depth > maxSchemaDepth {
+		return
+	}
+	bs, rs := allof.Flatten(b.Value), allof.Flatten(r.Value)
+	prefix := dir.prefix()
+
+	if bs.Type != rs.Type && bs.Type != "" && rs.Type != "" {
+		o.add(Breaking, prefix+"-type-changed", location, "type changed from %s to %s", bs.Type, rs.Type)
+		return
+	}
+	if bs.Format != rs.Format {
+		o.add(Breaking, prefix+"-format-changed", location, "format changed from %q to %q", bs.Format, rs.Format)
+	}
+	if bs.Pattern != rs.Pattern {
+		o.add(dir.narrowed(), prefix+"-pattern-changed", location, "pattern changed from %q to %q", bs.Pattern, rs.Pattern)
+	}
+	if bs.Nullable && !rs.Nullable {
+		o.add(dir.narrowed(), prefix+"-became-not-nullable", location, "null is no longer allowed")
+	} else if !bs.Nullable && rs.Nullable {
+		o.add(dir.widened(), prefix+"-became-nullable", location, "null is now allowed")
+	}
+
+	o.compareEnum(dir, location, bs.Enum, rs.Enum)
+	o.compareLowerBound(dir, location, "minimum", bs.Min, rs.Min, bs.ExclusiveMin, rs.ExclusiveMin)
+	o.compareUpperBound(dir, location, "maximum", bs.Max, rs.Max, bs.ExclusiveMax, rs.ExclusiveMax)
+	o.compareLowerBound(dir, location, "minLength", uintPtr(bs.MinLength), uintPtr(rs.MinLength), false, false)
+	o.compareUpperBound(dir, location, "maxLength", floatPtr(bs.MaxLength), floatPtr(rs.MaxLength), false, false)
+	o.compareLowerBound(dir, location, "minItems", uintPtr(bs.MinItems), uintPtr(rs.MinItems), false, false)
+	o.compareUpperBound(dir, location, "maxItems", floatPtr(bs.MaxItems), floatPtr(rs.MaxItems), false, false)
+
+	if bs.Items != nil || rs.Items != nil {
+		o.compareSchemaDepth(dir, location+"[]", bs.Items, rs.Items, depth+1)
+	}
+	o.compareProperties(dir, location, bs, rs, depth)
+}
+
+func (o op) compareProperties(dir direction, location string, bs, rs *openapi3.Schema, depth int) {
+	prefix := dir.prefix()
+	for _, name := range unionKeys(bs.Properties, rs.Properties) {
+		bp, rp := bs.Properties[name], rs.Properties[name]
+		propLocation := location + "." + name
+		bReq, rReq := contains(bs.Required, name), contains(rs.Required, name)
+		switch {
+		case rp == nil:
+			// 请求中客户端仍会发送这个字段，响应中客户端依赖的字段消失了
+			o.add(Breaking, prefix+"-property-removed", propLocation, "property %s was removed", name)
+		case bp == nil && dir == request && rReq:
+			o.add(Breaking, "new-required-request-property", propLocation, "required property %s was added", name)
+		case bp == nil:
+			o.add(NonBreaking, prefix+"-property-added", propLocation, "property %s was added", name)
+		default:
+			if !bReq && rReq {
+				o.add(dir.narrowed(), prefix+"-property-became-required", propLocation, "property %s became required", name)
+			} else if bReq && !rReq {
+				o.add(dir.widened(), prefix+"-property-became-optional", propLocation, "property %s became optional", name)
+			}
+			o.compareSchemaDepth(dir, propLocation, bp, rp, depth+1)
+		}
+	}
+}
+
+func (o op) compareEnum(dir direction, location string, b, r []interface{}) {
+	prefix := dir.prefix()
+	switch {
+	case len(b) == 0 && len(r) == 0:
+		return
+	case len(b) == 0:
+		o.add(dir.narrowed(), prefix+"-enum-added", location, "values are now restricted to [%s]", quoteAll(r))
+		return
+	case len(r) == 0:
+		o.add(dir.widened(), prefix+"-enum-removed", location, "values are no longer restricted")
+		return
+	}
+	if removed := difference(b, r); len(removed) > 0 {
+		o.add(dir.narrowed(), prefix+"-enum-value-removed", location, "enum values removed: %s", quoteAll(removed))
+	}
+	if added := difference(r, b); len(added) > 0 {
+		o.add(dir.widened(), prefix+"-enum-value-added", location, "enum values added: %s", quoteAll(added))
+	}
+}
+
+// compareLowerBound minimum、minLength、minItems：变大或新增为收紧
+func (o op) compareLowerBound(dir direction, location, name string, b, r *float64, bExclusive, rExclusive bool) {
+	switch {
+	case b == nil && r == nil:
+		return
+	case b == nil:
+		o.add(dir.narrowed(), dir.prefix()+"-"+name+"-added", location, "%s %v was added", name, *r)
+	case r == nil:
+		o.add(dir.widened(), dir.prefix()+"-"+name+"-removed", location, "%s %v was removed", name, *b)
+	case *r > *b || *r == *b && rExclusive && !bExclusive:
+		o.add(dir.narrowed(), dir.prefix()+"-"+name+"-increased", location, "%s increased from %v to %v", name, *b, *r)
+	case *r < *b || *r == *b && !rExclusive && bExclusive:
+		o.add(dir.widened(), dir.prefix()+"-"+name+"-decreased", location, "%s decreased from %v to %v", name, *b, *r)
+	}
+}
+
+// compareUpperBound maximum、maxLength、maxItems：变小或新增为收紧
+func (o op) compareUpperBound(dir direction, location, name string, b, r *float64, bExclusive, rExclusive bool) {
+	switch {
+	case b == nil && r == nil:
+		return
+	case b == nil:
+		o.add(dir.narrowed(), dir.prefix()+"-"+name+"-added", location, "%s %v was added", name, *r)
+	case r == nil:
+		o.add(dir.widened(), dir.prefix()+"-"+name+"-removed", location, "%s %v was removed", name, *b)
+	case *r < *b || *r == *b && rExclusive && !bExclusive:
+		o.add(dir.narrowed(), dir.prefix()+"-"+name+"-decreased", location, "%s decreased from %v to %v", name, *b, *r)
+	case *r > *b || *r == *b && !rExclusive && bExclusive:
+		o.add(dir.widened(), dir.prefix()+"-"+name+"-increased", location, "%s increased from %v to %v", name, *b, *r)
+	}
+}
+
+func difference(a, b []interface{}) []interface{} {
+	var result []interface{}
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			found = found || reflect.DeepEqual(v, w)
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// uintPtr minLength/minItems 为 0 时视为没有约束
+func uintPtr(v uint64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	f := float64(v)
+	return &f
+}
+
+func floatPtr(v *uint64) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
@@ -0,0 +1,96 @@
+package codegen_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WaitForOperation 的默认轮询间隔
+const (
+	DefaultWaitInterval    = 200 * time.Millisecond
+	DefaultWaitMaxInterval = 5 * time.Second
+	DefaultWaitMultiplier  = 1.5
+)
+
+// PreferAsync 请求服务端在后台执行 spec 中标记了 x-async 的接口，服务端接受时返回 202 与 Operation
+func PreferAsync(ctx context.Context, req *http.Request) error {
+	req.Header.Set("Prefer", "respond-async")
+	return nil
+}
+
+// Finished 状态是否已经不会再变化
+func (o Operation) Finished() bool {
+	return o.Status == Succeeded || o.Status == Failed || o.Status == Canceled
+}
+
+// WaitOptions 控制 WaitForOperation 的轮询
+type WaitOptions struct {
+	// Interval 第一次轮询之前的等待，0 表示 DefaultWaitInterval
+	Interval time.Duration
+	// MaxInterval 间隔的上限，0 表示 DefaultWaitMaxInterval
+	MaxInterval time.Duration
+	// Multiplier 每次轮询之后间隔乘以的系数，不大于 1 时使用 DefaultWaitMultiplier
+	Multiplier float64
+	// Progress 每次轮询到未结束的状态时调用
+	Progress func(Operation)
+}
+
+// WaitForOperation 轮询 GetOperation 直到任务结束，间隔按 Multiplier 指数增长，
+// 响应带 Retry-After 秒数时以它为下一次的间隔。任务失败或被取消不是错误，由返回的 Status 区分；
+// 非 200 的响应返回 *ResponseError，ctx 取消时返回 ctx.Err()
+//
+//	rsp, _ := client.BulkImportPetsWithResponse(ctx, nil, pets, codegenTest.PreferAsync)
+//	op, err := codegenTest.WaitForOperation(ctx, client, rsp.JSON202.Id, codegenTest.WaitOptions{})
+func WaitForOperation(ctx context.Context, client ClientInterface, id string, options WaitOptions, reqEditors ...RequestEditorFn) (*Operation, error) {
+	if options.Interval <= 0 {
+		options.Interval = DefaultWaitInterval
+	}
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = DefaultWaitMaxInterval
+	}
+	if options.Multiplier <= 1 {
+		options.Multiplier = DefaultWaitMultiplier
+	}
+	interval := options.Interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		rsp, err := client.GetOperation(ctx, id, reqEditors...)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseGetOperationResponse(rsp)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.JSON200 == nil {
+			body := parsed.JSONDefault
+			if parsed.JSON404 != nil {
+				body = parsed.JSON404
+			}
+			return nil, &ResponseError{StatusCode: rsp.StatusCode, Body: body}
+		}
+		if parsed.JSON200.Finished() {
+			return parsed.JSON200, nil
+		}
+		if options.Progress != nil {
+			options.Progress(*parsed.JSON200)
+		}
+		interval = time.Duration(float64(interval) * options.Multiplier)
+		if interval > options.MaxInterval {
+			interval = options.MaxInterval
+		}
+		next := interval
+		if seconds, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			next = time.Duration(seconds) * time.Second
+		}
+		timer.Reset(next)
+	}
+}
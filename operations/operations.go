@@ -0,0 +1,568 @@
+// Package operations 把耗时的请求放到后台执行，客户端轮询 GET /operations/{id} 获取进度和结果。
+//
+// spec 中标记了 x-async: true 的 operation，请求带 Prefer: respond-async 时，
+// Middleware 保存请求并立即返回 202，Location 指向 /operations/{id}。
+// 有界的 worker 池按提交顺序把请求重放给 Start 传入的 handler，响应保存在 Operation.response 中。
+// handler 可以用 SetProgress 报告进度，并在请求的 context 取消时尽快返回。
+//
+// 任务保存在 Store 中，使用 FileStore 时重启后未开始的任务会继续执行，
+// 重启前正在执行的任务没法知道做到了哪一步，标记为 failed。
+package operations
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/internal/httpdoer"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// Extension operation 上的扩展名，为 true 时允许异步执行
+const Extension = "x-async"
+
+// HeaderPrefer 与 HeaderPreferenceApplied 见 RFC 7240
+const (
+	HeaderPrefer            = "Prefer"
+	HeaderPreferenceApplied = "Preference-Applied"
+	RespondAsync            = "respond-async"
+)
+
+// Options 的默认值
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+	DefaultRetention = 24 * time.Hour
+)
+
+// cleanupInterval 清理过期任务的周期
+const cleanupInterval = time.Minute
+
+var (
+	// ErrNotFound 任务不存在或已经过期
+	ErrNotFound = errors.New("operation not found")
+	// ErrFinished 任务已经结束，不能取消
+	ErrFinished = errors.New("operation already finished")
+	// ErrQueueFull 排队的任务达到 QueueSize
+	ErrQueueFull = errors.New("too many pending operations")
+)
+
+// Options 控制 Manager
+type Options struct {
+	// Swagger 用于查找带 x-async 的 operation，路径要带上与路由相同的前缀
+	Swagger *openapi3.T
+	// BaseURL Location 中 /operations/{id} 之前的前缀
+	BaseURL string
+	// Store 为 nil 时使用 MemoryStore
+	Store Store
+	// Workers 同时执行的任务数，0 表示 DefaultWorkers
+	Workers int
+	// QueueSize 等待执行的任务数上限，超过时返回 503，0 表示 DefaultQueueSize
+	QueueSize int
+	// Retention 结束的任务保留多久，0 表示 DefaultRetention
+	Retention time.Duration
+	// RedactHeaders 保存请求之前删除的 header，为 nil 时使用 httpdoer.CredentialHeaders；
+	// spec 中 in: header 的 apiKey 总是删除。重放的请求不带这些凭证
+	RedactHeaders []string
+	// Principal 不为 nil 时在提交时解析调用方的身份并代替凭证保存，重放时用 PrincipalFromContext 取出；
+	// 返回错误时不提交，错误原样交给 Echo，例如 echo.ErrUnauthorized
+	Principal func(req *http.Request) (string, error)
+}
+
+// Manager 接收、排队、执行并保存任务，可以并发使用
+type Manager struct {
+	options Options
+	router  routers.Router
+	queue   chan string
+
+	// mu 保护任务状态的读-改-写以及 running
+	mu      sync.Mutex
+	running map[string]*execution
+
+	handler http.Handler
+	ctx     context.Context
+	stop    context.CancelFunc
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// execution 正在执行的任务
+type execution struct {
+	cancel   context.CancelFunc
+	canceled bool
+}
+
+type jobKey struct{}
+
+// jobContext 重放请求的 context 中保存的任务
+type jobContext struct {
+	manager   *Manager
+	id        string
+	principal string
+}
+
+// New 创建 Manager，需要调用 Start 之后任务才会执行
+func New(options Options) (*Manager, error) {
+	if options.Swagger == nil {
+		return nil, errors.New("operations: Swagger is required")
+	}
+	if options.Store == nil {
+		options.Store = NewMemoryStore()
+	}
+	if options.Workers <= 0 {
+		options.Workers = DefaultWorkers
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultQueueSize
+	}
+	if options.Retention <= 0 {
+		options.Retention = DefaultRetention
+	}
+	if options.RedactHeaders == nil {
+		options.RedactHeaders = httpdoer.CredentialHeaders
+	}
+	options.RedactHeaders = append(append([]string(nil), options.RedactHeaders...), apiKeyHeaders(options.Swagger)...)
+	router, err := gorillamux.NewRouter(options.Swagger)
+	if err != nil {
+		return nil, err
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		options: options,
+		router:  router,
+		queue:   make(chan string, options.QueueSize),
+		running: map[string]*execution{},
+		ctx:     ctx,
+		stop:    stop,
+		closed:  make(chan struct{}),
+	}, nil
+}
+
+// Middleware 把带 Prefer: respond-async 的 x-async 请求转为后台任务并返回 202。
+// 挂在请求校验之后，无效的请求仍然同步返回 400
+func (m *Manager) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Context().Value(jobKey{}) != nil || !respondAsync(req.Header.Values(HeaderPrefer)) {
+				return next(c)
+			}
+			route, _, err := m.router.FindRoute(req)
+			if err != nil || route.Operation == nil || route.Operation.Extensions[Extension] != true {
+				return next(c)
+			}
+			op, err := m.submit(route.Operation.OperationID, req)
+			if errors.Is(err, ErrQueueFull) {
+				c.Response().Header().Set("Retry-After", "1")
+				return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error()).SetInternal(err)
+			}
+			if err != nil {
+				return err
+			}
+			c.Response().Header().Set(echo.HeaderLocation, m.options.BaseURL+"/operations/"+op.Id)
+			c.Response().Header().Set(HeaderPreferenceApplied, RespondAsync)
+			return codec.Respond(c, http.StatusAccepted, op)
+		}
+	}
+}
+
+// respondAsync Prefer 中是否有 respond-async，忽略其它偏好和参数
+func respondAsync(values []string) bool {
+	for _, value := range values {
+		for _, preference := range strings.Split(value, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			token, _, _ = strings.Cut(token, "=")
+			if strings.EqualFold(strings.TrimSpace(token), RespondAsync) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// submit 保存请求快照并排队，队列满时不保存
+func (m *Manager) submit(operationID string, req *http.Request) (codegenTest.Operation, error) {
+	var body []byte
+	if req.Body != nil {
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(req.Body); err != nil {
+			return codegenTest.Operation{}, err
+		}
+		body = buf.Bytes()
+	}
+	var principal string
+	if m.options.Principal != nil {
+		var err error
+		if principal, err = m.options.Principal(req); err != nil {
+			return codegenTest.Operation{}, err
+		}
+	}
+	header := req.Header.Clone()
+	// 重放时同步执行并以 JSON 保存响应
+	for _, name := range []string{HeaderPrefer, echo.HeaderAccept, echo.HeaderAcceptEncoding, "Connection"} {
+		header.Del(name)
+	}
+	// 凭证不落盘
+	for _, name := range m.options.RedactHeaders {
+		header.Del(name)
+	}
+	now := time.Now().UTC()
+	job := &Job{
+		Operation: codegenTest.Operation{
+			Id:          newID(),
+			OperationId: operationID,
+			Status:      codegenTest.Pending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		Request: Request{Method: req.Method, URL: req.URL.RequestURI(), Header: header, Body: body, Principal: principal},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.closed:
+		return codegenTest.Operation{}, errors.New("operations: manager is shut down")
+	default:
+	}
+	if err := m.options.Store.Save(job); err != nil {
+		return codegenTest.Operation{}, err
+	}
+	select {
+	case m.queue <- job.Operation.Id:
+		return job.Operation, nil
+	default:
+		_ = m.options.Store.Delete(job.Operation.Id)
+		return codegenTest.Operation{}, ErrQueueFull
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Start 恢复 Store 中的任务并启动 worker，任务重放给 handler，通常是挂载了 Middleware 的 *echo.Echo。
+// 重放的请求经过完整的中间件链，Middleware 识别出重放的请求后直接放行
+func (m *Manager) Start(handler http.Handler) error {
+	m.handler = handler
+	pending, err := m.restore()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < m.options.Workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+	m.wg.Add(1)
+	go m.cleanup()
+	// 恢复的任务可能超过队列容量，不能阻塞启动
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for _, id := range pending {
+			select {
+			case m.queue <- id:
+			case <-m.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// restore 按创建顺序返回未开始的任务，重启前正在执行的任务标记为 failed，并清理过期的任务
+func (m *Manager) restore() ([]string, error) {
+	jobs, err := m.options.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Operation.CreatedAt.Before(jobs[j].Operation.CreatedAt) })
+	var pending []string
+	for _, job := range jobs {
+		switch job.Operation.Status {
+		case codegenTest.Pending:
+			pending = append(pending, job.Operation.Id)
+		case codegenTest.Running:
+			finish(job, codegenTest.Failed, nil, &codegenTest.Error{Code: http.StatusInternalServerError, Message: "interrupted by restart"})
+			if err := m.options.Store.Save(job); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pending, m.expire()
+}
+
+// expire 删除结束超过 Retention 的任务
+func (m *Manager) expire() error {
+	jobs, err := m.options.Store.List()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(-m.options.Retention)
+	for _, job := range jobs {
+		if job.Operation.Finished() && job.Operation.UpdatedAt.Before(deadline) {
+			if err := m.options.Store.Delete(job.Operation.Id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) cleanup() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = m.expire()
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for {
+		select {
+		case id := <-m.queue:
+			m.execute(id)
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// execute 执行一个任务；任务在排队时被取消或已经过期时跳过
+func (m *Manager) execute(id string) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.mu.Lock()
+	job, err := m.options.Store.Load(id)
+	if err != nil || job.Operation.Status != codegenTest.Pending {
+		m.mu.Unlock()
+		return
+	}
+	job.Operation.Status = codegenTest.Running
+	job.Operation.UpdatedAt = time.Now().UTC()
+	if err := m.options.Store.Save(job); err != nil {
+		m.mu.Unlock()
+		return
+	}
+	exec := &execution{cancel: cancel}
+	m.running[id] = exec
+	m.mu.Unlock()
+
+	rec := m.replay(context.WithValue(ctx, jobKey{}, jobContext{manager: m, id: id, principal: job.Request.Principal}), job.Request)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.running, id)
+	if job, err = m.options.Store.Load(id); err != nil {
+		return
+	}
+	response := &codegenTest.OperationResponse{
+		Status:      int32(rec.status),
+		ContentType: rec.header.Get(echo.HeaderContentType),
+		Body:        rec.body.String(),
+	}
+	switch {
+	case rec.status < http.StatusBadRequest:
+		// 取消得太晚，handler 已经做完了，结果仍然有效
+		finish(job, codegenTest.Succeeded, response, nil)
+	case exec.canceled:
+		finish(job, codegenTest.Canceled, response, nil)
+	case ctx.Err() != nil:
+		finish(job, codegenTest.Failed, response, &codegenTest.Error{Code: http.StatusServiceUnavailable, Message: "interrupted by shutdown"})
+	default:
+		finish(job, codegenTest.Failed, response, responseError(rec))
+	}
+	_ = m.options.Store.Save(job)
+}
+
+// replay 用快照重建请求交给 handler，handler panic 时按 500 记录
+func (m *Manager) replay(ctx context.Context, snapshot Request) (rec *recorder) {
+	rec = &recorder{header: http.Header{}}
+	defer func() {
+		if r := recover(); r != nil {
+			rec = &recorder{header: http.Header{}, status: http.StatusInternalServerError}
+			rec.body.WriteString(fmt.Sprint(r))
+		}
+	}()
+	req, err := http.NewRequestWithContext(ctx, snapshot.Method, snapshot.URL, bytes.NewReader(snapshot.Body))
+	if err != nil {
+		rec.WriteHeader(http.StatusInternalServerError)
+		rec.body.WriteString(err.Error())
+		return rec
+	}
+	req.Header = snapshot.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set(echo.HeaderAccept, codec.MIMEApplicationJSON)
+	m.handler.ServeHTTP(rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec
+}
+
+// responseError 从失败的响应中取出 Error，无法解码时按状态码生成
+func responseError(rec *recorder) *codegenTest.Error {
+	var e codegenTest.Error
+	if c, ok := codec.Lookup(rec.header.Get(echo.HeaderContentType)); ok && c.Unmarshal(rec.body.Bytes(), &e) == nil && e.Message != "" {
+		return &e
+	}
+	return &codegenTest.Error{Code: int32(rec.status), Message: http.StatusText(rec.status)}
+}
+
+func finish(job *Job, status codegenTest.OperationStatus, response *codegenTest.OperationResponse, e *codegenTest.Error) {
+	job.Operation.Status = status
+	job.Operation.Response = response
+	job.Operation.Error = e
+	job.Operation.UpdatedAt = time.Now().UTC()
+}
+
+// Get 返回任务的当前状态
+func (m *Manager) Get(id string) (codegenTest.Operation, error) {
+	job, err := m.options.Store.Load(id)
+	if err != nil {
+		return codegenTest.Operation{}, err
+	}
+	return job.Operation, nil
+}
+
+// Cancel 取消任务：排队中的立即变为 canceled；执行中的取消其 context，
+// 返回的状态仍是 running，handler 返回后变为 canceled。已经结束的返回 ErrFinished
+func (m *Manager) Cancel(id string) (codegenTest.Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, err := m.options.Store.Load(id)
+	if err != nil {
+		return codegenTest.Operation{}, err
+	}
+	switch job.Operation.Status {
+	case codegenTest.Pending:
+		finish(job, codegenTest.Canceled, nil, nil)
+		if err := m.options.Store.Save(job); err != nil {
+			return codegenTest.Operation{}, err
+		}
+	case codegenTest.Running:
+		if exec, ok := m.running[id]; ok {
+			exec.canceled = true
+			exec.cancel()
+		}
+	default:
+		return job.Operation, ErrFinished
+	}
+	return job.Operation, nil
+}
+
+// SetProgress handler 在后台执行时报告进度，ctx 是请求的 context；同步执行的请求什么都不做
+func SetProgress(ctx context.Context, done, total int64) {
+	jc, ok := ctx.Value(jobKey{}).(jobContext)
+	if !ok {
+		return
+	}
+	m := jc.manager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, err := m.options.Store.Load(jc.id)
+	if err != nil || job.Operation.Status != codegenTest.Running {
+		return
+	}
+	job.Operation.Progress = &codegenTest.OperationProgress{Done: done, Total: total}
+	job.Operation.UpdatedAt = time.Now().UTC()
+	_ = m.options.Store.Save(job)
+}
+
+// PrincipalFromContext 重放请求时取出 Options.Principal 在提交时解析的身份
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	jc, ok := ctx.Value(jobKey{}).(jobContext)
+	if !ok || jc.principal == "" {
+		return "", false
+	}
+	return jc.principal, true
+}
+
+// apiKeyHeaders spec 中放在 header 里的 apiKey 名称
+func apiKeyHeaders(swagger *openapi3.T) []string {
+	var names []string
+	for _, ref := range swagger.Components.SecuritySchemes {
+		if ref.Value != nil && ref.Value.Type == "apiKey" && ref.Value.In == openapi3.ParameterInHeader {
+			names = append(names, ref.Value.Name)
+		}
+	}
+	return names
+}
+
+// Shutdown 停止接收新任务，等待正在执行的任务结束；ctx 到期时取消它们，
+// 这些任务记为 failed。排队中的任务保留在 Store 中，下次 Start 时继续
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	select {
+	case <-m.closed:
+	default:
+		close(m.closed)
+	}
+	m.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		m.stop()
+		return nil
+	case <-ctx.Done():
+		m.stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// recorder 保存重放的响应；Flush 什么都不做，流式响应也整体保存
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *recorder) Flush() {}
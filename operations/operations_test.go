@@ -0,0 +1,288 @@
+package operations_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/operations"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastPolling = codegenTest.WaitOptions{Interval: time.Millisecond, MaxInterval: 10 * time.Millisecond}
+
+func bulkBody(names ...string) codegenTest.BulkImportPetsJSONRequestBody {
+	body := make(codegenTest.BulkImportPetsJSONRequestBody, len(names))
+	for i, name := range names {
+		body[i] = map[string]interface{}{"name": name}
+	}
+	return body
+}
+
+func TestAsyncBulkImport(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{Operations: &operations.Options{}})
+
+	rsp, err := h.Client.BulkImportPetsWithResponse(h.Ctx, nil, bulkBody("rex", "fido"), codegenTest.PreferAsync)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, rsp.StatusCode(), string(rsp.Body))
+	require.NotNil(t, rsp.JSON202)
+	assert.Equal(t, "/api/operations/"+rsp.JSON202.Id, rsp.HTTPResponse.Header.Get("Location"))
+	assert.Equal(t, "respond-async", rsp.HTTPResponse.Header.Get("Preference-Applied"))
+	assert.Equal(t, "BulkImportPets", rsp.JSON202.OperationId)
+
+	op, err := codegenTest.WaitForOperation(h.Ctx, h.Client, rsp.JSON202.Id, fastPolling)
+	require.NoError(t, err)
+	assert.Equal(t, codegenTest.Succeeded, op.Status)
+	assert.Equal(t, &codegenTest.OperationProgress{Done: 2, Total: 2}, op.Progress)
+	require.NotNil(t, op.Response)
+	assert.EqualValues(t, http.StatusOK, op.Response.Status)
+	var result codegenTest.BulkImportResult
+	require.NoError(t, json.Unmarshal([]byte(op.Response.Body), &result))
+	assert.EqualValues(t, 2, result.Created)
+	assert.Len(t, h.Store.Find(nil, 0), 2)
+}
+
+func TestSynchronousWithoutPrefer(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{Operations: &operations.Options{}})
+
+	rsp, err := h.Client.BulkImportPetsWithResponse(h.Ctx, nil, bulkBody("rex"))
+	require.NoError(t, err)
+	require.NotNil(t, rsp.JSON200)
+	assert.EqualValues(t, 1, rsp.JSON200.Created)
+
+	// 无效的请求在排队之前就被校验拒绝
+	mode := codegenTest.BulkImportPetsParamsMode("all")
+	rsp, err = h.Client.BulkImportPetsWithResponse(h.Ctx, &codegenTest.BulkImportPetsParams{Mode: &mode}, bulkBody("rex"), codegenTest.PreferAsync)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rsp.StatusCode())
+
+	// 没有标记 x-async 的接口忽略 Prefer
+	add, err := h.Client.AddPetWithResponse(h.Ctx, codegenTest.NewPet{Name: "tom"}, codegenTest.PreferAsync)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, add.StatusCode())
+}
+
+func TestFailedOperation(t *testing.T) {
+	h := testkit.New(t, &testkit.Options{Operations: &operations.Options{}})
+
+	mode := codegenTest.Atomic
+	rsp, err := h.Client.BulkImportPetsWithBodyWithResponse(h.Ctx, &codegenTest.BulkImportPetsParams{Mode: &mode},
+		"application/x-ndjson", strings.NewReader("{\"name\":\"rex\"}\n{}\n"), codegenTest.PreferAsync)
+	require.NoError(t, err)
+	require.NotNil(t, rsp.JSON202, string(rsp.Body))
+
+	op, err := codegenTest.WaitForOperation(h.Ctx, h.Client, rsp.JSON202.Id, fastPolling)
+	require.NoError(t, err)
+	assert.Equal(t, codegenTest.Failed, op.Status)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, op.Response.Status)
+	require.NotNil(t, op.Error)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, op.Error.Code)
+	assert.Empty(t, h.Store.Find(nil, 0))
+}
+
+// blockingServer 的批量导入在 release 关闭或请求取消之前不返回
+type blockingServer struct {
+	*app.EchoServer
+	started chan string
+	release chan struct{}
+}
+
+func (s *blockingServer) BulkImportPets(ctx echo.Context, params codegenTest.BulkImportPetsParams) error {
+	s.started <- ctx.Request().URL.RawQuery
+	select {
+	case <-s.release:
+	case <-ctx.Request().Context().Done():
+		return ctx.Request().Context().Err()
+	}
+	return s.EchoServer.BulkImportPets(ctx, params)
+}
+
+func TestCancelAndQueueLimit(t *testing.T) {
+	server := &blockingServer{EchoServer: &app.EchoServer{}, started: make(chan string, 1), release: make(chan struct{})}
+	h := testkit.New(t, &testkit.Options{Server: server, Operations: &operations.Options{Workers: 1, QueueSize: 1}})
+	server.Operations = h.Operations
+	submit := func() *codegenTest.BulkImportPetsResponse {
+		rsp, err := h.Client.BulkImportPetsWithResponse(h.Ctx, nil, bulkBody("rex"), codegenTest.PreferAsync)
+		require.NoError(t, err)
+		return rsp
+	}
+
+	running := submit().JSON202
+	<-server.started
+	pending := submit().JSON202
+	require.NotNil(t, pending)
+	full := submit()
+	assert.Equal(t, http.StatusServiceUnavailable, full.StatusCode())
+	assert.Equal(t, "1", full.HTTPResponse.Header.Get("Retry-After"))
+
+	// 排队中的任务立即取消，worker 取到时跳过
+	canceled, err := h.Client.CancelOperationWithResponse(h.Ctx, pending.Id)
+	require.NoError(t, err)
+	require.NotNil(t, canceled.JSON200)
+	assert.Equal(t, codegenTest.Canceled, canceled.JSON200.Status)
+
+	// 执行中的任务在 handler 返回后才变为 canceled
+	canceled, err = h.Client.CancelOperationWithResponse(h.Ctx, running.Id)
+	require.NoError(t, err)
+	require.NotNil(t, canceled.JSON200)
+	assert.Equal(t, codegenTest.Running, canceled.JSON200.Status)
+	op, err := codegenTest.WaitForOperation(h.Ctx, h.Client, running.Id, fastPolling)
+	require.NoError(t, err)
+	assert.Equal(t, codegenTest.Canceled, op.Status)
+
+	again, err := h.Client.CancelOperationWithResponse(h.Ctx, running.Id)
+	require.NoError(t, err)
+	require.NotNil(t, again.JSON409)
+
+	missing, err := h.Client.GetOperationWithResponse(h.Ctx, "missing")
+	require.NoError(t, err)
+	require.NotNil(t, missing.JSON404)
+	_, err = codegenTest.WaitForOperation(h.Ctx, h.Client, "missing", fastPolling)
+	var responseErr *codegenTest.ResponseError
+	require.ErrorAs(t, err, &responseErr)
+	assert.Equal(t, http.StatusNotFound, responseErr.StatusCode)
+	assert.Empty(t, h.Store.Find(nil, 0))
+}
+
+func TestRestart(t *testing.T) {
+	store, err := operations.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	var logs bytes.Buffer
+	store.Logger = log.New(&logs, "", 0)
+	now := time.Now().UTC()
+	job := func(id string, status codegenTest.OperationStatus, at time.Time) *operations.Job {
+		return &operations.Job{
+			Operation: codegenTest.Operation{Id: id, OperationId: "BulkImportPets", Status: status, CreatedAt: at, UpdatedAt: at},
+			Request: operations.Request{
+				Method: http.MethodPost,
+				URL:    "/api/pets:bulk",
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   []byte(`[{"name":"rex"}]`),
+			},
+		}
+	}
+	// 上次运行留下的任务：两个没有开始，一个执行到一半，一个早已结束
+	require.NoError(t, store.Save(job("second", codegenTest.Pending, now)))
+	require.NoError(t, store.Save(job("first", codegenTest.Pending, now.Add(-time.Second))))
+	require.NoError(t, store.Save(job("interrupted", codegenTest.Running, now)))
+	require.NoError(t, store.Save(job("expired", codegenTest.Succeeded, now.Add(-2*time.Hour))))
+	// 写了一半的文件跳过，不影响其它任务恢复
+	require.NoError(t, os.WriteFile(filepath.Join(store.Dir, "corrupt.json"), []byte(`{"operation":`), 0o644))
+
+	h := testkit.New(t, &testkit.Options{Operations: &operations.Options{Store: store, Workers: 1, Retention: time.Hour}})
+
+	first, err := codegenTest.WaitForOperation(h.Ctx, h.Client, "first", fastPolling)
+	require.NoError(t, err)
+	second, err := codegenTest.WaitForOperation(h.Ctx, h.Client, "second", fastPolling)
+	require.NoError(t, err)
+	assert.Equal(t, codegenTest.Succeeded, first.Status)
+	assert.Equal(t, codegenTest.Succeeded, second.Status)
+	// 按创建顺序执行
+	assert.Contains(t, first.Response.Body, `"id":1`)
+	assert.Contains(t, second.Response.Body, `"id":2`)
+
+	interrupted, err := h.Operations.Get("interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, codegenTest.Failed, interrupted.Status)
+	assert.Equal(t, "interrupted by restart", interrupted.Error.Message)
+
+	_, err = h.Operations.Get("expired")
+	assert.ErrorIs(t, err, operations.ErrNotFound)
+	_, err = store.Load("../first")
+	assert.ErrorIs(t, err, operations.ErrNotFound)
+	assert.Contains(t, logs.String(), "corrupt.json")
+}
+
+func TestShutdownKeepsPendingOperations(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+	store := operations.NewMemoryStore()
+	manager, err := operations.New(operations.Options{Swagger: swagger, BaseURL: "/api", Store: store})
+	require.NoError(t, err)
+	e := echo.New()
+	e.Use(manager.Middleware())
+	codegenTest.RegisterHandlersWithBaseURL(e, &app.EchoServer{Operations: manager}, "/api")
+
+	// 没有 Start，任务只是保存下来
+	client, err := codegenTest.NewClient("http://operations/api", codegenTest.WithHTTPClient(&testkit.Doer{Handler: e}))
+	require.NoError(t, err)
+	rsp, err := client.BulkImportPets(context.Background(), nil, bulkBody("rex"), codegenTest.PreferAsync)
+	require.NoError(t, err)
+	parsed, err := codegenTest.ParseBulkImportPetsResponse(rsp)
+	require.NoError(t, err)
+	require.NotNil(t, parsed.JSON202)
+	require.NoError(t, manager.Shutdown(context.Background()))
+
+	jobs, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, codegenTest.Pending, jobs[0].Operation.Status)
+	assert.Empty(t, jobs[0].Request.Header.Get("Prefer"))
+	assert.JSONEq(t, `[{"name":"rex"}]`, string(jobs[0].Request.Body))
+}
+
+// principalServer 记录重放时看到的身份与 Authorization
+type principalServer struct {
+	*app.EchoServer
+	seen chan [2]string
+}
+
+func (s *principalServer) BulkImportPets(ctx echo.Context, params codegenTest.BulkImportPetsParams) error {
+	principal, _ := operations.PrincipalFromContext(ctx.Request().Context())
+	s.seen <- [2]string{principal, ctx.Request().Header.Get(echo.HeaderAuthorization)}
+	return s.EchoServer.BulkImportPets(ctx, params)
+}
+
+func TestCredentialsAreNotStored(t *testing.T) {
+	store := operations.NewMemoryStore()
+	server := &principalServer{EchoServer: &app.EchoServer{}, seen: make(chan [2]string, 1)}
+	h := testkit.New(t, &testkit.Options{Server: server, Operations: &operations.Options{
+		Store: store,
+		Principal: func(req *http.Request) (string, error) {
+			if req.Header.Get(echo.HeaderAuthorization) != "Bearer token-of-alice" {
+				return "", echo.ErrUnauthorized
+			}
+			return "alice", nil
+		},
+	}})
+	server.Operations = h.Operations
+	credentials := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer token-of-alice")
+		req.Header.Set("Cookie", "session=secret")
+		req.Header.Set("X-Api-Key", "secret")
+		req.Header.Set("X-Request-Id", "kept")
+		return nil
+	}
+
+	rsp, err := h.Client.BulkImportPetsWithResponse(h.Ctx, nil, bulkBody("rex"), codegenTest.PreferAsync, credentials)
+	require.NoError(t, err)
+	require.NotNil(t, rsp.JSON202, string(rsp.Body))
+	assert.Equal(t, [2]string{"alice", ""}, <-server.seen)
+	_, err = codegenTest.WaitForOperation(h.Ctx, h.Client, rsp.JSON202.Id, fastPolling)
+	require.NoError(t, err)
+
+	job, err := store.Load(rsp.JSON202.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", job.Request.Principal)
+	assert.Empty(t, job.Request.Header.Get(echo.HeaderAuthorization))
+	assert.Empty(t, job.Request.Header.Get("Cookie"))
+	assert.Empty(t, job.Request.Header.Get("X-Api-Key"))
+	assert.Equal(t, "kept", job.Request.Header.Get("X-Request-Id"))
+
+	// 无法解析身份时不提交
+	rsp, err = h.Client.BulkImportPetsWithResponse(h.Ctx, nil, bulkBody("rex"), codegenTest.PreferAsync)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rsp.StatusCode())
+}
@@ -0,0 +1,190 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	codegenTest "demo/oapi-codegen-go"
+)
+
+// Job 一个后台执行的请求及其状态，Store 保存的单位
+type Job struct {
+	Operation codegenTest.Operation `json:"operation"`
+	Request   Request               `json:"request"`
+}
+
+// Request 重放请求所需的快照
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+	// Principal Options.Principal 解析的调用方身份，凭证本身不保存
+	Principal string `json:"principal,omitempty"`
+}
+
+// Store 保存任务。Load 找不到时返回 ErrNotFound，Delete 不存在的任务不是错误。
+// 实现需要可以并发使用，返回的 Job 不能与 Store 内部共享
+type Store interface {
+	Save(job *Job) error
+	Load(id string) (*Job, error)
+	List() ([]*Job, error)
+	Delete(id string) error
+}
+
+// MemoryStore 进程内的 Store，重启后任务丢失
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string][]byte
+}
+
+// NewMemoryStore 创建空的 MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: map[string][]byte{}}
+}
+
+// Save 按 JSON 保存副本
+func (s *MemoryStore) Save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Operation.Id] = data
+	return nil
+}
+
+func (s *MemoryStore) Load(id string) (*Job, error) {
+	s.mu.Lock()
+	data, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return decodeJob(data)
+}
+
+func (s *MemoryStore) List() ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, data := range s.jobs {
+		job, err := decodeJob(data)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// FileStore 每个任务一个 <id>.json 文件，先写临时文件再改名，进程崩溃时不会留下半个文件。
+// 保存之前 Manager 已经删除了凭证（见 Options.RedactHeaders），请求体仍然原样保存
+type FileStore struct {
+	Dir string
+	// Logger 记录 List 跳过的文件，为 nil 时使用 log.Default()
+	Logger *log.Logger
+}
+
+// NewFileStore 创建 dir 并返回以它为目录的 FileStore
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+func (s *FileStore) Save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, job.Operation.Id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(job.Operation.Id))
+}
+
+func (s *FileStore) Load(id string) (*Job, error) {
+	// id 来自 URL，不允许借此读取目录之外的文件
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeJob(data)
+}
+
+// List 跳过无法读取或解析的文件并记录日志，单个损坏的任务不影响其它任务恢复
+func (s *FileStore) List() ([]*Job, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	logger := s.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	jobs := make([]*Job, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var job *Job
+			if job, err = decodeJob(data); err == nil {
+				jobs = append(jobs, job)
+				continue
+			}
+		}
+		logger.Printf("operations: skipping %s: %v", path, err)
+	}
+	return jobs, nil
+}
+
+func (s *FileStore) Delete(id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func decodeJob(data []byte) (*Job, error) {
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,248 @@
+package proptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Case 一次具体的请求。Valid 为 false 时 Mutation 说明它违反了哪条约束
+type Case struct {
+	OperationID string
+	Method      string
+	// Path 为文档中的模板，例如 /pets/{id}
+	Path       string
+	PathParams map[string]interface{}
+	Query      map[string]interface{}
+	// Body 为 nil 且 RawBody 为空时不发送请求体
+	Body        interface{}
+	RawBody     string
+	ContentType string
+	Valid       bool
+	Mutation    string
+
+	operation *openapi3.Operation
+	// item 提供 path 级的参数，为 nil 时只使用 operation 的参数
+	item *openapi3.PathItem
+	// locked 为被变异的值，缩小时保持不动，否则用例会变成合法的
+	locked string
+}
+
+func (c Case) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", c.Method, c.expandPath())
+	if query := c.queryValues().Encode(); query != "" {
+		b.WriteString("?" + query)
+	}
+	if body, ok := c.body(); ok {
+		fmt.Fprintf(&b, " [%s] %s", c.ContentType, body)
+	}
+	if !c.Valid {
+		fmt.Fprintf(&b, " (invalid: %s)", c.Mutation)
+	}
+	return b.String()
+}
+
+func (c Case) expandPath() string {
+	path := c.Path
+	for name, value := range c.PathParams {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(formatValue(value)))
+	}
+	return path
+}
+
+func (c Case) queryValues() url.Values {
+	values := url.Values{}
+	for name, value := range c.Query {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				values.Add(name, formatValue(item))
+			}
+			continue
+		}
+		values.Set(name, formatValue(value))
+	}
+	return values
+}
+
+func (c Case) body() ([]byte, bool) {
+	if c.RawBody != "" {
+		return []byte(c.RawBody), true
+	}
+	if c.Body == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(c.Body)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// request 与生成的 NewXxxRequest 一样，把 path 解析为相对 server 的地址
+func (c Case) request(ctx context.Context, server string) (*http.Request, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	queryURL, err := serverURL.Parse("." + c.expandPath())
+	if err != nil {
+		return nil, err
+	}
+	queryURL.RawQuery = c.queryValues().Encode()
+
+	var reader io.Reader
+	data, hasBody := c.body()
+	if hasBody {
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, c.Method, queryURL.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if hasBody {
+		req.Header.Set("Content-Type", c.ContentType)
+	}
+	return req, nil
+}
+
+func (c Case) clone() Case {
+	c.PathParams = cloneMap(c.PathParams)
+	c.Query = cloneMap(c.Query)
+	if obj, ok := c.Body.(map[string]interface{}); ok {
+		c.Body = cloneMap(obj)
+	}
+	return c
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// shrink 贪心地缩小失败用例：依次尝试删除可选的 query 参数和 body 属性、
+// 把未锁定的值换成最简单的合法值，只要 fails 仍然成立就接受这一步，直到不能再缩小
+func shrink(c Case, fails func(Case) bool) Case {
+	for {
+		shrunk := false
+		for _, candidate := range c.candidates() {
+			if fails(candidate) {
+				c, shrunk = candidate, true
+				break
+			}
+		}
+		if !shrunk {
+			return c
+		}
+	}
+}
+
+func (c Case) candidates() []Case {
+	var result []Case
+	for _, param := range c.parameters() {
+		key := param.In + "." + param.Name
+		if key == c.locked || param.Schema == nil {
+			continue
+		}
+		values := c.Query
+		if param.In == openapi3.ParameterInPath {
+			values = c.PathParams
+		}
+		current, ok := values[param.Name]
+		if !ok {
+			continue
+		}
+		if !param.Required {
+			candidate := c.clone()
+			delete(candidate.paramValues(param.In), param.Name)
+			result = append(result, candidate)
+		}
+		if min := minimal(param.Schema); min != nil && !reflect.DeepEqual(min, current) {
+			candidate := c.clone()
+			candidate.paramValues(param.In)[param.Name] = min
+			result = append(result, candidate)
+		}
+	}
+
+	obj, ok := c.Body.(map[string]interface{})
+	schema := c.bodySchema()
+	if !ok || schema == nil {
+		return result
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if "body."+name == c.locked {
+			continue
+		}
+		if !isRequired(schema, name) {
+			candidate := c.clone()
+			delete(candidate.Body.(map[string]interface{}), name)
+			result = append(result, candidate)
+		}
+		if min := minimal(schema.Properties[name]); min != nil && !reflect.DeepEqual(min, obj[name]) {
+			candidate := c.clone()
+			candidate.Body.(map[string]interface{})[name] = min
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func (c Case) paramValues(in string) map[string]interface{} {
+	if in == openapi3.ParameterInPath {
+		return c.PathParams
+	}
+	return c.Query
+}
+
+// parameters 合并 path 级与 operation 级的参数，同名同位置时 operation 级优先
+func (c Case) parameters() []*openapi3.Parameter {
+	var params []*openapi3.Parameter
+	if c.operation == nil {
+		return nil
+	}
+	if c.item != nil {
+		for _, ref := range c.item.Parameters {
+			if ref.Value == nil || c.operation.Parameters.GetByInAndName(ref.Value.In, ref.Value.Name) != nil {
+				continue
+			}
+			params = append(params, ref.Value)
+		}
+	}
+	for _, ref := range c.operation.Parameters {
+		if ref.Value != nil {
+			params = append(params, ref.Value)
+		}
+	}
+	return params
+}
+
+func (c Case) bodySchema() *openapi3.Schema {
+	if c.operation == nil || c.operation.RequestBody == nil || c.operation.RequestBody.Value == nil {
+		return nil
+	}
+	media := c.operation.RequestBody.Value.Content.Get(jsonContentType)
+	if media == nil {
+		return nil
+	}
+	return flattenObject(media.Schema)
+}
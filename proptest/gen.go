@@ -0,0 +1,286 @@
+package proptest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const maxDepth = 6
+
+// generator 按 schema 生成随机但合法的值，同一个 seed 生成的序列相同
+type generator struct {
+	rnd *rand.Rand
+}
+
+func (g *generator) value(ref *openapi3.SchemaRef, depth int) interface{} {
+	if ref == nil || ref.Value == nil || depth > maxDepth {
+		return nil
+	}
+	schema := ref.Value
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rnd.Intn(len(schema.Enum))]
+	}
+	if len(schema.AllOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, sub := range schema.AllOf {
+			if obj, ok := g.value(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return g.value(schema.OneOf[g.rnd.Intn(len(schema.OneOf))], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return g.value(schema.AnyOf[g.rnd.Intn(len(schema.AnyOf))], depth+1)
+	}
+
+	switch schema.Type {
+	case openapi3.TypeInteger:
+		lo, hi := intBounds(schema)
+		return lo + g.rnd.Int63n(hi-lo+1)
+	case openapi3.TypeNumber:
+		lo, hi := -1000.0, 1000.0
+		if schema.Min != nil {
+			lo = *schema.Min
+		}
+		if schema.Max != nil {
+			hi = *schema.Max
+		}
+		return lo + g.rnd.Float64()*(hi-lo)
+	case openapi3.TypeBoolean:
+		return g.rnd.Intn(2) == 0
+	case openapi3.TypeString:
+		return g.str(schema)
+	case openapi3.TypeArray:
+		lo, hi := int(schema.MinItems), int(schema.MinItems)+3
+		if schema.MaxItems != nil && int(*schema.MaxItems) < hi {
+			hi = int(*schema.MaxItems)
+		}
+		n := lo
+		if hi > lo {
+			n += g.rnd.Intn(hi - lo + 1)
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, g.value(schema.Items, depth+1))
+		}
+		return items
+	case openapi3.TypeObject, "":
+		obj := make(map[string]interface{})
+		for _, name := range sortedProperties(schema) {
+			if !isRequired(schema, name) && g.rnd.Intn(2) == 0 {
+				continue
+			}
+			prop := schema.Properties[name]
+			if prop.Value != nil && prop.Value.ReadOnly {
+				continue
+			}
+			obj[name] = g.value(prop, depth+1)
+		}
+		return obj
+	}
+	return nil
+}
+
+var formatSamples = map[string][]string{
+	"date":      {"2023-01-01", "1999-12-31"},
+	"date-time": {"2023-01-01T00:00:00Z", "1999-12-31T23:59:59+08:00"},
+	"email":     {"user@example.com", "pet@shelter.org"},
+	"uuid":      {"3fa85f64-5717-4562-b3fc-2c963f66afa6", "00000000-0000-4000-8000-000000000000"},
+	"ipv4":      {"192.0.2.1", "10.0.0.1"},
+}
+
+const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+func (g *generator) str(schema *openapi3.Schema) string {
+	if samples, ok := formatSamples[schema.Format]; ok {
+		return samples[g.rnd.Intn(len(samples))]
+	}
+	lo := int(schema.MinLength)
+	hi := lo + 12
+	if schema.MaxLength != nil && int(*schema.MaxLength) < hi {
+		hi = int(*schema.MaxLength)
+	}
+	n := lo
+	if hi > lo {
+		n += g.rnd.Intn(hi - lo + 1)
+	}
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(alphabet[g.rnd.Intn(len(alphabet))])
+	}
+	return b.String()
+}
+
+// minimal 返回最简单的合法值，用于缩小失败用例
+func minimal(ref *openapi3.SchemaRef) interface{} {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	schema := ref.Value
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	switch schema.Type {
+	case openapi3.TypeInteger:
+		lo, hi := intBounds(schema)
+		switch {
+		case lo > 0:
+			return lo
+		case hi < 0:
+			return hi
+		}
+		return int64(0)
+	case openapi3.TypeString:
+		if samples, ok := formatSamples[schema.Format]; ok {
+			return samples[0]
+		}
+		return strings.Repeat("a", int(schema.MinLength))
+	case openapi3.TypeBoolean:
+		return false
+	}
+	return nil
+}
+
+// intBounds 默认范围取 [0, 1000]，并受 format 与 minimum/maximum 约束
+func intBounds(schema *openapi3.Schema) (int64, int64) {
+	lo, hi := int64(0), int64(1000)
+	if schema.Format == "int32" {
+		lo, hi = int64(math.Max(float64(lo), math.MinInt32)), int64(math.Min(float64(hi), math.MaxInt32))
+	}
+	if schema.Min != nil {
+		lo = int64(math.Ceil(*schema.Min))
+		if schema.ExclusiveMin {
+			lo++
+		}
+		if hi < lo {
+			hi = lo + 1000
+		}
+	}
+	if schema.Max != nil {
+		hi = int64(math.Floor(*schema.Max))
+		if schema.ExclusiveMax {
+			hi--
+		}
+		if lo > hi {
+			lo = hi - 1000
+		}
+	}
+	return lo, hi
+}
+
+// paramMutations 返回让参数非法的取值及其描述
+func paramMutations(schema *openapi3.Schema) []mutation {
+	var result []mutation
+	switch schema.Type {
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		if schema.Min != nil {
+			result = append(result, mutation{"below minimum", int64(math.Ceil(*schema.Min)) - 1})
+		}
+		if schema.Max != nil {
+			result = append(result, mutation{"above maximum", int64(math.Floor(*schema.Max)) + 1})
+		}
+		result = append(result, mutation{"not a number", "abc"})
+		if schema.Type == openapi3.TypeInteger {
+			result = append(result, mutation{"not an integer", "1.5"})
+		}
+	case openapi3.TypeString:
+		if len(schema.Enum) > 0 {
+			result = append(result, mutation{"not in enum", "not-in-enum"})
+		}
+		if schema.MaxLength != nil {
+			result = append(result, mutation{"too long", strings.Repeat("a", int(*schema.MaxLength)+1)})
+		}
+	case openapi3.TypeArray:
+		if schema.Items != nil && schema.Items.Value != nil {
+			for _, m := range paramMutations(schema.Items.Value) {
+				result = append(result, mutation{"item " + m.name, []interface{}{m.value}})
+			}
+		}
+	}
+	return result
+}
+
+// wrongType 返回与 schema 类型不符的值
+func wrongType(schema *openapi3.Schema) interface{} {
+	switch schema.Type {
+	case openapi3.TypeString:
+		return 123
+	case openapi3.TypeObject:
+		return "object"
+	case openapi3.TypeArray:
+		return map[string]interface{}{}
+	}
+	return "string"
+}
+
+type mutation struct {
+	name  string
+	value interface{}
+}
+
+func sortedProperties(schema *openapi3.Schema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isRequired(schema *openapi3.Schema, name string) bool {
+	for _, r := range schema.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenObject 把 allOf 合并成一个对象 schema，便于按属性生成非法 body
+func flattenObject(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	schema := ref.Value
+	if len(schema.AllOf) == 0 {
+		if schema.Type != openapi3.TypeObject && len(schema.Properties) == 0 {
+			return nil
+		}
+		return schema
+	}
+	merged := &openapi3.Schema{Type: openapi3.TypeObject, Properties: openapi3.Schemas{}}
+	for _, sub := range append(openapi3.SchemaRefs{{Value: &openapi3.Schema{Properties: schema.Properties, Required: schema.Required}}}, schema.AllOf...) {
+		flat := flattenObject(sub)
+		if flat == nil {
+			continue
+		}
+		for name, prop := range flat.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, flat.Required...)
+	}
+	return merged
+}
+
+func formatValue(v interface{}) string {
+	switch x := v.(type) {
+	case float64:
+		if x == math.Trunc(x) {
+			return fmt.Sprintf("%d", int64(x))
+		}
+		return fmt.Sprintf("%g", x)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
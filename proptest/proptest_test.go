@@ -0,0 +1,122 @@
+package proptest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRunner(t *testing.T, options *testkit.Options, seed int64) *Runner {
+	h := testkit.New(t, options)
+	client, ok := h.Client.ClientInterface.(*codegenTest.Client)
+	require.True(t, ok)
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	return NewRunner(swagger, client, Config{Seed: seed})
+}
+
+func TestRunConforming(t *testing.T) {
+	// 关闭响应校验中间件，由 Runner 自己检查响应
+	result, err := newRunner(t, &testkit.Options{DisableResponseValidation: true}, 42).Run(context.Background())
+	require.NoError(t, err)
+	assert.Greater(t, result.Cases, 4*DefaultIterations)
+	result.Check(t)
+}
+
+func TestRunDeterministic(t *testing.T) {
+	g1 := &generator{rnd: newRunner(t, nil, 7).rand()}
+	g2 := &generator{rnd: newRunner(t, nil, 7).rand()}
+	r := newRunner(t, nil, 7)
+	assert.Equal(t, fmtCases(r.cases(g1)), fmtCases(r.cases(g2)))
+}
+
+func TestRunCoversMutations(t *testing.T) {
+	r := newRunner(t, nil, 1)
+	var mutations []string
+	for _, c := range r.cases(&generator{rnd: r.rand()}) {
+		if !c.Valid {
+			mutations = append(mutations, c.OperationID+": "+c.Mutation)
+		}
+	}
+	for _, want := range []string{
+		"limit below minimum",
+		"limit above maximum",
+		"limit not an integer",
+		"id not an integer",
+		"missing name",
+		"wrong content type",
+		"invalid JSON",
+	} {
+		found := false
+		for _, m := range mutations {
+			found = found || strings.HasSuffix(m, want)
+		}
+		assert.True(t, found, "no case for %q in %v", want, mutations)
+	}
+}
+
+func TestPathLevelParameters(t *testing.T) {
+	r := newRunner(t, nil, 1)
+	// 把 id 移到 path 级，各个 operation 仍然要生成并变异它
+	item := r.swagger.Paths["/pets/{id}"]
+	item.Parameters = item.Get.Parameters
+	for _, op := range item.Operations() {
+		op.Parameters = nil
+	}
+	counts := map[string]int{}
+	for _, c := range r.cases(&generator{rnd: r.rand()}) {
+		if c.Path != "/pets/{id}" {
+			continue
+		}
+		if c.Valid {
+			assert.Contains(t, c.PathParams, "id", c.String())
+		} else if c.Mutation == "id not an integer" {
+			counts[c.Method]++
+		}
+	}
+	assert.Equal(t, map[string]int{"GET": 1, "DELETE": 1}, counts)
+}
+
+func TestRunShrinksFailures(t *testing.T) {
+	// 关闭请求校验后，缺少 name 的 NewPet 会被 handler 接受
+	result, err := newRunner(t, &testkit.Options{DisableRequestValidation: true, DisableResponseValidation: true}, 3).Run(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Failures)
+
+	var missingName *Failure
+	for i, f := range result.Failures {
+		if f.Case.Mutation == "missing name" {
+			missingName = &result.Failures[i]
+		}
+	}
+	require.NotNil(t, missingName)
+	assert.Contains(t, missingName.Reason, "invalid request accepted")
+	assert.Equal(t, map[string]interface{}{}, missingName.Shrunk.Body)
+}
+
+func TestShrinkRemovesOptionalValues(t *testing.T) {
+	r := newRunner(t, nil, 1)
+	operation := r.swagger.Paths["/pets"].Get
+	c := Case{
+		Method:    "GET",
+		Path:      "/pets",
+		Query:     map[string]interface{}{"tags": []interface{}{"a", "b"}, "limit": int64(21)},
+		operation: operation,
+		locked:    "query.limit",
+	}
+	shrunk := shrink(c, func(c Case) bool { return c.Query["limit"] == int64(21) })
+	assert.Equal(t, map[string]interface{}{"limit": int64(21)}, shrunk.Query)
+}
+
+func fmtCases(cases []Case) []string {
+	result := make([]string, 0, len(cases))
+	for _, c := range cases {
+		result = append(result, c.String())
+	}
+	return result
+}
@@ -0,0 +1,316 @@
+// Package proptest 根据 OpenAPI 文档为每个 operation 生成合法与非法的输入，
+// 通过生成的客户端发往服务端，检查服务端恰好接受合法的请求、拒绝非法的请求，
+// 并且所有响应都符合文档。运行结果由 seed 决定，失败用例会被缩小到最小形式。
+//
+//	result, err := proptest.NewRunner(swagger, client, proptest.Config{Seed: 1}).Run(ctx)
+//	require.NoError(t, err)
+//	result.Check(t)
+package proptest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	codegenTest "demo/oapi-codegen-go"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+const jsonContentType = "application/json"
+
+// DefaultIterations 每个 operation 生成的合法用例数
+const DefaultIterations = 20
+
+// Config 控制生成过程
+type Config struct {
+	// Seed 为 0 时取当前时间，实际使用的值记录在 Result.Seed 中以便复现
+	Seed       int64
+	Iterations int
+}
+
+// Runner 生成并执行用例
+type Runner struct {
+	swagger *openapi3.T
+	client  *codegenTest.Client
+	config  Config
+}
+
+// NewRunner swagger 使用不带前缀的文档（codegenTest.GetSwagger），
+// 前缀由 client.Server 决定；请求经过 client.Client 发出，并应用 client.RequestEditors
+func NewRunner(swagger *openapi3.T, client *codegenTest.Client, config Config) *Runner {
+	if config.Iterations <= 0 {
+		config.Iterations = DefaultIterations
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	return &Runner{swagger: swagger, client: client, config: config}
+}
+
+// Failure 一个不符合预期的用例及其缩小后的形式
+type Failure struct {
+	Case   Case
+	Shrunk Case
+	Status int
+	Reason string
+}
+
+// Result 一次运行的结果
+type Result struct {
+	Seed     int64
+	Cases    int
+	Failures []Failure
+}
+
+// Check 把每个失败报告为测试错误，附带复现所需的 seed
+func (r *Result) Check(t testing.TB) {
+	t.Helper()
+	for _, f := range r.Failures {
+		t.Errorf("proptest (seed %d): %s: %s\n\tshrunk: %s", r.Seed, f.Reason, f.Case, f.Shrunk)
+	}
+}
+
+// Run 依次执行每个 operation 的合法用例和所有变异用例。传输错误直接返回
+func (r *Runner) Run(ctx context.Context) (*Result, error) {
+	result := &Result{Seed: r.config.Seed}
+	g := &generator{rnd: r.rand()}
+
+	for _, c := range r.cases(g) {
+		result.Cases++
+		status, reason, err := r.execute(ctx, c)
+		if err != nil {
+			return result, err
+		}
+		if reason == "" {
+			continue
+		}
+		shrunk := shrink(c, func(candidate Case) bool {
+			_, reason, err := r.execute(ctx, candidate)
+			return err == nil && reason != ""
+		})
+		result.Failures = append(result.Failures, Failure{Case: c, Shrunk: shrunk, Status: status, Reason: reason})
+	}
+	return result, nil
+}
+
+func (r *Runner) rand() *rand.Rand {
+	return rand.New(rand.NewSource(r.config.Seed))
+}
+
+// cases 按 path、method 排序生成，保证同一个 seed 得到同样的序列
+func (r *Runner) cases(g *generator) []Case {
+	paths := make([]string, 0, len(r.swagger.Paths))
+	for path := range r.swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var cases []Case
+	for _, path := range paths {
+		item := r.swagger.Paths[path]
+		operations := item.Operations()
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			operation := operations[method]
+			for i := 0; i < r.config.Iterations; i++ {
+				cases = append(cases, validCase(g, path, method, item, operation))
+			}
+			cases = append(cases, invalidCases(g, path, method, item, operation)...)
+		}
+	}
+	return cases
+}
+
+func validCase(g *generator, path, method string, item *openapi3.PathItem, operation *openapi3.Operation) Case {
+	c := Case{
+		OperationID: operation.OperationID,
+		Method:      method,
+		Path:        path,
+		PathParams:  map[string]interface{}{},
+		Query:       map[string]interface{}{},
+		Valid:       true,
+		operation:   operation,
+		item:        item,
+	}
+	for _, param := range c.parameters() {
+		if !param.Required && g.rnd.Intn(2) == 0 {
+			continue
+		}
+		switch param.In {
+		case openapi3.ParameterInPath:
+			c.paramValues(param.In)[param.Name] = g.value(param.Schema, 0)
+		case openapi3.ParameterInQuery:
+			if value, ok := queryValue(param, g.value(param.Schema, 0)); ok {
+				c.Query[param.Name] = value
+			}
+		}
+	}
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if media := operation.RequestBody.Value.Content.Get(jsonContentType); media != nil {
+			c.Body = g.value(media.Schema, 0)
+			c.ContentType = jsonContentType
+		}
+	}
+	return c
+}
+
+// invalidCases 每种变异都基于一个新的合法用例，只改动一处
+func invalidCases(g *generator, path, method string, item *openapi3.PathItem, operation *openapi3.Operation) []Case {
+	var cases []Case
+	mutate := func(name, locked string, apply func(*Case)) {
+		c := validCase(g, path, method, item, operation)
+		c.Valid, c.Mutation, c.locked = false, name, locked
+		apply(&c)
+		cases = append(cases, c)
+	}
+
+	for _, param := range (Case{operation: operation, item: item}).parameters() {
+		if param.Schema == nil || param.Schema.Value == nil {
+			continue
+		}
+		if param.In != openapi3.ParameterInPath && param.In != openapi3.ParameterInQuery {
+			continue
+		}
+		for _, m := range paramMutations(param.Schema.Value) {
+			m := m
+			mutate(param.Name+" "+m.name, param.In+"."+param.Name, func(c *Case) {
+				c.paramValues(param.In)[param.Name] = m.value
+			})
+		}
+	}
+
+	body := operation.RequestBody
+	if body == nil || body.Value == nil || body.Value.Content.Get(jsonContentType) == nil {
+		return cases
+	}
+	if body.Value.Required {
+		mutate("missing body", "body", func(c *Case) { c.Body = nil })
+	}
+	mutate("invalid JSON", "body", func(c *Case) { c.RawBody = "{" })
+	mutate("wrong content type", "body", func(c *Case) { c.ContentType = "text/plain" })
+
+	schema := flattenObject(body.Value.Content.Get(jsonContentType).Schema)
+	if schema == nil {
+		return cases
+	}
+	for _, name := range sortedProperties(schema) {
+		name := name
+		prop := schema.Properties[name].Value
+		if prop == nil || prop.ReadOnly {
+			continue
+		}
+		if isRequired(schema, name) {
+			mutate("missing "+name, "body."+name, func(c *Case) {
+				delete(c.Body.(map[string]interface{}), name)
+			})
+		}
+		mutate(name+" wrong type", "body."+name, func(c *Case) {
+			c.Body.(map[string]interface{})[name] = wrongType(prop)
+		})
+	}
+	return cases
+}
+
+// execute 发出请求并返回失败原因，空字符串表示符合预期
+func (r *Runner) execute(ctx context.Context, c Case) (int, string, error) {
+	req, err := c.request(ctx, r.client.Server)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, editor := range r.client.RequestEditors {
+		if err := editor(ctx, req); err != nil {
+			return 0, "", err
+		}
+	}
+	resp, err := r.client.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+
+	status := resp.StatusCode
+	switch {
+	case status >= http.StatusInternalServerError:
+		return status, fmt.Sprintf("server error %d", status), nil
+	case c.Valid && rejected(status) && !declared(c.operation, status):
+		return status, fmt.Sprintf("valid request rejected with %d", status), nil
+	case !c.Valid && !rejected(status):
+		return status, fmt.Sprintf("invalid request accepted with %d", status), nil
+	}
+	if err := r.validateResponse(ctx, c, req, resp, data); err != nil {
+		return status, "response does not match the spec: " + err.Error(), nil
+	}
+	return status, "", nil
+}
+
+func rejected(status int) bool {
+	return status == http.StatusBadRequest || status == http.StatusUnsupportedMediaType || status == http.StatusUnprocessableEntity
+}
+
+// declared operation 单独声明了 status，例如批量导入在 atomic 模式下用 422 报告无效的项，
+// 这是合法请求的正常结果而不是拒绝
+func declared(operation *openapi3.Operation, status int) bool {
+	return operation != nil && operation.Responses.Get(status) != nil
+}
+
+// queryValue kin-openapi 拒绝空的 query 参数值（除非 allowEmptyValue），去掉生成的空字符串，
+// 全部为空时不带这个参数
+func queryValue(param *openapi3.Parameter, value interface{}) (interface{}, bool) {
+	if param.AllowEmptyValue {
+		return value, true
+	}
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, len(items) > 0
+	}
+	return value, true
+}
+
+func (r *Runner) validateResponse(ctx context.Context, c Case, req *http.Request, resp *http.Response, data []byte) error {
+	pathParams := make(map[string]string, len(c.PathParams))
+	for name, value := range c.PathParams {
+		pathParams[name] = formatValue(value)
+	}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      r.swagger,
+				Path:      c.Path,
+				PathItem:  r.swagger.Paths[c.Path],
+				Method:    c.Method,
+				Operation: c.operation,
+			},
+		},
+		Status:  resp.StatusCode,
+		Header:  resp.Header,
+		Body:    io.NopCloser(bytes.NewReader(data)),
+		Options: &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	return openapi3filter.ValidateResponse(ctx, input)
+}
@@ -0,0 +1,274 @@
+package protocodec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ListField 列表包装消息中数组字段的名称与编号：[]Pet 编码为 PetList{repeated Pet items = 1}
+const ListField = "items"
+
+// builder 把 schema 转换为 proto3 消息，所有消息放在同一个 .proto 文件中。
+//
+// 字段编号按属性名的字典序从 1 开始分配，allOf 的属性合并后一起排序。两端只要使用同一份
+// spec 就能得到相同的编号；新增属性可能改变已有字段的编号，与修改 JSON 字段名一样需要同时发布。
+type builder struct {
+	file *descriptorpb.FileDescriptorProto
+	// built schema 对应的消息名，同一个 schema 只生成一次
+	built map[*openapi3.Schema]string
+	// lists 数组项的 schema 对应的列表消息名
+	lists map[*openapi3.Schema]string
+	used  map[string]bool
+}
+
+func newBuilder(pkg string) *builder {
+	return &builder{
+		file: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(strings.ReplaceAll(pkg, ".", "/") + ".proto"),
+			Package: proto.String(pkg),
+			Syntax:  proto.String("proto3"),
+		},
+		built: make(map[*openapi3.Schema]string),
+		lists: make(map[*openapi3.Schema]string),
+		used:  make(map[string]bool),
+	}
+}
+
+// message 返回 ref 对应的消息名，必要时生成。$ref 使用组件名，内联 schema 使用 name
+func (b *builder) message(ref *openapi3.SchemaRef, name string) (string, error) {
+	if ref == nil || ref.Value == nil {
+		return "", fmt.Errorf("protocodec: %s: missing schema", name)
+	}
+	if n, ok := b.built[ref.Value]; ok {
+		return n, nil
+	}
+	if ref.Ref != "" {
+		name = ref.Ref[strings.LastIndexByte(ref.Ref, '/')+1:]
+	}
+	schema := ref.Value
+	if schemaType(schema) == openapi3.TypeArray {
+		list, err := b.list(schema.Items, name)
+		if err == nil {
+			b.built[schema] = list
+		}
+		return list, err
+	}
+	if schemaType(schema) != openapi3.TypeObject {
+		return "", fmt.Errorf("protocodec: %s: only object and array schemas can be messages", name)
+	}
+	name = b.unique(messageName(name))
+	b.built[schema] = name
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	// 先占位，递归生成的消息排在后面
+	b.file.MessageType = append(b.file.MessageType, msg)
+
+	properties, required := objectProperties(schema)
+	names := make([]string, 0, len(properties))
+	for n := range properties {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for i, property := range names {
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(fieldName(property)),
+			JsonName: proto.String(property),
+			Number:   proto.Int32(int32(i + 1)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		value := properties[property]
+		if value.Value != nil && schemaType(value.Value) == openapi3.TypeArray {
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			value = value.Value.Items
+			if value != nil && value.Value != nil && schemaType(value.Value) == openapi3.TypeArray {
+				return "", fmt.Errorf("protocodec: %s.%s: nested arrays are not supported", name, property)
+			}
+		}
+		if err := b.fieldType(field, value, name+messageName(property)); err != nil {
+			return "", fmt.Errorf("protocodec: %s.%s: %w", name, property, err)
+		}
+		// 非必填的标量使用 proto3 optional，保留“未设置”与零值的区别
+		if !required[property] && field.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED &&
+			field.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			field.Proto3Optional = proto.Bool(true)
+			field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + field.GetName())})
+		}
+		msg.Field = append(msg.Field, field)
+	}
+	return name, nil
+}
+
+// list 生成 <Item>List{repeated Item items = 1}，作为顶层数组的编码
+func (b *builder) list(items *openapi3.SchemaRef, name string) (string, error) {
+	if items == nil || items.Value == nil {
+		return "", fmt.Errorf("protocodec: %s: array without items", name)
+	}
+	if n, ok := b.lists[items.Value]; ok {
+		return n, nil
+	}
+	if items.Ref != "" {
+		name = items.Ref[strings.LastIndexByte(items.Ref, '/')+1:] + "List"
+	}
+	name = b.unique(messageName(name))
+	b.lists[items.Value] = name
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(ListField),
+		JsonName: proto.String(ListField),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+	}
+	if schemaType(items.Value) == openapi3.TypeArray {
+		return "", fmt.Errorf("protocodec: %s: nested arrays are not supported", name)
+	}
+	b.file.MessageType = append(b.file.MessageType, &descriptorpb.DescriptorProto{
+		Name:  proto.String(name),
+		Field: []*descriptorpb.FieldDescriptorProto{field},
+	})
+	if err := b.fieldType(field, items, name+"Item"); err != nil {
+		return "", fmt.Errorf("protocodec: %s: %w", name, err)
+	}
+	return name, nil
+}
+
+func (b *builder) fieldType(field *descriptorpb.FieldDescriptorProto, ref *openapi3.SchemaRef, name string) error {
+	if ref == nil || ref.Value == nil {
+		return fmt.Errorf("missing schema")
+	}
+	schema := ref.Value
+	var t descriptorpb.FieldDescriptorProto_Type
+	switch schemaType(schema) {
+	case openapi3.TypeInteger:
+		t = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		if schema.Format == "int32" {
+			t = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		}
+	case openapi3.TypeNumber:
+		t = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+		if schema.Format == "float" {
+			t = descriptorpb.FieldDescriptorProto_TYPE_FLOAT
+		}
+	case openapi3.TypeString:
+		t = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	case openapi3.TypeBoolean:
+		t = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	case openapi3.TypeObject:
+		if len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+			return fmt.Errorf("free-form objects are not supported")
+		}
+		msg, err := b.message(ref, name)
+		if err != nil {
+			return err
+		}
+		t = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		field.TypeName = proto.String("." + b.file.GetPackage() + "." + msg)
+	default:
+		return fmt.Errorf("unsupported schema type %q", schema.Type)
+	}
+	field.Type = t.Enum()
+	return nil
+}
+
+// build 生成文件描述符
+func (b *builder) build() (protoreflect.FileDescriptor, error) {
+	return protodesc.NewFile(b.file, nil)
+}
+
+func (b *builder) unique(name string) string {
+	candidate := name
+	for i := 2; b.used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	b.used[candidate] = true
+	return candidate
+}
+
+// objectProperties 合并 allOf 中各部分的属性与 required
+func objectProperties(schema *openapi3.Schema) (openapi3.Schemas, map[string]bool) {
+	properties := make(openapi3.Schemas)
+	required := make(map[string]bool)
+	for _, ref := range schema.AllOf {
+		if ref.Value == nil {
+			continue
+		}
+		p, r := objectProperties(ref.Value)
+		for name, property := range p {
+			properties[name] = property
+		}
+		for name := range r {
+			required[name] = true
+		}
+	}
+	for name, property := range schema.Properties {
+		properties[name] = property
+	}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	return properties, required
+}
+
+// schemaType 没有 type 时按 properties、items 或 allOf 推断
+func schemaType(schema *openapi3.Schema) string {
+	switch {
+	case schema.Type != "":
+		return schema.Type
+	case len(schema.Properties) > 0:
+		return openapi3.TypeObject
+	case schema.Items != nil:
+		return openapi3.TypeArray
+	}
+	for _, ref := range schema.AllOf {
+		if ref.Value != nil {
+			if t := schemaType(ref.Value); t != "" {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// messageName 转换为 CamelCase 的合法标识符，例如 find pet by id 为 FindPetById
+func messageName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "M" + b.String()
+	}
+	return b.String()
+}
+
+// fieldName 合法的字段名，原始的属性名保存在 json_name 中
+func fieldName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "f_" + name
+	}
+	return name
+}
@@ -0,0 +1,312 @@
+// Package protocodec 由 OpenAPI 组件 schema 生成 protobuf 消息，提供 application/x-protobuf 格式的 codec.Codec。
+//
+// 描述符在运行时用 descriptorpb 构造，消息用 dynamicpb 编解码，不需要 .proto 文件与 protoc：
+// 每个对象组件生成一个同名消息，另外生成 <Name>List{repeated <Name> items = 1} 表示数组。
+// 编码时按 Go 类型名找到同名组件（生成代码的模型与组件同名），与 JSON 经过相同的字段，
+// 两种格式承载的数据一致。
+//
+//	pc, err := protocodec.New(swagger, protocodec.Options{})
+//	pc.Register(codec.Default)
+package protocodec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"demo/oapi-codegen-go/codec"
+	"github.com/getkin/kin-openapi/openapi3"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// MIMEApplicationProtobuf spec 中声明的媒体类型
+const MIMEApplicationProtobuf = "application/x-protobuf"
+
+// MIMEApplicationProtobufAlias 另一个常用的 protobuf 媒体类型，按 MIMEApplicationProtobuf 处理
+const MIMEApplicationProtobufAlias = "application/protobuf"
+
+// mediaTypes Register 与 RegisterBodyDecoders 登记的全部媒体类型
+var mediaTypes = []string{MIMEApplicationProtobuf, MIMEApplicationProtobufAlias}
+
+// Options 控制包名与类型映射
+type Options struct {
+	// Package proto 包名，默认 openapi
+	Package string
+	// Types 额外的 Go 类型到组件名的映射，例如把 violations.Body 映射为 Error；
+	// 组件中没有的字段在编码时丢弃
+	Types map[reflect.Type]string
+}
+
+// Codec 实现 codec.Codec，可以并发使用
+type Codec struct {
+	file       protoreflect.FileDescriptor
+	components map[string]protoreflect.MessageDescriptor
+	lists      map[string]protoreflect.MessageDescriptor
+	types      map[reflect.Type]string
+	requests   []requestBody
+}
+
+// requestBody 某个 operation 的请求体消息，客户端按 method 与路径模板匹配
+type requestBody struct {
+	method   string
+	segments []string
+	message  protoreflect.MessageDescriptor
+}
+
+// New 为 swagger 中的全部对象组件与声明了 application/x-protobuf 的请求体生成消息。
+// 无法表示的组件（自由对象、嵌套数组等）返回错误
+func New(swagger *openapi3.T, options Options) (*Codec, error) {
+	if options.Package == "" {
+		options.Package = "openapi"
+	}
+	b := newBuilder(options.Package)
+	components := make(map[string]string)
+	lists := make(map[string]string)
+	names := make([]string, 0, len(swagger.Components.Schemas))
+	for name := range swagger.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref := &openapi3.SchemaRef{Ref: "#/components/schemas/" + name, Value: swagger.Components.Schemas[name].Value}
+		if ref.Value == nil || schemaType(ref.Value) != openapi3.TypeObject {
+			continue
+		}
+		msg, err := b.message(ref, name)
+		if err != nil {
+			return nil, err
+		}
+		list, err := b.list(ref, name+"List")
+		if err != nil {
+			return nil, err
+		}
+		components[name], lists[name] = msg, list
+	}
+
+	type pending struct {
+		method, path, message string
+	}
+	var bodies []pending
+	for _, path := range swagger.Paths.InMatchingOrder() {
+		for method, op := range swagger.Paths[path].Operations() {
+			if op.RequestBody == nil || op.RequestBody.Value == nil {
+				continue
+			}
+			media := op.RequestBody.Value.Content.Get(MIMEApplicationProtobuf)
+			if media == nil {
+				continue
+			}
+			msg, err := b.message(media.Schema, op.OperationID+"Request")
+			if err != nil {
+				return nil, err
+			}
+			bodies = append(bodies, pending{method, path, msg})
+		}
+	}
+
+	file, err := b.build()
+	if err != nil {
+		return nil, fmt.Errorf("protocodec: %w", err)
+	}
+	c := &Codec{
+		file:       file,
+		components: make(map[string]protoreflect.MessageDescriptor),
+		lists:      make(map[string]protoreflect.MessageDescriptor),
+		types:      options.Types,
+	}
+	byName := func(name string) protoreflect.MessageDescriptor {
+		return file.Messages().ByName(protoreflect.Name(name))
+	}
+	for name, msg := range components {
+		c.components[name] = byName(msg)
+		c.lists[name] = byName(lists[name])
+	}
+	for _, body := range bodies {
+		c.requests = append(c.requests, requestBody{body.method, strings.Split(strings.Trim(body.path, "/"), "/"), byName(body.message)})
+	}
+	return c, nil
+}
+
+// File 生成的文件描述符，可以用 protodesc.ToFileDescriptorProto 导出给其他语言的调用方
+func (c *Codec) File() protoreflect.FileDescriptor {
+	return c.file
+}
+
+// Descriptor 组件对应的消息
+func (c *Codec) Descriptor(component string) (protoreflect.MessageDescriptor, bool) {
+	d, ok := c.components[component]
+	return d, ok
+}
+
+func (c *Codec) MediaType() string { return MIMEApplicationProtobuf }
+
+// Register 把 c 连同 MIMEApplicationProtobufAlias 注册到 r，并调用 RegisterBodyDecoders
+func (c *Codec) Register(r *codec.Registry) {
+	r.Register(c, mediaTypes[1:]...)
+	RegisterBodyDecoders()
+}
+
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	desc, list, err := c.resolve(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if list {
+		value = map[string]interface{}{ListField: value}
+	}
+	return marshal(desc, value)
+}
+
+func (c *Codec) Unmarshal(data []byte, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("protocodec: unmarshal target must be a non-nil pointer")
+	}
+	desc, list, err := c.resolve(t.Elem())
+	if err != nil {
+		return err
+	}
+	value, err := unmarshal(desc, data, list)
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(value); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// resolve 按 Go 类型找到消息，切片使用对应的 List 消息
+func (c *Codec) resolve(t reflect.Type) (protoreflect.MessageDescriptor, bool, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, false, fmt.Errorf("protocodec: cannot encode nil")
+	}
+	messages, elem, list := c.components, t, false
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		messages, elem, list = c.lists, t.Elem(), true
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+	}
+	name, ok := c.types[elem]
+	if !ok {
+		name = elem.Name()
+	}
+	desc, ok := messages[name]
+	if !ok {
+		return nil, false, fmt.Errorf("protocodec: no message for Go type %s", t)
+	}
+	return desc, list, nil
+}
+
+// WithContentType 返回生成客户端的 RequestEditorFn：把 JSON 请求体按 operation 的请求体消息
+// 重新编码为 protobuf。客户端只有序列化后的 JSON，所以按 method 与路径模板（从末尾对齐，
+// 忽略 server 前缀）找到 operation
+func (c *Codec) WithContentType() func(ctx context.Context, req *http.Request) error {
+	return func(ctx context.Context, req *http.Request) error {
+		if req.Body == nil || req.Body == http.NoBody || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+			return nil
+		}
+		desc, ok := c.requestMessage(req.Method, req.URL.Path)
+		if !ok {
+			return fmt.Errorf("protocodec: no request body message for %s %s", req.Method, req.URL.Path)
+		}
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		value, err := decodeJSON(data)
+		if err != nil {
+			return err
+		}
+		if data, err = marshal(desc, value); err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", MIMEApplicationProtobuf)
+		req.ContentLength = int64(len(data))
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+		return nil
+	}
+}
+
+func (c *Codec) requestMessage(method, path string) (protoreflect.MessageDescriptor, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, r := range c.requests {
+		if r.method != method || len(r.segments) > len(segments) {
+			continue
+		}
+		tail := segments[len(segments)-len(r.segments):]
+		matched := true
+		for i, s := range r.segments {
+			if !strings.HasPrefix(s, "{") && s != tail[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.message, true
+		}
+	}
+	return nil, false
+}
+
+func marshal(desc protoreflect.MessageDescriptor, value interface{}) ([]byte, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := fill(msg, value); err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+func unmarshal(desc protoreflect.MessageDescriptor, data []byte, list bool) (interface{}, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	object := plainMessage(msg)
+	if !list {
+		return object, nil
+	}
+	if items, ok := object[ListField]; ok {
+		return items, nil
+	}
+	return []interface{}{}, nil
+}
+
+// jsonValue 经 JSON 得到与 JSON 格式相同的字段与取值
+func jsonValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
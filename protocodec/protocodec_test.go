@@ -0,0 +1,177 @@
+package protocodec_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/protocodec"
+	"demo/oapi-codegen-go/testkit"
+	"demo/oapi-codegen-go/violations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func newCodec(t *testing.T) *protocodec.Codec {
+	t.Helper()
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	pc, err := protocodec.New(swagger, protocodec.Options{
+		Types: map[reflect.Type]string{reflect.TypeOf(violations.Body{}): "Error"},
+	})
+	require.NoError(t, err)
+	return pc
+}
+
+func TestDescriptors(t *testing.T) {
+	pc := newCodec(t)
+
+	type field struct {
+		name     string
+		number   protoreflect.FieldNumber
+		kind     protoreflect.Kind
+		optional bool
+	}
+	fields := func(component string) []field {
+		desc, ok := pc.Descriptor(component)
+		require.True(t, ok, component)
+		var got []field
+		for i := 0; i < desc.Fields().Len(); i++ {
+			fd := desc.Fields().Get(i)
+			got = append(got, field{fd.JSONName(), fd.Number(), fd.Kind(), fd.HasOptionalKeyword()})
+		}
+		return got
+	}
+	// 属性按字典序编号，allOf 合并后一起排序；非必填的 tag 是 optional
+	assert.Equal(t, []field{
+		{"id", 1, protoreflect.Int64Kind, false},
+		{"name", 2, protoreflect.StringKind, false},
+		{"tag", 3, protoreflect.StringKind, true},
+	}, fields("Pet"))
+	assert.Equal(t, []field{
+		{"name", 1, protoreflect.StringKind, false},
+		{"tag", 2, protoreflect.StringKind, true},
+	}, fields("NewPet"))
+	assert.Equal(t, []field{
+		{"code", 1, protoreflect.Int32Kind, false},
+		{"message", 2, protoreflect.StringKind, false},
+	}, fields("Error"))
+
+	list := pc.File().Messages().ByName("PetList")
+	require.NotNil(t, list)
+	items := list.Fields().ByName(protocodec.ListField)
+	assert.True(t, items.IsList())
+	assert.Equal(t, protoreflect.FullName("openapi.Pet"), items.Message().FullName())
+}
+
+func TestRoundTripMatchesJSON(t *testing.T) {
+	pc := newCodec(t)
+	tag, empty := "cat", ""
+	for name, value := range map[string]interface{}{
+		"pet":        &codegenTest.Pet{Id: 1 << 40, Name: "kitty", Tag: &tag},
+		"empty tag":  &codegenTest.Pet{Id: 2, Name: "rex", Tag: &empty},
+		"zero value": &codegenTest.Pet{},
+		"list":       &[]codegenTest.Pet{{Id: 1, Name: "kitty", Tag: &tag}, {Id: 2, Name: "rex"}},
+		"empty list": &[]codegenTest.Pet{},
+		"new pet":    &codegenTest.NewPet{Name: "baby"},
+		"error":      &codegenTest.Error{Code: 404, Message: "pet not found"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := pc.Marshal(value)
+			require.NoError(t, err)
+			decoded := reflect.New(reflect.TypeOf(value).Elem())
+			require.NoError(t, pc.Unmarshal(data, decoded.Interface()))
+			assert.Equal(t, value, decoded.Interface())
+
+			want, err := json.Marshal(value)
+			require.NoError(t, err)
+			got, err := json.Marshal(decoded.Interface())
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+
+	// 未设置的 optional 字段与空字符串可以区分
+	var pet codegenTest.Pet
+	data, err := pc.Marshal(codegenTest.Pet{Id: 3, Name: "rex"})
+	require.NoError(t, err)
+	require.NoError(t, pc.Unmarshal(data, &pet))
+	assert.Nil(t, pet.Tag)
+
+	// Types 把 violations.Body 映射为 Error，violations 被丢弃
+	data, err = pc.Marshal(&violations.Body{Code: 400, Message: "bad", Violations: []violations.Violation{{Message: "bad"}}})
+	require.NoError(t, err)
+	var e codegenTest.Error
+	require.NoError(t, pc.Unmarshal(data, &e))
+	assert.Equal(t, codegenTest.Error{Code: 400, Message: "bad"}, e)
+
+	_, err = pc.Marshal(struct{ Name string }{"x"})
+	assert.Error(t, err)
+}
+
+func TestServer(t *testing.T) {
+	pc := newCodec(t)
+	pc.Register(codec.Default)
+
+	h := testkit.New(t, &testkit.Options{ClientOptions: []codegenTest.ClientOption{
+		codegenTest.WithRequestEditorFn(codec.WithAccept(protocodec.MIMEApplicationProtobuf)),
+		codegenTest.WithRequestEditorFn(pc.WithContentType()),
+	}})
+	tag := "dog"
+	added := h.AddPet(codegenTest.NewPet{Name: "rex", Tag: &tag})
+	require.Equal(t, http.StatusOK, added.StatusCode(), string(added.Body))
+	assert.Equal(t, protocodec.MIMEApplicationProtobuf, added.HTTPResponse.Header.Get("Content-Type"))
+	assert.Equal(t, &codegenTest.Pet{Id: 1, Name: "rex", Tag: &tag}, added.JSON200)
+
+	found := h.FindPets(nil)
+	require.NotNil(t, found.JSON200, string(found.Body))
+	assert.Equal(t, []codegenTest.Pet{*added.JSON200}, *found.JSON200)
+
+	missing := h.FindPetById(404)
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode())
+	require.NotNil(t, missing.JSONDefault)
+	assert.Equal(t, int32(http.StatusNotFound), missing.JSONDefault.Code)
+
+	// 同一个服务按 Accept 返回 JSON，数据相同
+	rec := httptest.NewRecorder()
+	h.Echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, testkit.DefaultBaseURL+"/pets", nil))
+	var pets []codegenTest.Pet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pets))
+	assert.Equal(t, *found.JSON200, pets)
+}
+
+func TestRequestValidation(t *testing.T) {
+	pc := newCodec(t)
+	pc.Register(codec.Default)
+	h := testkit.New(t, nil)
+
+	send := func(data []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, testkit.DefaultBaseURL+"/pets", strings.NewReader(string(data)))
+		req.Header.Set("Content-Type", protocodec.MIMEApplicationProtobuf)
+		rec := httptest.NewRecorder()
+		h.Echo.ServeHTTP(rec, req)
+		return rec
+	}
+	data, err := pc.Marshal(codegenTest.NewPet{Name: "rex"})
+	require.NoError(t, err)
+	rec := send(data)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// 所有字段都是零值的消息编码为空字节，与没有请求体无法区分
+	data, err = pc.Marshal(codegenTest.NewPet{})
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	rec = send(data)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request body has an error")
+
+	// 无法解码的请求体
+	rec = send([]byte("\x08\x01\xff"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
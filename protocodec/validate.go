@@ -0,0 +1,70 @@
+package protocodec
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var registerOnce sync.Once
+
+// RegisterBodyDecoders 为 MIMEApplicationProtobuf 及其别名注册 openapi3filter 的请求体解码器：
+// 请求与响应校验按 schema 生成消息解码 protobuf 请求体，编号规则与 New 相同，
+// 得到的值与 JSON 请求体的形状一致，schema 校验的结果也一致。
+// openapi3filter 只有全局的注册表，重复调用只注册一次
+func RegisterBodyDecoders() {
+	registerOnce.Do(func() {
+		for _, mediaType := range mediaTypes {
+			openapi3filter.RegisterBodyDecoder(mediaType, bodyDecoder)
+		}
+	})
+}
+
+// descriptors 以生成的描述符内容为 key，而不是 *openapi3.Schema：热更新每次加载都是新的 Schema，
+// 按指针缓存会一直增长；按内容缓存时形状相同的 schema 共用一个条目，只有请求体的结构变化才会新增
+var descriptors sync.Map // 消息名 + 确定性序列化的 FileDescriptorProto -> protoreflect.MessageDescriptor
+
+func bodyDecoder(body io.Reader, _ http.Header, schema *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	desc, err := schemaDescriptor(schema)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindOther, Cause: err}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	value, err := unmarshal(desc, data, schemaType(schema.Value) == openapi3.TypeArray)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+	return value, nil
+}
+
+// schemaDescriptor 每次都遍历 schema 生成 FileDescriptorProto，只有 protodesc.NewFile 的结果被缓存
+func schemaDescriptor(schema *openapi3.SchemaRef) (protoreflect.MessageDescriptor, error) {
+	b := newBuilder("openapi")
+	name, err := b.message(schema, "Body")
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := proto.MarshalOptions{Deterministic: true}.Marshal(b.file)
+	if err != nil {
+		return nil, err
+	}
+	key := name + "\x00" + string(fingerprint)
+	if desc, ok := descriptors.Load(key); ok {
+		return desc.(protoreflect.MessageDescriptor), nil
+	}
+	file, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	desc := file.Messages().ByName(protoreflect.Name(name))
+	descriptors.Store(key, desc)
+	return desc, nil
+}
@@ -0,0 +1,151 @@
+package protocodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fill 把 JSON 形状的值（map[string]interface{}、[]interface{}、json.Number 等）写入消息，
+// 按 json_name 匹配字段，消息中没有的键被丢弃
+func fill(m protoreflect.Message, value interface{}) error {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("protocodec: %s: expected object, got %T", m.Descriptor().FullName(), value)
+	}
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		v, ok := object[fd.JSONName()]
+		if !ok || v == nil {
+			continue
+		}
+		switch {
+		case fd.IsList():
+			items, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("protocodec: %s: expected array, got %T", fd.FullName(), v)
+			}
+			list := m.Mutable(fd).List()
+			for _, item := range items {
+				if fd.Kind() == protoreflect.MessageKind {
+					elem := list.NewElement()
+					if err := fill(elem.Message(), item); err != nil {
+						return err
+					}
+					list.Append(elem)
+					continue
+				}
+				pv, err := scalar(fd, item)
+				if err != nil {
+					return err
+				}
+				list.Append(pv)
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			if err := fill(m.Mutable(fd).Message(), v); err != nil {
+				return err
+			}
+		default:
+			pv, err := scalar(fd, v)
+			if err != nil {
+				return err
+			}
+			m.Set(fd, pv)
+		}
+	}
+	return nil
+}
+
+func scalar(fd protoreflect.FieldDescriptor, v interface{}) (protoreflect.Value, error) {
+	mismatch := func(err error) (protoreflect.Value, error) {
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("protocodec: %s: %w", fd.FullName(), err)
+		}
+		return protoreflect.Value{}, fmt.Errorf("protocodec: %s: expected %s, got %T", fd.FullName(), fd.Kind(), v)
+	}
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if s, ok := v.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.BoolKind:
+		if b, ok := v.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Int64Kind:
+		n, ok := v.(json.Number)
+		if !ok {
+			break
+		}
+		bits := 64
+		if fd.Kind() == protoreflect.Int32Kind {
+			bits = 32
+		}
+		i, err := strconv.ParseInt(n.String(), 10, bits)
+		if err != nil {
+			return mismatch(err)
+		}
+		if bits == 32 {
+			return protoreflect.ValueOfInt32(int32(i)), nil
+		}
+		return protoreflect.ValueOfInt64(i), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		n, ok := v.(json.Number)
+		if !ok {
+			break
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return mismatch(err)
+		}
+		if fd.Kind() == protoreflect.FloatKind {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	}
+	return mismatch(nil)
+}
+
+// plainMessage 转换为 JSON 形状的值。没有 presence 的标量即使是零值也输出，
+// 与 JSON 中必填字段总是出现一致；未设置的 optional 字段、消息与空列表省略
+func plainMessage(m protoreflect.Message) map[string]interface{} {
+	object := make(map[string]interface{})
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		switch {
+		case fd.IsList():
+			if !m.Has(fd) {
+				continue
+			}
+			list := m.Get(fd).List()
+			items := make([]interface{}, 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, plainValue(fd, list.Get(j)))
+			}
+			object[fd.JSONName()] = items
+		case fd.HasPresence() && !m.Has(fd):
+			continue
+		default:
+			object[fd.JSONName()] = plainValue(fd, m.Get(fd))
+		}
+	}
+	return object
+}
+
+func plainValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return plainMessage(v.Message())
+	case protoreflect.Int32Kind, protoreflect.Int64Kind:
+		return v.Int()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.BoolKind:
+		return v.Bool()
+	}
+	return v.String()
+}
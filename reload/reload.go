@@ -0,0 +1,243 @@
+// Package reload 提供可以热更新的请求校验中间件：定时检查 spec 文件或通过管理接口
+// 触发，重新加载并校验文档、构建路由后原子替换；新版本加载失败时继续使用旧版本。
+// 每次替换都会记录与上一版本的差异摘要。
+package reload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/fastvalidate"
+	"demo/oapi-codegen-go/oasdiff"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// DefaultInterval Watch 检查文件的默认间隔
+const DefaultInterval = 2 * time.Second
+
+// Options 控制加载与校验
+type Options struct {
+	// Path spec 文件路径
+	Path string
+	// Prefix 挂载的 baseURL，每个版本都按 codegenTest.Mount 改写，与 MountSwagger 一致
+	Prefix string
+	// KeepServers 见 codegenTest.MountOptions
+	KeepServers bool
+	// Interval Watch 检查文件的间隔，为 0 时使用 DefaultInterval
+	Interval time.Duration
+	// Validator 传给 fastvalidate.New，语义与 OapiRequestValidatorWithOptions 相同
+	Validator *middleware.Options
+	// Logger 为 nil 时使用 log.Default()
+	Logger *log.Logger
+}
+
+// version 一次成功加载的结果，替换后不再修改
+type version struct {
+	number    int
+	swagger   *openapi3.T
+	validator *fastvalidate.Validator
+	checksum  string
+	loadedAt  time.Time
+}
+
+// Validator 持有当前生效的文档与路由
+type Validator struct {
+	options Options
+	current atomic.Pointer[version]
+
+	mu        sync.Mutex // 串行化 Reload
+	lastError string
+}
+
+// New 加载初始版本，失败时返回错误
+func New(options Options) (*Validator, error) {
+	if options.Interval == 0 {
+		options.Interval = DefaultInterval
+	}
+	if options.Logger == nil {
+		options.Logger = log.Default()
+	}
+	v := &Validator{options: options}
+	data, err := os.ReadFile(options.Path)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := v.load(data)
+	if err != nil {
+		return nil, err
+	}
+	loaded.number = 1
+	v.current.Store(loaded)
+	options.Logger.Printf("reload: loaded %s version 1 (%d paths)", options.Path, len(loaded.swagger.Paths))
+	return v, nil
+}
+
+func (v *Validator) load(data []byte) (*version, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	swagger, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", v.options.Path, err)
+	}
+	if err := swagger.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", v.options.Path, err)
+	}
+	if err := codegenTest.Mount(swagger, v.options.Prefix, &codegenTest.MountOptions{KeepServers: v.options.KeepServers}); err != nil {
+		return nil, fmt.Errorf("mount %s: %w", v.options.Path, err)
+	}
+	validator, err := fastvalidate.New(swagger, v.options.Validator)
+	if err != nil {
+		return nil, fmt.Errorf("build router for %s: %w", v.options.Path, err)
+	}
+	sum := sha256.Sum256(data)
+	return &version{
+		swagger:   swagger,
+		validator: validator,
+		checksum:  hex.EncodeToString(sum[:]),
+		loadedAt:  time.Now(),
+	}, nil
+}
+
+// Swagger 返回当前生效的文档，调用方不能修改
+func (v *Validator) Swagger() *openapi3.T {
+	return v.current.Load().swagger
+}
+
+// FindRoute 实现 routers.Router，按当前版本查找 operation，
+// 供需要读取 operation 扩展的中间件跟随热更新
+func (v *Validator) FindRoute(req *http.Request) (*routers.Route, map[string]string, error) {
+	return v.current.Load().validator.FindRoute(req)
+}
+
+// Reload 重新读取文件。内容没有变化时返回 nil, nil；加载失败时保留当前版本并返回错误。
+// 成功时返回与上一版本的差异。
+func (v *Validator) Reload() (*oasdiff.Report, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	old := v.current.Load()
+	data, err := os.ReadFile(v.options.Path)
+	if err == nil {
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) == old.checksum {
+			v.lastError = ""
+			return nil, nil
+		}
+	}
+	var loaded *version
+	if err == nil {
+		loaded, err = v.load(data)
+	}
+	if err != nil {
+		v.lastError = err.Error()
+		v.options.Logger.Printf("reload: keeping version %d: %v", old.number, err)
+		return nil, err
+	}
+
+	loaded.number = old.number + 1
+	v.current.Store(loaded)
+	v.lastError = ""
+
+	report := oasdiff.Compare(old.swagger, loaded.swagger)
+	var summary bytes.Buffer
+	_ = report.WriteText(&summary)
+	v.options.Logger.Printf("reload: %s version %d -> %d\n%s", v.options.Path, old.number, loaded.number, summary.String())
+	return report, nil
+}
+
+// Watch 每隔 Options.Interval 调用一次 Reload，直到 ctx 结束
+func (v *Validator) Watch(ctx context.Context) {
+	ticker := time.NewTicker(v.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = v.Reload()
+		}
+	}
+}
+
+// Middleware 使用当前版本预处理好的校验器。每个请求开始时取一次版本，
+// 请求处理中发生的替换不影响它。
+func (v *Validator) Middleware() echo.MiddlewareFunc {
+	options := v.options.Validator
+	skipper := echomiddleware.DefaultSkipper
+	if options != nil && options.Skipper != nil {
+		skipper = options.Skipper
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+			if err := v.current.Load().validator.Validate(c); err != nil {
+				if options != nil && options.ErrorHandler != nil {
+					return options.ErrorHandler(c, err)
+				}
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// Status 管理接口返回的状态
+type Status struct {
+	Version   int              `json:"version"`
+	Path      string           `json:"path"`
+	Checksum  string           `json:"checksum"`
+	LoadedAt  time.Time        `json:"loadedAt"`
+	LastError string           `json:"lastError,omitempty"`
+	Changes   []oasdiff.Change `json:"changes,omitempty"`
+}
+
+// Status 返回当前版本与最近一次加载错误
+func (v *Validator) Status() Status {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	current := v.current.Load()
+	return Status{
+		Version:   current.number,
+		Path:      v.options.Path,
+		Checksum:  current.checksum,
+		LoadedAt:  current.loadedAt,
+		LastError: v.lastError,
+	}
+}
+
+// Handler 管理接口：GET 返回状态，POST 立即重新加载。
+// 加载失败时返回 422 和错误信息，旧版本继续生效。
+func (v *Validator) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		switch c.Request().Method {
+		case http.MethodGet:
+			return c.JSON(http.StatusOK, v.Status())
+		case http.MethodPost:
+			report, err := v.Reload()
+			status := v.Status()
+			if err != nil {
+				return c.JSON(http.StatusUnprocessableEntity, status)
+			}
+			if report != nil {
+				status.Changes = report.Changes
+			}
+			return c.JSON(http.StatusOK, status)
+		}
+		return echo.NewHTTPError(http.StatusMethodNotAllowed)
+	}
+}
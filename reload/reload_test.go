@@ -0,0 +1,169 @@
+package reload_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"demo/oapi-codegen-go/reload"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup 把 demo.yaml 复制到临时目录，返回文件路径、校验器与挂了校验器的 Echo
+func setup(t *testing.T, interval time.Duration) (string, *reload.Validator, *echo.Echo, *bytes.Buffer) {
+	t.Helper()
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	var logs bytes.Buffer
+	v, err := reload.New(reload.Options{
+		Path:     path,
+		Prefix:   "/api",
+		Interval: interval,
+		Logger:   log.New(&logs, "", 0),
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Any("/admin/reload", v.Handler())
+	api := e.Group("/api", v.Middleware())
+	api.GET("/pets", func(c echo.Context) error { return c.JSON(http.StatusOK, []string{}) })
+	return path, v, e, &logs
+}
+
+func get(e *echo.Echo, target string) int {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec.Code
+}
+
+func edit(t *testing.T, path, old, new string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), old)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o644))
+}
+
+func TestReload(t *testing.T) {
+	path, v, e, logs := setup(t, time.Hour)
+	assert.Equal(t, http.StatusOK, get(e, "/api/pets?limit=18"))
+
+	// 内容没有变化
+	report, err := v.Reload()
+	require.NoError(t, err)
+	assert.Nil(t, report)
+	assert.Equal(t, 1, v.Status().Version)
+
+	edit(t, path, "maximum: 20", "maximum: 15")
+	report, err = v.Reload()
+	require.NoError(t, err)
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, "request-maximum-decreased", report.Changes[0].ID)
+	assert.Equal(t, 2, v.Status().Version)
+	assert.Equal(t, http.StatusBadRequest, get(e, "/api/pets?limit=18"))
+	assert.Equal(t, http.StatusOK, get(e, "/api/pets?limit=14"))
+	assert.Contains(t, logs.String(), "version 1 -> 2")
+	assert.Contains(t, logs.String(), "maximum decreased from 20 to 15")
+}
+
+func TestReloadFailureKeepsOldVersion(t *testing.T) {
+	path, v, e, logs := setup(t, time.Hour)
+	edit(t, path, "maximum: 20", "maximum: 15")
+	edit(t, path, "paths:", "paths: [")
+
+	_, err := v.Reload()
+	require.Error(t, err)
+	status := v.Status()
+	assert.Equal(t, 1, status.Version)
+	assert.NotEmpty(t, status.LastError)
+	assert.Contains(t, logs.String(), "keeping version 1")
+	// 旧版本仍然生效：maximum 仍是 20
+	assert.Equal(t, http.StatusOK, get(e, "/api/pets?limit=18"))
+
+	// 文档能解析但不合法
+	edit(t, path, "paths: [", "paths:")
+	edit(t, path, "type: integer\n            format: int32", "type: integer\n            format: int32\n            minimum: oops")
+	_, err = v.Reload()
+	require.Error(t, err)
+	assert.Equal(t, 1, v.Status().Version)
+}
+
+func TestAdminHandler(t *testing.T) {
+	path, _, e, _ := setup(t, time.Hour)
+	edit(t, path, "maximum: 20", "maximum: 30")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status reload.Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, 2, status.Version)
+	require.Len(t, status.Changes, 1)
+	assert.Equal(t, "request-maximum-increased", status.Changes[0].ID)
+	assert.Equal(t, http.StatusOK, get(e, "/api/pets?limit=25"))
+
+	require.NoError(t, os.WriteFile(path, []byte("not: [valid"), 0o644))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"version":2`)
+	assert.Contains(t, rec.Body.String(), "lastError")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/reload", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWatch(t *testing.T) {
+	path, v, e, _ := setup(t, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.Watch(ctx)
+
+	// 替换过程中持续发送请求，每个请求都由某一个完整版本处理
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			if code := get(e, "/api/pets?limit=14"); code != http.StatusOK {
+				t.Errorf("unexpected status %d", code)
+				return
+			}
+		}
+	}()
+
+	edit(t, path, "maximum: 20", "maximum: 15")
+	require.Eventually(t, func() bool { return v.Status().Version == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusBadRequest, get(e, "/api/pets?limit=18"))
+	cancel()
+	<-done
+}
+
+func TestKeepServers(t *testing.T) {
+	data, err := os.ReadFile("../demo.yaml")
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte("\npaths:\n"), []byte("\nservers:\n  - url: https://petstore.example.com/v1\npaths:\n"), 1)
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	// 与 MountSwagger 相同：servers 只保留 origin，path 由 Prefix 接管
+	v, err := reload.New(reload.Options{Path: path, Prefix: "/api", KeepServers: true, Logger: log.New(io.Discard, "", 0)})
+	require.NoError(t, err)
+	require.Len(t, v.Swagger().Servers, 1)
+	assert.Equal(t, "https://petstore.example.com", v.Swagger().Servers[0].URL)
+	assert.Contains(t, v.Swagger().Paths, "/api/pets")
+}
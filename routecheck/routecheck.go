@@ -0,0 +1,265 @@
+// Package routecheck 在启动时核对 Echo 注册的路由与 swagger 中的 operation 是否一致。
+//
+// RegisterHandlersWithBaseURL 手工列出路由，GetSwaggerWithPrefix 另外改写 spec 的
+// path，两边写法也不同（Echo 的 :id 对应 spec 的 {id}，spec 中 /pets:bulk 这类自定义方法
+// 的冒号在 Echo 中要写成 \:），任何一边漏改都只会在请求时才暴露。Check 把两边的 path
+// 归一化后逐一比较。
+package routecheck
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+// Mismatch 的种类
+const (
+	// MissingRoute spec 中的 operation 没有对应的路由，该 path 下没有任何路由
+	MissingRoute = "missing-route"
+	// UndocumentedRoute 路由在 spec 中没有对应的 path
+	UndocumentedRoute = "undocumented-route"
+	// MethodMismatch path 两边都有，但某个 method 只出现在一边
+	MethodMismatch = "method-mismatch"
+	// ParamNameMismatch path 参数名不同，生成的 wrapper 会取不到参数
+	ParamNameMismatch = "param-name-mismatch"
+)
+
+// Options 控制核对范围
+type Options struct {
+	// Strict 为 true 时 Verify 在存在不一致时返回错误
+	Strict bool
+	// IgnorePrefixes 以这些前缀开头的路由不参与核对，例如管理接口和文档页面
+	IgnorePrefixes []string
+}
+
+// Mismatch 一处不一致
+type Mismatch struct {
+	Kind        string `json:"kind"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operationId,omitempty"`
+	Message     string `json:"message"`
+}
+
+// Report 核对结果
+type Report struct {
+	Operations int        `json:"operations"`
+	Routes     int        `json:"routes"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// OK 是否完全一致
+func (r *Report) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// MismatchError Strict 模式下 Verify 返回的错误
+type MismatchError struct {
+	Report *Report
+}
+
+func (e *MismatchError) Error() string {
+	lines := make([]string, 0, len(e.Report.Mismatches))
+	for _, m := range e.Report.Mismatches {
+		lines = append(lines, m.Message)
+	}
+	return fmt.Sprintf("routes and spec disagree (%d mismatches):\n\t%s", len(lines), strings.Join(lines, "\n\t"))
+}
+
+// Verify 核对并在 Strict 模式下把不一致转换为 *MismatchError
+func Verify(e *echo.Echo, swagger *openapi3.T, options Options) (*Report, error) {
+	report := Check(e, swagger, options)
+	if options.Strict && !report.OK() {
+		return report, &MismatchError{Report: report}
+	}
+	return report, nil
+}
+
+// endpoint 归一化后的 path 上的一个 method
+type endpoint struct {
+	method      string
+	path        string // 原始写法，用于报告
+	params      []string
+	operationID string
+	// inlineParam Echo 路由的某一段在字面量之后出现了未转义的冒号
+	inlineParam bool
+}
+
+// Check 比较 e.Routes() 与 swagger.Paths。swagger 的 path 需要已经带上前缀
+// （例如 GetSwaggerWithPrefix 的结果）。
+func Check(e *echo.Echo, swagger *openapi3.T, options Options) *Report {
+	report := &Report{Mismatches: []Mismatch{}}
+
+	spec := make(map[string]map[string]endpoint)
+	for path, item := range swagger.Paths {
+		if item == nil {
+			continue
+		}
+		key, params := normalize(path, '{')
+		for method, op := range item.Operations() {
+			if spec[key] == nil {
+				spec[key] = make(map[string]endpoint)
+			}
+			spec[key][method] = endpoint{method: method, path: path, params: params, operationID: op.OperationID}
+			report.Operations++
+		}
+	}
+
+	routes := make(map[string]map[string]endpoint)
+	for _, route := range e.Routes() {
+		if !standardMethod(route.Method) || ignored(route.Path, options.IgnorePrefixes) {
+			continue
+		}
+		key, params := normalize(route.Path, ':')
+		if routes[key] == nil {
+			routes[key] = make(map[string]endpoint)
+		}
+		routes[key][route.Method] = endpoint{method: route.Method, path: route.Path, params: params, inlineParam: inlineParam(route.Path)}
+		report.Routes++
+	}
+
+	add := func(kind string, ep endpoint, operationID, format string, args ...interface{}) {
+		report.Mismatches = append(report.Mismatches, Mismatch{
+			Kind:        kind,
+			Method:      ep.method,
+			Path:        ep.path,
+			OperationID: operationID,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+	for key, methods := range spec {
+		for method, op := range methods {
+			route, ok := routes[key][method]
+			switch {
+			case routes[key] == nil:
+				add(MissingRoute, op, op.operationID, "%s %s (%s) has no registered route", method, op.path, op.operationID)
+			case !ok:
+				add(MethodMismatch, op, op.operationID, "%s %s (%s) is in the spec but the route only serves %s",
+					method, op.path, op.operationID, strings.Join(methodsOf(routes[key]), ", "))
+			case strings.Join(route.params, ",") != strings.Join(op.params, ","):
+				add(ParamNameMismatch, route, op.operationID, "%s %s names its parameters %v but the spec path %s uses %v",
+					method, route.path, route.params, op.path, op.params)
+			}
+		}
+	}
+	for key, methods := range routes {
+		for method, route := range methods {
+			if _, ok := spec[key][method]; ok {
+				continue
+			}
+			switch {
+			case spec[key] == nil && route.inlineParam:
+				add(UndocumentedRoute, route, "", "route %s %s has no operation in the spec; Echo reads a \":\" inside a segment as a parameter, escape it as \"\\:\"",
+					method, route.path)
+			case spec[key] == nil:
+				add(UndocumentedRoute, route, "", "route %s %s has no operation in the spec", method, route.path)
+			default:
+				add(MethodMismatch, route, "", "route %s %s is registered but the spec only documents %s",
+					method, route.path, strings.Join(methodsOf(spec[key]), ", "))
+			}
+		}
+	}
+
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		a, b := report.Mismatches[i], report.Mismatches[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Kind < b.Kind
+	})
+	return report
+}
+
+// Handler 管理接口：一致时返回 200，否则返回 503 方便探针发现
+func Handler(report *Report) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		return c.JSON(status, report)
+	}
+}
+
+// normalize 把 /pets/{id} 与 /pets/:id 都转换为 /pets/{}，并返回参数名。
+// Echo 的 \: 是字面的冒号；未转义的冒号即使在段中间也开始参数，/pets:bulk 归一化为 /pets{}
+func normalize(path string, marker byte) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		switch {
+		case marker == '{' && len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}':
+			params = append(params, segment[1:len(segment)-1])
+			segments[i] = "{}"
+		case marker == ':':
+			prefix, name, ok := echoSegment(segment)
+			segments[i] = prefix
+			if ok {
+				params = append(params, name)
+				segments[i] += "{}"
+			}
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// echoSegment 按 Echo 的规则拆分一段：返回参数之前的字面量（\: 还原为 :）与参数名
+func echoSegment(segment string) (prefix, name string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		switch {
+		case segment[i] == '\\' && i+1 < len(segment) && segment[i+1] == ':':
+			b.WriteByte(':')
+			i++
+		case segment[i] == ':':
+			return b.String(), segment[i+1:], true
+		default:
+			b.WriteByte(segment[i])
+		}
+	}
+	return b.String(), "", false
+}
+
+// inlineParam path 中是否有某段在字面量之后才出现未转义的冒号，多半是忘了写 \:
+func inlineParam(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if prefix, _, ok := echoSegment(segment); ok && prefix != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func standardMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func ignored(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func methodsOf(endpoints map[string]endpoint) []string {
+	methods := make([]string, 0, len(endpoints))
+	for method := range endpoints {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
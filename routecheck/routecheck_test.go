@@ -0,0 +1,107 @@
+package routecheck_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/routecheck"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noop(c echo.Context) error { return nil }
+
+func kinds(report *routecheck.Report) []string {
+	var result []string
+	for _, m := range report.Mismatches {
+		result = append(result, m.Kind+" "+m.Method+" "+m.Path)
+	}
+	return result
+}
+
+func TestGeneratedRoutesMatchSpec(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/james")
+	require.NoError(t, err)
+	e := echo.New()
+	codegenTest.RegisterHandlersWithBaseURL(e, &app.EchoServer{Store: app.NewPetStore()}, "/james")
+	// 管理接口与 Group 注册的 RouteNotFound 不参与核对
+	e.GET("/james/admin/routes", noop)
+	e.Group("/james", func(next echo.HandlerFunc) echo.HandlerFunc { return next })
+
+	report, err := routecheck.Verify(e, swagger, routecheck.Options{Strict: true, IgnorePrefixes: []string{"/james/admin"}})
+	require.NoError(t, err)
+	assert.True(t, report.OK(), kinds(report))
+	assert.Equal(t, 8, report.Operations)
+	assert.Equal(t, 8, report.Routes)
+}
+
+func TestPrefixMismatch(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/james")
+	require.NoError(t, err)
+	e := echo.New()
+	codegenTest.RegisterHandlersWithBaseURL(e, &app.EchoServer{Store: app.NewPetStore()}, "/api")
+
+	report := routecheck.Check(e, swagger, routecheck.Options{})
+	assert.Equal(t, []string{
+		"undocumented-route DELETE /api/operations/:id",
+		"undocumented-route GET /api/operations/:id",
+		"undocumented-route GET /api/pets",
+		"undocumented-route POST /api/pets",
+		"undocumented-route DELETE /api/pets/:id",
+		"undocumented-route GET /api/pets/:id",
+		"undocumented-route POST /api/pets\\:bulk",
+		"undocumented-route GET /api/pets\\:export",
+		"missing-route DELETE /james/operations/{id}",
+		"missing-route GET /james/operations/{id}",
+		"missing-route GET /james/pets",
+		"missing-route POST /james/pets",
+		"missing-route DELETE /james/pets/{id}",
+		"missing-route GET /james/pets/{id}",
+		"missing-route POST /james/pets:bulk",
+		"missing-route GET /james/pets:export",
+	}, kinds(report))
+}
+
+func TestMethodAndParamMismatch(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+	e := echo.New()
+	e.DELETE("/api/operations/:id", noop)
+	e.GET("/api/operations/:id", noop)
+	e.GET("/api/pets", noop)
+	e.POST("/api/pets", noop)
+	e.PUT("/api/pets", noop)
+	e.GET("/api/pets/:petId", noop)
+	e.POST("/api/pets\\:bulk", noop)
+	// 没有转义，Echo 注册的是 /api/pets 之后的参数 export
+	e.GET("/api/pets:export", noop)
+
+	report, err := routecheck.Verify(e, swagger, routecheck.Options{Strict: true})
+	var mismatch *routecheck.MismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Same(t, report, mismatch.Report)
+	assert.Equal(t, []string{
+		"method-mismatch PUT /api/pets",
+		"param-name-mismatch GET /api/pets/:petId",
+		"method-mismatch DELETE /api/pets/{id}",
+		"missing-route GET /api/pets:export",
+		"undocumented-route GET /api/pets:export",
+	}, kinds(report))
+	assert.Contains(t, err.Error(), "DELETE /api/pets/{id} (DeletePet) is in the spec but the route only serves GET")
+	assert.Contains(t, err.Error(), `escape it as "\:"`)
+
+	// 非 strict 模式只报告
+	_, err = routecheck.Verify(e, swagger, routecheck.Options{})
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/routes", nil), rec)
+	require.NoError(t, routecheck.Handler(report)(c))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"kind":"param-name-mismatch"`)
+}
@@ -0,0 +1,256 @@
+package safebind
+
+import (
+	"errors"
+	"flag"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/deepmap/oapi-codegen/pkg/types"
+)
+
+// 默认情况下被恢复的 panic 视为正常的错误；加上 -safebind.strict 后 fuzz 会在
+// 遇到新的 panic 时失败，用来发现 runtime 中尚未记录的问题（见 TestPanicsBecomeErrors）
+var strict = flag.Bool("safebind.strict", false, "fail fuzz targets on recovered panics")
+
+var (
+	styles    = []string{"simple", "label", "matrix", "form", "deepObject"}
+	locations = []runtime.ParamLocation{runtime.ParamLocationPath, runtime.ParamLocationQuery, runtime.ParamLocationHeader}
+)
+
+// object 只含字符串字段：path 中的 simple/label/matrix 风格把所有值都当作 JSON 字符串，
+// 非字符串字段无法往返
+type object struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+// typedObject 用于能保留类型的 form(explode) 与 deepObject
+type typedObject struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	OK    bool   `json:"ok"`
+}
+
+type nested struct {
+	Inner *typedObject      `json:"inner,omitempty"`
+	List  []int             `json:"list,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+	Day   types.Date        `json:"day"`
+}
+
+func checkErr(t *testing.T, err error) {
+	t.Helper()
+	var pe *PanicError
+	if *strict && errors.As(err, &pe) {
+		t.Fatalf("%v\n%s", pe, pe.Stack)
+	}
+}
+
+func FuzzBindStyledParameter(f *testing.F) {
+	f.Add("5", uint8(0), false, uint8(0))
+	f.Add(".a.b", uint8(1), true, uint8(0))
+	f.Add(";p=1;p=2", uint8(2), true, uint8(0))
+	f.Add("name,baby,tag,cat", uint8(0), false, uint8(1))
+	f.Add("%zz", uint8(0), false, uint8(0))
+	f.Fuzz(func(t *testing.T, value string, style uint8, explode bool, location uint8) {
+		s := styles[int(style)%len(styles)]
+		loc := locations[int(location)%len(locations)]
+		var (
+			i   int64
+			str string
+			ids []int
+			obj object
+			m   map[string]interface{}
+			day types.Date
+		)
+		for _, dest := range []interface{}{&i, &str, &ids, &obj, &m, &day} {
+			checkErr(t, BindStyledParameterWithLocation(s, explode, "p", loc, value, dest))
+		}
+	})
+}
+
+func FuzzBindQueryParameter(f *testing.F) {
+	f.Add("p=12", uint8(3), true)
+	f.Add("p=1,2,3", uint8(3), false)
+	f.Add("name=baby&count=2", uint8(3), true)
+	f.Add("p[name]=baby&p[count]=2", uint8(4), true)
+	f.Fuzz(func(t *testing.T, raw string, style uint8, explode bool) {
+		query, err := url.ParseQuery(raw)
+		if err != nil {
+			return
+		}
+		s := styles[int(style)%len(styles)]
+		var (
+			i   int64
+			ids []int
+			obj typedObject
+			opt *int64
+			ptr *typedObject
+		)
+		for _, dest := range []interface{}{&i, &ids, &obj} {
+			checkErr(t, BindQueryParameter(s, explode, true, "p", query, dest))
+		}
+		for _, dest := range []interface{}{&opt, &ptr} {
+			checkErr(t, BindQueryParameter(s, explode, false, "p", query, dest))
+		}
+	})
+}
+
+func FuzzUnmarshalDeepObject(f *testing.F) {
+	f.Add("p[name]=baby&p[count]=2&p[ok]=true")
+	f.Add("p[inner][name]=baby&p[list][0]=1&p[list][1]=2&p[tags][a]=b&p[day]=2023-01-01")
+	f.Add("p[list][1]=2")
+	f.Fuzz(func(t *testing.T, raw string) {
+		query, err := url.ParseQuery(raw)
+		if err != nil {
+			return
+		}
+		var (
+			obj  typedObject
+			deep nested
+			m    map[string]string
+			list []string
+		)
+		for _, dest := range []interface{}{&obj, &deep, &m, &list} {
+			checkErr(t, UnmarshalDeepObject(dest, "p", query))
+		}
+	})
+}
+
+// roundTrip 先 Style 再 Bind，要求得到原值。path 位置走 BindStyledParameterWithLocation，
+// query 位置与生成的客户端一样先 url.ParseQuery 再 BindQueryParameter
+func roundTrip(t *testing.T, style string, explode bool, loc runtime.ParamLocation, value interface{}) {
+	t.Helper()
+	styled, err := StyleParamWithLocation(style, explode, "p", loc, value)
+	if err != nil {
+		t.Fatalf("%s explode=%v: style %#v: %v", style, explode, value, err)
+	}
+	dest := reflect.New(reflect.TypeOf(value))
+	if loc == runtime.ParamLocationQuery {
+		query, err := url.ParseQuery(styled)
+		if err != nil {
+			t.Fatalf("%s explode=%v: parse %q: %v", style, explode, styled, err)
+		}
+		err = BindQueryParameter(style, explode, true, "p", query, dest.Interface())
+	} else {
+		err = BindStyledParameterWithLocation(style, explode, "p", loc, styled, dest.Interface())
+	}
+	if err != nil {
+		t.Fatalf("%s explode=%v: bind %q: %v", style, explode, styled, err)
+	}
+	if got := dest.Elem().Interface(); !reflect.DeepEqual(got, value) {
+		t.Fatalf("%s explode=%v: %#v styled as %q bound back as %#v", style, explode, value, styled, got)
+	}
+}
+
+// plain 排除各风格的分隔符以及会破坏 runtime 内部拼接 JSON 的字符，
+// 这类值在 runtime 中本身就有歧义，不在往返性质的范围内。
+// 经过 JSON 的值中非法 UTF-8 会被替换成 U+FFFD，控制字符会让拼出的 JSON 无法解析，同样排除
+func plain(s string) bool {
+	return s != "" && utf8.ValidString(s) && !strings.ContainsAny(s, ",.;=&[]\"\\") &&
+		strings.IndexFunc(s, unicode.IsControl) < 0
+}
+
+// deepPlain runtime.MarshalDeepObject 不对值做 URL 转义，"%"、"+" 等字符会在
+// url.ParseQuery 时出错或被改写
+func deepPlain(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsAny(s, "%+&;#")
+}
+
+// label 与 matrix 风格的原始类型绑定时不会去掉前缀（".5"、";p=5"），只验证 simple 与 form；
+// 未展开的 form 按逗号切分，含逗号的字符串同样无法往返
+func FuzzRoundTripPrimitive(f *testing.F) {
+	f.Add(int64(5), "baby", true, 1.5)
+	f.Add(int64(-1), "a b/c?d", false, -0.25)
+	f.Fuzz(func(t *testing.T, i int64, s string, b bool, x float64) {
+		values := []interface{}{i, b, x}
+		if s != "" {
+			values = append(values, s)
+		}
+		if x != x {
+			values = values[:2] // NaN 与自身不相等
+		}
+		for _, explode := range []bool{false, true} {
+			for _, v := range values {
+				roundTrip(t, "simple", explode, runtime.ParamLocationPath, v)
+				if str, ok := v.(string); !explode && ok && strings.Contains(str, ",") {
+					continue
+				}
+				roundTrip(t, "form", explode, runtime.ParamLocationQuery, v)
+			}
+		}
+	})
+}
+
+func FuzzRoundTripSlice(f *testing.F) {
+	f.Add("cat", "dog", int64(1), int64(2))
+	f.Add("a b", "ü", int64(-3), int64(0))
+	f.Fuzz(func(t *testing.T, a, b string, x, y int64) {
+		values := []interface{}{[]int64{x, y}}
+		if plain(a) && plain(b) {
+			values = append(values, []string{a, b})
+		}
+		for _, v := range values {
+			for _, explode := range []bool{false, true} {
+				for _, style := range []string{"simple", "label", "matrix"} {
+					roundTrip(t, style, explode, runtime.ParamLocationPath, v)
+				}
+				roundTrip(t, "form", explode, runtime.ParamLocationQuery, v)
+			}
+			if _, ok := v.([]string); !ok || deepPlain(a) && deepPlain(b) {
+				roundTrip(t, "deepObject", true, runtime.ParamLocationQuery, v)
+			}
+		}
+	})
+}
+
+func FuzzRoundTripStruct(f *testing.F) {
+	f.Add("baby", "cat", int64(2), true)
+	f.Add("a b", "", int64(-1), false)
+	f.Fuzz(func(t *testing.T, name, tag string, count int64, ok bool) {
+		if plain(name) && plain(tag) {
+			for _, explode := range []bool{false, true} {
+				for _, style := range []string{"simple", "label", "matrix"} {
+					roundTrip(t, style, explode, runtime.ParamLocationPath, object{Name: name, Tag: tag})
+				}
+			}
+		}
+		typed := typedObject{Name: name, Count: count, OK: ok}
+		roundTrip(t, "form", true, runtime.ParamLocationQuery, typed)
+		if deepPlain(name) {
+			roundTrip(t, "deepObject", true, runtime.ParamLocationQuery, typed)
+		}
+	})
+}
+
+// map 只有 deepObject 能绑定回来，其它风格会返回 "can not bind to destination of type: map"
+func FuzzRoundTripMap(f *testing.F) {
+	f.Add("color", "black")
+	f.Add("a b", "")
+	f.Fuzz(func(t *testing.T, key, value string) {
+		if !plain(key) || !deepPlain(key) || !deepPlain(value) {
+			return
+		}
+		styled, err := StyleParamWithLocation("deepObject", true, "p", runtime.ParamLocationQuery, map[string]interface{}{key: value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		query, err := url.ParseQuery(styled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]string
+		if err := UnmarshalDeepObject(&got, "p", query); err != nil {
+			t.Fatalf("bind %q: %v", styled, err)
+		}
+		if want := map[string]string{key: value}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%#v styled as %q bound back as %#v", want, styled, got)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// Package safebind 包装 runtime 中解析不可信输入的参数绑定与序列化函数。
+// runtime 大量使用反射，畸形输入或不合适的目标类型会直接 panic；
+// 这里的同名函数把 panic 转换为 *PanicError，调用方只需要处理 error。
+//
+// 往返性质（先 Style 再 Bind 得到原值）由本包的 fuzz 测试覆盖，
+// runtime 本身不支持的组合见 fuzz_test.go 中的说明。
+package safebind
+
+import (
+	"fmt"
+	"net/url"
+	"runtime/debug"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+)
+
+// PanicError 被包装的函数发生了 panic
+type PanicError struct {
+	// Func 发生 panic 的 runtime 函数名
+	Func  string
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: recovered from panic: %v", e.Func, e.Value)
+}
+
+func recoverTo(name string, err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Func: name, Value: r, Stack: debug.Stack()}
+	}
+}
+
+// BindStyledParameterWithLocation 见 runtime.BindStyledParameterWithLocation
+func BindStyledParameterWithLocation(style string, explode bool, paramName string,
+	paramLocation runtime.ParamLocation, value string, dest interface{}) (err error) {
+	defer recoverTo("BindStyledParameterWithLocation", &err)
+	return runtime.BindStyledParameterWithLocation(style, explode, paramName, paramLocation, value, dest)
+}
+
+// BindQueryParameter 见 runtime.BindQueryParameter
+func BindQueryParameter(style string, explode bool, required bool, paramName string,
+	queryParams url.Values, dest interface{}) (err error) {
+	defer recoverTo("BindQueryParameter", &err)
+	return runtime.BindQueryParameter(style, explode, required, paramName, queryParams, dest)
+}
+
+// UnmarshalDeepObject 见 runtime.UnmarshalDeepObject
+func UnmarshalDeepObject(dst interface{}, paramName string, params url.Values) (err error) {
+	defer recoverTo("UnmarshalDeepObject", &err)
+	return runtime.UnmarshalDeepObject(dst, paramName, params)
+}
+
+// StyleParamWithLocation 见 runtime.StyleParamWithLocation
+func StyleParamWithLocation(style string, explode bool, paramName string,
+	paramLocation runtime.ParamLocation, value interface{}) (styled string, err error) {
+	defer recoverTo("StyleParamWithLocation", &err)
+	return runtime.StyleParamWithLocation(style, explode, paramName, paramLocation, value)
+}
@@ -0,0 +1,92 @@
+package safebind
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unexported struct {
+	name string
+	Tag  string `json:"tag"`
+}
+
+// 以下输入都会让 runtime 直接 panic，第一条来自 FuzzUnmarshalDeepObject，
+// 其余是目标类型不合适时的误用
+func TestPanicsBecomeErrors(t *testing.T) {
+	query := func(raw string) url.Values {
+		values, err := url.ParseQuery(raw)
+		require.NoError(t, err)
+		return values
+	}
+	tests := []struct {
+		name string
+		fn   string
+		call func() error
+	}{
+		{"deepObject value and object at the same key", "UnmarshalDeepObject", func() error {
+			// 只有先处理 p[k] 再处理 p[k][x] 时才会 panic，map 遍历顺序随机，用多组 key 让它必然发生
+			var dst map[string]map[string]string
+			return UnmarshalDeepObject(&dst, "p", query("p[a]=1&p[a][x]=2&p[b]=1&p[b][x]=2&p[c]=1&p[c][x]=2&p[d]=1&p[d][x]=2&"+
+				"p[e]=1&p[e][x]=2&p[f]=1&p[f][x]=2&p[g]=1&p[g][x]=2&p[h]=1&p[h][x]=2&p[i]=1&p[i][x]=2&p[j]=1&p[j][x]=2"))
+		}},
+		{"deepObject into map with int keys", "UnmarshalDeepObject", func() error {
+			var dst map[int]string
+			return UnmarshalDeepObject(&dst, "p", query("p[1]=a"))
+		}},
+		{"deepObject into unexported field", "UnmarshalDeepObject", func() error {
+			var dst unexported
+			return UnmarshalDeepObject(&dst, "p", query("p[name]=a"))
+		}},
+		{"optional query parameter without extra pointer", "BindQueryParameter", func() error {
+			var dst int
+			return BindQueryParameter("form", true, false, "p", query("p=1"), &dst)
+		}},
+		{"nil destination", "BindStyledParameterWithLocation", func() error {
+			return BindStyledParameterWithLocation("simple", false, "p", runtime.ParamLocationPath, "1", nil)
+		}},
+		{"nil value", "StyleParamWithLocation", func() error {
+			_, err := StyleParamWithLocation("simple", false, "p", runtime.ParamLocationPath, nil)
+			return err
+		}},
+		{"nil slice element", "StyleParamWithLocation", func() error {
+			_, err := StyleParamWithLocation("form", true, "p", runtime.ParamLocationQuery, []interface{}{nil})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			require.NotPanics(t, func() { err = tt.call() })
+			var pe *PanicError
+			require.True(t, errors.As(err, &pe), "got %v", err)
+			assert.Equal(t, tt.fn, pe.Func)
+			assert.NotEmpty(t, pe.Stack)
+			assert.Contains(t, err.Error(), "recovered from panic")
+		})
+	}
+}
+
+func TestPassThrough(t *testing.T) {
+	var id int64
+	require.NoError(t, BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, "12", &id))
+	assert.Equal(t, int64(12), id)
+
+	err := BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, "abc", &id)
+	require.Error(t, err)
+	var pe *PanicError
+	assert.False(t, errors.As(err, &pe))
+
+	var limit *int32
+	require.NoError(t, BindQueryParameter("form", true, false, "limit", url.Values{"limit": {"15"}}, &limit))
+	require.NotNil(t, limit)
+	assert.Equal(t, int32(15), *limit)
+
+	styled, err := StyleParamWithLocation("form", true, "tags", runtime.ParamLocationQuery, []string{"cat", "dog"})
+	require.NoError(t, err)
+	assert.Equal(t, "tags=cat&tags=dog", styled)
+}
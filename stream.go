@@ -0,0 +1,50 @@
+package codegen_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"demo/oapi-codegen-go/codec"
+)
+
+// ResponseError FindPetsStream、BulkImport 等辅助函数收到的意外响应
+type ResponseError struct {
+	StatusCode int
+	// Body 按 Error schema 解码的响应体，无法解码时为 nil
+	Body *Error
+}
+
+func (e *ResponseError) Error() string {
+	if e.Body == nil {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body.Message)
+}
+
+// FindPetsStream 以 application/x-ndjson 请求 FindPets，返回逐行解码的迭代器，
+// 客户端内存中同时只有一只宠物。迭代器用完或提前放弃时都要 Close。
+// 服务端不支持流式、按 JSON 等格式返回整个列表时，迭代器遍历解码好的列表
+//
+//	it, err := codegenTest.FindPetsStream(ctx, client, nil)
+//	defer it.Close()
+//	for it.Next() {
+//		pet := it.Value()
+//	}
+func FindPetsStream(ctx context.Context, client ClientInterface, params *FindPetsParams, reqEditors ...RequestEditorFn) (*codec.Iterator[Pet], error) {
+	rsp, err := client.FindPets(ctx, params, append(reqEditors, codec.WithAccept(codec.MIMEApplicationNDJSON))...)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode == http.StatusOK && codec.IsNDJSON(rsp.Header.Get("Content-Type")) {
+		return codec.NewIterator[Pet](rsp.Body), nil
+	}
+	parsed, err := ParseFindPetsResponse(rsp)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.JSON200 == nil {
+		return nil, &ResponseError{StatusCode: rsp.StatusCode, Body: parsed.JSONDefault}
+	}
+	return codec.NewSliceIterator(*parsed.JSON200), nil
+}
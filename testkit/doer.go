@@ -0,0 +1,26 @@
+package testkit
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// Doer 实现 HttpRequestDoer，直接调用 http.Handler，不打开任何端口
+type Doer struct {
+	Handler http.Handler
+}
+
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	// 与真实服务端收到的请求保持一致
+	if req.RequestURI == "" {
+		req.RequestURI = req.URL.RequestURI()
+	}
+	rec := httptest.NewRecorder()
+	d.Handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
@@ -0,0 +1,244 @@
+// Package testkit 在进程内启动完整的 Echo 服务（路由、请求/响应校验、错误处理），
+// 并返回通过内存 Doer 连接的 ClientWithResponses，测试无需打开端口，也无需关心 base URL。
+//
+//	h := testkit.New(t, nil)
+//	h.Seed(codegenTest.NewPet{Name: "baby"})
+//	assert.Equal(t, "baby", h.FindPetById(1).JSON200.Name)
+package testkit
+
+import (
+	"context"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/codec"
+	"demo/oapi-codegen-go/compression"
+	"demo/oapi-codegen-go/coverage"
+	"demo/oapi-codegen-go/operations"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultBaseURL 服务挂载的前缀，对测试透明
+const DefaultBaseURL = "/api"
+
+// Options 定制 Harness，nil 使用默认值
+type Options struct {
+	BaseURL string
+	// DisableRequestValidation 关闭请求校验，用于测试 handler 自身的容错
+	DisableRequestValidation bool
+	// DisableResponseValidation 关闭响应校验
+	DisableResponseValidation bool
+	// ClientOptions 追加到生成的客户端上，例如 WithRequestEditorFn
+	ClientOptions []codegenTest.ClientOption
+	// Server 替换默认的 app.EchoServer；为 nil 时使用共享 Harness.Store 的 EchoServer
+	Server codegenTest.ServerInterface
+	// Coverage 记录客户端发出的每个请求，见 NewCoverageRecorder
+	Coverage *coverage.Recorder
+	// Compression 不为 nil 时在校验之前挂载 compression.New 返回的中间件，Swagger 为空时使用带前缀的 spec
+	Compression *compression.Options
+	// Operations 不为 nil 时在校验之后挂载 operations.Middleware 并启动 worker，
+	// Swagger 与 BaseURL 为空时使用 Harness 的值；默认的 EchoServer 共享这个 Manager
+	Operations *operations.Options
+}
+
+// NewCoverageRecorder 创建与 baseURL（为空时取 DefaultBaseURL）匹配的覆盖率记录器，
+// 通常在 TestMain 中创建一个，所有 Harness 共用，m.Run 之后输出报告
+func NewCoverageRecorder(baseURL string) (*coverage.Recorder, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	swagger, err := codegenTest.GetSwaggerWithPrefix(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return coverage.NewRecorder(swagger)
+}
+
+// Harness 一个完整的进程内服务及其客户端
+type Harness struct {
+	Echo    *echo.Echo
+	Store   *app.PetStore
+	Swagger *openapi3.T
+	Client  *codegenTest.ClientWithResponses
+	// Doer 客户端使用的内存传输，可以再包装一层后通过 ClientOptions 替换
+	Doer *Doer
+	Ctx  context.Context
+	// Operations 启用了 Options.Operations 时不为 nil，测试结束时关闭
+	Operations *operations.Manager
+
+	t testing.TB
+}
+
+// New 启动服务；任何初始化失败都会直接让测试失败
+func New(t testing.TB, options *Options) *Harness {
+	t.Helper()
+	if options == nil {
+		options = &Options{}
+	}
+	baseURL := options.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	swagger, err := codegenTest.GetSwaggerWithPrefix(baseURL)
+	if err != nil {
+		t.Fatalf("testkit: load swagger: %v", err)
+	}
+
+	store := app.NewPetStore()
+	e := echo.New()
+	e.HideBanner = true
+	e.HTTPErrorHandler = app.HTTPErrorHandler
+	e.Binder = &codec.Binder{}
+	if options.Compression != nil {
+		compressionOptions := *options.Compression
+		if compressionOptions.Swagger == nil {
+			compressionOptions.Swagger = swagger
+		}
+		mw, err := compression.New(compressionOptions)
+		if err != nil {
+			t.Fatalf("testkit: compression: %v", err)
+		}
+		e.Use(mw)
+	}
+	if !options.DisableResponseValidation {
+		e.Use(ResponseValidator(t, swagger))
+	}
+	if !options.DisableRequestValidation {
+		e.Use(middleware.OapiRequestValidator(swagger))
+	}
+	var manager *operations.Manager
+	if options.Operations != nil {
+		operationsOptions := *options.Operations
+		if operationsOptions.Swagger == nil {
+			operationsOptions.Swagger = swagger
+		}
+		if operationsOptions.BaseURL == "" {
+			operationsOptions.BaseURL = baseURL
+		}
+		manager, err = operations.New(operationsOptions)
+		if err != nil {
+			t.Fatalf("testkit: create operations manager: %v", err)
+		}
+		e.Use(manager.Middleware())
+	}
+	server := options.Server
+	if server == nil {
+		server = &app.EchoServer{Store: store, Operations: manager}
+	}
+	codegenTest.RegisterHandlersWithBaseURL(e, server, baseURL)
+	if manager != nil {
+		if err := manager.Start(e); err != nil {
+			t.Fatalf("testkit: start operations manager: %v", err)
+		}
+		t.Cleanup(func() { _ = manager.Shutdown(context.Background()) })
+	}
+
+	doer := &Doer{Handler: e}
+	var transport codegenTest.HttpRequestDoer = doer
+	if options.Coverage != nil {
+		transport = options.Coverage.Doer(doer)
+	}
+	clientOptions := append([]codegenTest.ClientOption{codegenTest.WithHTTPClient(transport)}, options.ClientOptions...)
+	client, err := codegenTest.NewClientWithResponses("http://testkit"+baseURL, clientOptions...)
+	if err != nil {
+		t.Fatalf("testkit: create client: %v", err)
+	}
+
+	return &Harness{
+		Echo:       e,
+		Store:      store,
+		Operations: manager,
+		Swagger:    swagger,
+		Client:     client,
+		Doer:       doer,
+		Ctx:        context.Background(),
+		t:          t,
+	}
+}
+
+// Seed 直接写入仓库，按顺序返回分配了 id 的宠物
+func (h *Harness) Seed(pets ...codegenTest.NewPet) []codegenTest.Pet {
+	seeded := make([]codegenTest.Pet, 0, len(pets))
+	for _, pet := range pets {
+		seeded = append(seeded, h.Store.Add(pet))
+	}
+	return seeded
+}
+
+// SeedNames 按名称批量写入，没有 tag
+func (h *Harness) SeedNames(names ...string) []codegenTest.Pet {
+	pets := make([]codegenTest.NewPet, 0, len(names))
+	for _, name := range names {
+		pets = append(pets, codegenTest.NewPet{Name: name})
+	}
+	return h.Seed(pets...)
+}
+
+// 以下方法调用对应的客户端接口，传输或解析出错时直接让测试失败
+
+func (h *Harness) FindPets(params *codegenTest.FindPetsParams) *codegenTest.FindPetsResponse {
+	h.t.Helper()
+	resp, err := h.Client.FindPetsWithResponse(h.Ctx, params)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) AddPet(body codegenTest.NewPet) *codegenTest.AddPetResponse {
+	h.t.Helper()
+	resp, err := h.Client.AddPetWithResponse(h.Ctx, body)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) DeletePet(id int64) *codegenTest.DeletePetResponse {
+	h.t.Helper()
+	resp, err := h.Client.DeletePetWithResponse(h.Ctx, id)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) FindPetById(id int64) *codegenTest.FindPetByIdResponse {
+	h.t.Helper()
+	resp, err := h.Client.FindPetByIdWithResponse(h.Ctx, id)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) BulkImportPets(params *codegenTest.BulkImportPetsParams, body codegenTest.BulkImportPetsJSONRequestBody) *codegenTest.BulkImportPetsResponse {
+	h.t.Helper()
+	resp, err := h.Client.BulkImportPetsWithResponse(h.Ctx, params, body)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) ExportPets(params *codegenTest.ExportPetsParams) *codegenTest.ExportPetsResponse {
+	h.t.Helper()
+	resp, err := h.Client.ExportPetsWithResponse(h.Ctx, params)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) GetOperation(id string) *codegenTest.GetOperationResponse {
+	h.t.Helper()
+	resp, err := h.Client.GetOperationWithResponse(h.Ctx, id)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) CancelOperation(id string) *codegenTest.CancelOperationResponse {
+	h.t.Helper()
+	resp, err := h.Client.CancelOperationWithResponse(h.Ctx, id)
+	h.check(err)
+	return resp
+}
+
+func (h *Harness) check(err error) {
+	h.t.Helper()
+	if err != nil {
+		h.t.Fatalf("testkit: request failed: %v", err)
+	}
+}
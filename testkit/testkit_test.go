@@ -0,0 +1,89 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/operations"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestOperations(t *testing.T) {
+	h := New(t, nil)
+	h.Seed(codegenTest.NewPet{Name: "baby", Tag: ptr("cat")}, codegenTest.NewPet{Name: "kitty"})
+
+	assert.Equal(t, "baby", h.FindPetById(1).JSON200.Name)
+	assert.Equal(t, int32(http.StatusNotFound), h.FindPetById(99).JSONDefault.Code)
+	assert.Len(t, *h.FindPets(nil).JSON200, 2)
+	assert.Len(t, *h.FindPets(&codegenTest.FindPetsParams{Tags: &[]string{"cat"}}).JSON200, 1)
+	assert.Equal(t, http.StatusBadRequest, h.FindPets(&codegenTest.FindPetsParams{Limit: ptr(int32(1))}).StatusCode())
+	assert.Equal(t, int64(3), h.AddPet(codegenTest.NewPet{Name: "doggy"}).JSON200.Id)
+	assert.Equal(t, http.StatusNoContent, h.DeletePet(3).StatusCode())
+	assert.Equal(t, http.StatusNotFound, h.DeletePet(3).StatusCode())
+}
+
+func TestBulkAndOperations(t *testing.T) {
+	h := New(t, &Options{Operations: &operations.Options{}})
+	h.SeedNames("baby")
+
+	bulk := h.BulkImportPets(nil, codegenTest.BulkImportPetsJSONRequestBody{{"name": "kitty", "tag": "cat"}})
+	require.NotNil(t, bulk.JSON200, string(bulk.Body))
+	assert.Equal(t, int32(1), bulk.JSON200.Created)
+	assert.Len(t, *h.ExportPets(&codegenTest.ExportPetsParams{Tags: &[]string{"cat"}}).JSON200, 1)
+
+	assert.Equal(t, http.StatusNotFound, h.GetOperation("missing").StatusCode())
+	assert.Equal(t, http.StatusNotFound, h.CancelOperation("missing").StatusCode())
+}
+
+func TestErrorsRenderedAsErrorSchema(t *testing.T) {
+	h := New(t, nil)
+
+	resp, err := h.Client.AddPetWithBodyWithResponse(h.Ctx, "application/json", strings.NewReader(`{"tag":"cat"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	require.NotNil(t, resp.JSONDefault)
+	assert.Equal(t, int32(http.StatusBadRequest), resp.JSONDefault.Code)
+	assert.Contains(t, resp.JSONDefault.Message, "request body has an error")
+}
+
+func TestCustomBaseURL(t *testing.T) {
+	h := New(t, &Options{BaseURL: "/echo_test"})
+	h.SeedNames("baby")
+	assert.Equal(t, "baby", h.FindPetById(1).JSON200.Name)
+}
+
+// recordingTB 截获 Errorf，用来验证响应校验确实会报错
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// brokenServer 返回缺少 name 的宠物
+type brokenServer struct {
+	app.EchoServer
+}
+
+func (s *brokenServer) FindPetById(ctx echo.Context, id int64) error {
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+func TestResponseValidationReportsViolations(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	h := New(tb, &Options{Server: &brokenServer{}})
+
+	assert.Equal(t, http.StatusOK, h.FindPetById(1).StatusCode())
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], `property "name" is missing`)
+}
@@ -0,0 +1,72 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// ResponseValidator 按 swagger 校验每个响应，不符合时让测试失败。
+// 需要看到错误处理后的响应，所以这里直接调用 c.Error。
+func ResponseValidator(t testing.TB, swagger *openapi3.T) echo.MiddlewareFunc {
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		t.Fatalf("testkit: build router: %v", err)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			capture := &captureWriter{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = capture
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			req := c.Request()
+			route, pathParams, err := router.FindRoute(req)
+			if err != nil {
+				// 文档中不存在的路由不做响应校验
+				return nil
+			}
+			err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status:  c.Response().Status,
+				Header:  c.Response().Header(),
+				Body:    io.NopCloser(bytes.NewReader(capture.body.Bytes())),
+				Options: &openapi3filter.Options{IncludeResponseStatus: true},
+			})
+			if err != nil {
+				t.Errorf("testkit: %s %s: response does not conform to spec: %v\nbody: %s",
+					req.Method, req.URL.Path, err, capture.body.String())
+			}
+			return nil
+		}
+	}
+}
+
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 流式响应（NDJSON 等）会调用
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+// Inject 把 ctx 中的 trace 上下文写入请求头。ctx 中没有 span 时开启新 trace，
+// 保证下游总能拿到合法的 traceparent。
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		sc = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: FlagSampled}
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+// Extract 从请求头解析上游 trace 上下文，traceparent 不合法时返回 false
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.TraceState = ParseTracestate(header.Get(TracestateHeader))
+	return sc, true
+}
+
+// InjectRequestEditor 满足 RequestEditorFn 签名，用法：
+//
+//	codegenTest.NewClientWithResponses(server, codegenTest.WithRequestEditorFn(tracing.InjectRequestEditor))
+func InjectRequestEditor(ctx context.Context, req *http.Request) error {
+	Inject(ctx, req.Header)
+	return nil
+}
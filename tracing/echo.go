@@ -0,0 +1,228 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	codegenTest "demo/oapi-codegen-go"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	operationIDKey = "tracing/operation-id"
+	bindSpanKey    = "tracing/bind-span"
+)
+
+// Middleware 解析 traceparent/tracestate 并为每个请求创建 server span，
+// span 名为 swagger 中的 operationId，找不到时退回 Echo 的路由模板。
+// 需要在 OapiRequestValidator 之前 e.Use。
+func Middleware(tracer *Tracer, swagger *openapi3.T) echo.MiddlewareFunc {
+	var router routers.Router
+	if swagger != nil {
+		var err error
+		router, err = gorillamux.NewRouter(swagger)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+			if sc, ok := Extract(req.Header); ok {
+				ctx = ContextWithRemoteSpanContext(ctx, sc)
+			}
+			operationID := c.Path()
+			if router != nil {
+				if route, _, err := router.FindRoute(req); err == nil && route.Operation != nil && route.Operation.OperationID != "" {
+					operationID = route.Operation.OperationID
+				}
+			}
+			c.Set(operationIDKey, operationID)
+
+			ctx, span := tracer.Start(ctx, operationID, KindServer)
+			defer span.End()
+			span.SetAttribute("operation.id", operationID)
+			span.SetAttribute("http.method", req.Method)
+			span.SetAttribute("http.route", c.Path())
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			status := c.Response().Status
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				status = he.Code
+			}
+			span.SetAttribute("http.status_code", strconv.Itoa(status))
+			if err != nil || status >= http.StatusInternalServerError {
+				span.RecordError(err)
+			}
+			return err
+		}
+	}
+}
+
+// Trace 用一个 internal span 包裹任意中间件，例如：
+//
+//	e.Use(tracing.Trace(tracer, "validation", middleware.OapiRequestValidator(swagger)))
+//
+// span 在中间件调用 next 时结束，不包含后续处理的耗时。
+func Trace(tracer *Tracer, name string, mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			parent := c.Request().Context()
+			ctx, span := tracer.Start(parent, spanName(c, name), KindInternal)
+			c.SetRequest(c.Request().WithContext(ctx))
+			passed := false
+			err := mw(func(c echo.Context) error {
+				passed = true
+				span.End()
+				c.SetRequest(c.Request().WithContext(parent))
+				return next(c)
+			})(c)
+			if !passed {
+				span.RecordError(err)
+				span.End()
+				c.SetRequest(c.Request().WithContext(parent))
+			}
+			return err
+		}
+	}
+}
+
+// Router 包装 EchoRouter，为每条路由记录参数绑定 span；与 Handler 搭配使用：
+//
+//	codegenTest.RegisterHandlersWithBaseURL(tracing.Router(tracer, e), tracing.Handler(tracer, si), "/james")
+func Router(tracer *Tracer, router codegenTest.EchoRouter) codegenTest.EchoRouter {
+	return &tracedRouter{router: router, tracer: tracer}
+}
+
+type tracedRouter struct {
+	router codegenTest.EchoRouter
+	tracer *Tracer
+}
+
+// bind 在 ServerInterfaceWrapper 绑定参数之前开启 span，Handler 被调用时结束；
+// 绑定失败时 Handler 不会被调用，由这里记录错误并结束。
+func (r *tracedRouter) bind(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, span := r.tracer.Start(c.Request().Context(), spanName(c, "bind"), KindInternal)
+		c.Set(bindSpanKey, span)
+		err := next(c)
+		if c.Get(bindSpanKey) != nil {
+			span.RecordError(err)
+			span.End()
+			c.Set(bindSpanKey, nil)
+		}
+		return err
+	}
+}
+
+func (r *tracedRouter) middlewares(m []echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	return append([]echo.MiddlewareFunc{r.bind}, m...)
+}
+
+func (r *tracedRouter) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.CONNECT(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.DELETE(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.GET(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.HEAD(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.OPTIONS(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PATCH(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.POST(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PUT(path, h, r.middlewares(m)...)
+}
+
+func (r *tracedRouter) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.TRACE(path, h, r.middlewares(m)...)
+}
+
+// Handler 包装 ServerInterface，为业务处理创建 handler span
+func Handler(tracer *Tracer, si codegenTest.ServerInterface) codegenTest.ServerInterface {
+	return &tracedHandler{si: si, tracer: tracer}
+}
+
+type tracedHandler struct {
+	si     codegenTest.ServerInterface
+	tracer *Tracer
+}
+
+func (h *tracedHandler) run(c echo.Context, fn func() error) error {
+	if span, ok := c.Get(bindSpanKey).(*Span); ok {
+		span.End()
+		c.Set(bindSpanKey, nil)
+	}
+	parent := c.Request().Context()
+	ctx, span := h.tracer.Start(parent, spanName(c, "handler"), KindInternal)
+	defer span.End()
+	c.SetRequest(c.Request().WithContext(ctx))
+	err := fn()
+	span.RecordError(err)
+	c.SetRequest(c.Request().WithContext(parent))
+	return err
+}
+
+func (h *tracedHandler) CancelOperation(ctx echo.Context, id string) error {
+	return h.run(ctx, func() error { return h.si.CancelOperation(ctx, id) })
+}
+
+func (h *tracedHandler) GetOperation(ctx echo.Context, id string) error {
+	return h.run(ctx, func() error { return h.si.GetOperation(ctx, id) })
+}
+
+func (h *tracedHandler) FindPets(ctx echo.Context, params codegenTest.FindPetsParams) error {
+	return h.run(ctx, func() error { return h.si.FindPets(ctx, params) })
+}
+
+func (h *tracedHandler) AddPet(ctx echo.Context) error {
+	return h.run(ctx, func() error { return h.si.AddPet(ctx) })
+}
+
+func (h *tracedHandler) DeletePet(ctx echo.Context, id int64) error {
+	return h.run(ctx, func() error { return h.si.DeletePet(ctx, id) })
+}
+
+func (h *tracedHandler) FindPetById(ctx echo.Context, id int64) error {
+	return h.run(ctx, func() error { return h.si.FindPetById(ctx, id) })
+}
+
+func (h *tracedHandler) BulkImportPets(ctx echo.Context, params codegenTest.BulkImportPetsParams) error {
+	return h.run(ctx, func() error { return h.si.BulkImportPets(ctx, params) })
+}
+
+func (h *tracedHandler) ExportPets(ctx echo.Context, params codegenTest.ExportPetsParams) error {
+	return h.run(ctx, func() error { return h.si.ExportPets(ctx, params) })
+}
+
+func spanName(c echo.Context, suffix string) string {
+	operationID, _ := c.Get(operationIDKey).(string)
+	if operationID == "" {
+		operationID = c.Path()
+	}
+	return operationID + " " + suffix
+}
@@ -0,0 +1,152 @@
+// Package tracing 实现 W3C Trace Context（traceparent / tracestate）在生成的
+// Client 与 Echo Server 之间的传播，并把 span 交给可插拔的 Exporter。
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+
+	// FlagSampled 是 trace-flags 中的 sampled 位
+	FlagSampled byte = 0x01
+
+	maxTracestateMembers = 32
+)
+
+var ErrInvalidTraceparent = errors.New("tracing: invalid traceparent")
+
+// TraceID 16 字节的 trace-id
+type TraceID [16]byte
+
+// SpanID 8 字节的 parent-id / span-id
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (t *TraceID) UnmarshalText(b []byte) error { return decodeHex(string(b), t[:]) }
+
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+func (s SpanID) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *SpanID) UnmarshalText(b []byte) error { return decodeHex(string(b), s[:]) }
+
+// SpanContext 是跨进程传播的部分
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	// Remote 表示该上下文来自请求头，而不是本进程创建的 span
+	Remote bool
+}
+
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+func (sc SpanContext) IsSampled() bool { return sc.Flags&FlagSampled != 0 }
+
+// Traceparent 按 version 00 格式输出 traceparent 头
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent 解析 traceparent 头。高于 00 的版本按规范只读取前四段。
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return sc, ErrInvalidTraceparent
+	}
+	version := parts[0]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+		return sc, ErrInvalidTraceparent
+	}
+	if version == "00" && len(parts) != 4 {
+		return sc, ErrInvalidTraceparent
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceparent
+	}
+	for _, p := range parts[1:4] {
+		if !isLowerHex(p) {
+			return sc, ErrInvalidTraceparent
+		}
+	}
+	_ = decodeHex(parts[1], sc.TraceID[:])
+	_ = decodeHex(parts[2], sc.SpanID[:])
+	var flags [1]byte
+	_ = decodeHex(parts[3], flags[:])
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Remote = true
+	return sc, nil
+}
+
+// ParseTracestate 校验 tracestate 并去掉空成员；不合法时返回空串，按规范丢弃整个头。
+func ParseTracestate(value string) string {
+	var members []string
+	for _, m := range strings.Split(value, ",") {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(m, "=")
+		if !ok || k == "" || v == "" || len(k) > 256 || len(v) > 256 {
+			return ""
+		}
+		members = append(members, m)
+	}
+	if len(members) > maxTracestateMembers {
+		members = members[:maxTracestateMembers]
+	}
+	return strings.Join(members, ",")
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeHex(s string, dst []byte) error {
+	if len(s) != hex.EncodedLen(len(dst)) {
+		return fmt.Errorf("tracing: expected %d hex characters, got %d", hex.EncodedLen(len(dst)), len(s))
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
@@ -0,0 +1,156 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Span 种类
+const (
+	KindServer   = "server"
+	KindInternal = "internal"
+	KindClient   = "client"
+)
+
+// SpanData 是 span 结束后交给 Exporter 的只读快照
+type SpanData struct {
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	TraceID      TraceID           `json:"traceId"`
+	SpanID       SpanID            `json:"spanId"`
+	ParentSpanID SpanID            `json:"parentSpanId"`
+	TraceState   string            `json:"traceState,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// Tracer 创建 span，结束时导出到 Exporter
+type Tracer struct {
+	exporter Exporter
+	now      func() time.Time
+}
+
+// NewTracer 创建 Tracer；exporter 为 nil 时丢弃所有 span
+func NewTracer(exporter Exporter) *Tracer {
+	if exporter == nil {
+		exporter = nopExporter{}
+	}
+	return &Tracer{exporter: exporter, now: time.Now}
+}
+
+// Span 是一次进行中的操作
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+type spanKey struct{}
+
+// ContextWithSpan 把 span 放入 context
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext 取出当前 span，没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+type remoteKey struct{}
+
+// ContextWithRemoteSpanContext 记录从请求头解析出的上游 span
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext 返回当前 span 的上下文，其次是上游传来的上下文
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext(), true
+	}
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// Start 创建子 span：父级取自 ctx 中的 span 或上游上下文，都没有时开启新 trace
+func (t *Tracer) Start(ctx context.Context, name, kind string) (context.Context, *Span) {
+	span := &Span{tracer: t}
+	parent, ok := SpanContextFromContext(ctx)
+	if ok {
+		span.sc = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Flags:      parent.Flags,
+			TraceState: parent.TraceState,
+		}
+		span.data.ParentSpanID = parent.SpanID
+	} else {
+		span.sc = SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: FlagSampled}
+	}
+	span.data.Name = name
+	span.data.Kind = kind
+	span.data.TraceID = span.sc.TraceID
+	span.data.SpanID = span.sc.SpanID
+	span.data.TraceState = span.sc.TraceState
+	span.data.Start = t.now()
+	return ContextWithSpan(ctx, span), span
+}
+
+func (s *Span) SpanContext() SpanContext { return s.sc }
+
+// SetName 修改 span 名称，用于路由匹配之后才知道 operationId 的场景
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttribute 设置属性
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]string)
+	}
+	s.data.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError 记录错误，err 为 nil 时忽略
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Error = err.Error()
+	s.mu.Unlock()
+}
+
+// End 结束 span 并导出；重复调用无效。未采样的 span 不导出。
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	data := s.data
+	if s.data.Attributes != nil {
+		data.Attributes = make(map[string]string, len(s.data.Attributes))
+		for k, v := range s.data.Attributes {
+			data.Attributes[k] = v
+		}
+	}
+	s.mu.Unlock()
+	if s.sc.IsSampled() {
+		_ = s.tracer.exporter.Export(data)
+	}
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	// 高版本允许携带额外字段
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	assert.NoError(t, err)
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(bad)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, bad)
+	}
+}
+
+func TestClientServerPropagation(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/james")
+	require.NoError(t, err)
+	e := echo.New()
+	e.Use(Middleware(tracer, swagger))
+	e.Use(Trace(tracer, "validation", middleware.OapiRequestValidator(swagger)))
+	codegenTest.RegisterHandlersWithBaseURL(Router(tracer, e), Handler(tracer, &app.EchoServer{}), "/james")
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	client, err := codegenTest.NewClientWithResponses(server.URL+"/james",
+		codegenTest.WithRequestEditorFn(InjectRequestEditor))
+	require.NoError(t, err)
+
+	ctx, clientSpan := tracer.Start(context.Background(), "caller", KindClient)
+	resp, err := client.AddPetWithResponse(ctx, codegenTest.AddPetJSONRequestBody{Name: "baby"})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	clientSpan.End()
+
+	spans := exporter.Spans()
+	byName := map[string]SpanData{}
+	for _, s := range spans {
+		byName[s.Name] = s
+		assert.Equal(t, clientSpan.SpanContext().TraceID, s.TraceID, s.Name)
+	}
+	require.Contains(t, byName, "AddPet")
+	require.Contains(t, byName, "AddPet validation")
+	require.Contains(t, byName, "AddPet bind")
+	require.Contains(t, byName, "AddPet handler")
+
+	serverSpan := byName["AddPet"]
+	assert.Equal(t, clientSpan.SpanContext().SpanID, serverSpan.ParentSpanID)
+	assert.Equal(t, "200", serverSpan.Attributes["http.status_code"])
+	for _, name := range []string{"AddPet validation", "AddPet bind", "AddPet handler"} {
+		assert.Equal(t, serverSpan.SpanID, byName[name].ParentSpanID, name)
+	}
+}
+
+func TestValidationFailureRecorded(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	swagger, err := codegenTest.GetSwagger()
+	require.NoError(t, err)
+	e := echo.New()
+	e.Use(Middleware(tracer, swagger))
+	e.Use(Trace(tracer, "validation", middleware.OapiRequestValidator(swagger)))
+	codegenTest.RegisterHandlers(Router(tracer, e), Handler(tracer, &app.EchoServer{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/pets?limit=1", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(TracestateHeader, "congo=t61rcWkgMzE")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "FindPets validation", spans[0].Name)
+	assert.NotEmpty(t, spans[0].Error)
+	assert.Equal(t, "FindPets", spans[1].Name)
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].ParentSpanID.String())
+	assert.Equal(t, "congo=t61rcWkgMzE", spans[1].TraceState)
+	assert.Equal(t, "400", spans[1].Attributes["http.status_code"])
+}
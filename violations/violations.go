@@ -0,0 +1,356 @@
+// Package violations 把请求校验失败的 openapi3filter 错误展开成逐条的违规列表。
+//
+// 默认的 MultiErrorHandler 把 openapi3.MultiError 拼成一个字符串，RequestError 也只保留
+// 第一行，客户端无法知道具体哪个字段出错。Renderer 为每处违规给出位置、参数名、请求体内
+// 的 JSON pointer、失败的 schema 关键字以及期望值与实际值，敏感字段的实际值会被隐藏。
+//
+// 用法：
+//
+//	r := violations.New(violations.Options{})
+//	options := &middleware.Options{MultiErrorHandler: r.MultiErrorHandler, ErrorHandler: r.ErrorHandler}
+//
+// 两者返回的 *echo.HTTPError 的 Message 是 *Body，需要 HTTPErrorHandler 原样输出为 JSON。
+package violations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"demo/oapi-codegen-go/internal/pointer"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+)
+
+// 违规的位置，前四个与 openapi3.Parameter.In 相同
+const (
+	LocationQuery    = openapi3.ParameterInQuery
+	LocationPath     = openapi3.ParameterInPath
+	LocationHeader   = openapi3.ParameterInHeader
+	LocationCookie   = openapi3.ParameterInCookie
+	LocationBody     = "body"
+	LocationSecurity = "security"
+)
+
+// Redacted 替换敏感字段的实际值
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveNames Options.SensitiveNames 为 nil 时使用
+var DefaultSensitiveNames = []string{"password", "secret", "token", "authorization", "cookie", "api-key", "apikey", "api_key"}
+
+// Violation 一处违规
+type Violation struct {
+	Location  string `json:"location"`
+	Parameter string `json:"parameter,omitempty"`
+	// Pointer 指向出错值的 JSON pointer（RFC 6901），请求体为根时是 ""
+	Pointer string `json:"pointer,omitempty"`
+	// Keyword 失败的 schema 关键字，例如 minimum、required、type
+	Keyword  string      `json:"keyword,omitempty"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+	// Message 不包含实际值，可以直接展示
+	Message string `json:"message"`
+}
+
+// Body 响应体。code 与 message 与 Error schema 相同，只认识 Error 的客户端照常解析
+type Body struct {
+	Code       int32       `json:"code"`
+	Message    string      `json:"message"`
+	Violations []Violation `json:"violations"`
+}
+
+// Options 控制隐藏哪些值
+type Options struct {
+	// SensitiveNames 参数名或 JSON pointer 中任一段包含这些词（不区分大小写）时隐藏实际值，
+	// 为 nil 时使用 DefaultSensitiveNames。format 为 password 的 schema 总是隐藏。
+	SensitiveNames []string
+}
+
+// Renderer 把校验错误转换为 *Body
+type Renderer struct {
+	sensitive []string
+}
+
+// New 创建 Renderer
+func New(options Options) *Renderer {
+	names := options.SensitiveNames
+	if names == nil {
+		names = DefaultSensitiveNames
+	}
+	sensitive := make([]string, 0, len(names))
+	for _, name := range names {
+		sensitive = append(sensitive, strings.ToLower(name))
+	}
+	return &Renderer{sensitive: sensitive}
+}
+
+// MultiErrorHandler 可以直接作为 middleware.Options.MultiErrorHandler
+func (r *Renderer) MultiErrorHandler(me openapi3.MultiError) *echo.HTTPError {
+	return r.HTTPError(http.StatusBadRequest, me)
+}
+
+// ErrorHandler 可以直接作为 middleware.Options.ErrorHandler：把 Internal 中的校验错误
+// 展开成 *Body，其他错误（例如找不到路由）原样返回
+func (r *Renderer) ErrorHandler(c echo.Context, err *echo.HTTPError) error {
+	if _, ok := err.Message.(*Body); ok || err.Internal == nil {
+		return err
+	}
+	if violations := r.Violations(err.Internal); len(violations) > 0 {
+		return r.HTTPError(err.Code, err.Internal)
+	}
+	return err
+}
+
+// HTTPError 返回 Message 为 *Body 的 *echo.HTTPError，Internal 保留原始错误
+func (r *Renderer) HTTPError(code int, err error) *echo.HTTPError {
+	violations := r.Violations(err)
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return &echo.HTTPError{
+		Code: code,
+		Message: &Body{
+			Code:       int32(code),
+			Message:    strings.Join(messages, " | "),
+			Violations: violations,
+		},
+		Internal: err,
+	}
+}
+
+// Violations 展开 err，无法识别的错误作为一条只有 Message 的违规
+func (r *Renderer) Violations(err error) []Violation {
+	violations := []Violation{}
+	r.collect(err, origin{}, &violations)
+	return violations
+}
+
+// origin 外层 RequestError 提供的上下文
+type origin struct {
+	location  string
+	parameter *openapi3.Parameter
+	prefix    string
+}
+
+func (o origin) violation() Violation {
+	v := Violation{Location: o.location}
+	if o.parameter != nil {
+		v.Parameter = o.parameter.Name
+	}
+	return v
+}
+
+func (o origin) message(reason string) string {
+	if o.prefix == "" {
+		return reason
+	}
+	if reason == "" {
+		return o.prefix
+	}
+	return o.prefix + ": " + reason
+}
+
+func (r *Renderer) collect(err error, o origin, out *[]Violation) {
+	// 不用 errors.As：RequestError 会 Unwrap 出内层的 MultiError，丢掉参数信息
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			r.collect(inner, o, out)
+		}
+	case *openapi3filter.RequestError:
+		r.collectRequestError(e, out)
+	case *openapi3filter.SecurityRequirementsError:
+		*out = append(*out, Violation{Location: LocationSecurity, Keyword: "security", Message: e.Error()})
+	case *openapi3.SchemaError:
+		*out = append(*out, r.schemaViolation(e, o))
+	case *openapi3filter.ParseError:
+		*out = append(*out, r.parseViolation(e, o))
+	default:
+		v := o.violation()
+		switch {
+		case errors.Is(err, openapi3filter.ErrInvalidRequired):
+			v.Keyword = "required"
+		case errors.Is(err, openapi3filter.ErrInvalidEmptyValue):
+			v.Keyword = "allowEmptyValue"
+		}
+		v.Message = o.message(err.Error())
+		*out = append(*out, v)
+	}
+}
+
+func (r *Renderer) collectRequestError(e *openapi3filter.RequestError, out *[]Violation) {
+	var o origin
+	switch {
+	case e.Parameter != nil:
+		o = origin{location: e.Parameter.In, parameter: e.Parameter,
+			prefix: fmt.Sprintf("parameter %q in %s has an error", e.Parameter.Name, e.Parameter.In)}
+	case e.RequestBody != nil:
+		o = origin{location: LocationBody, prefix: "request body has an error"}
+	}
+	// 与 RequestError.Error 一样，Reason 与 Err 相同时只保留一个
+	if e.Reason != "" && (e.Err == nil || e.Reason != e.Err.Error()) {
+		o.prefix = o.message(e.Reason)
+	}
+	if e.Err == nil {
+		v := o.violation()
+		v.Message = o.prefix
+		*out = append(*out, v)
+		return
+	}
+	r.collect(e.Err, o, out)
+}
+
+func (r *Renderer) schemaViolation(e *openapi3.SchemaError, o origin) Violation {
+	v := o.violation()
+	path := e.JSONPointer()
+	v.Pointer = pointer.Join(path...)
+	v.Keyword = e.SchemaField
+	v.Message = o.message(e.Reason)
+	if v.Pointer != "" {
+		v.Message = o.message(v.Pointer + ": " + e.Reason)
+	}
+	v.Expected = expected(e.Schema, e.SchemaField)
+	v.Actual = actual(e.Value, e.SchemaField)
+	if v.Actual != nil && r.sensitiveValue(o.parameter, path, e.Schema) {
+		v.Actual = Redacted
+	}
+	return v
+}
+
+func (r *Renderer) parseViolation(e *openapi3filter.ParseError, o origin) Violation {
+	v := o.violation()
+	var path []string
+	for _, segment := range e.Path() {
+		path = append(path, fmt.Sprint(segment))
+	}
+	v.Pointer = pointer.Join(path...)
+	// 只拼接 Reason，Cause 中的 strconv 等错误会带上原始值
+	reasons := []string{}
+	for p := e; p != nil; {
+		if p.Reason != "" {
+			reasons = append(reasons, p.Reason)
+		}
+		next, ok := p.Cause.(*openapi3filter.ParseError)
+		if !ok {
+			break
+		}
+		p = next
+	}
+	v.Message = o.message(strings.Join(reasons, ": "))
+	var schema *openapi3.Schema
+	if o.parameter != nil && o.parameter.Schema != nil {
+		schema = o.parameter.Schema.Value
+		v.Keyword = "type"
+		v.Expected = schema.Type
+	}
+	if e.Value != nil {
+		v.Actual = e.Value
+		if r.sensitiveValue(o.parameter, path, schema) {
+			v.Actual = Redacted
+		}
+	}
+	return v
+}
+
+// sensitiveValue 参数名、pointer 中的任一段或 password 格式
+func (r *Renderer) sensitiveValue(parameter *openapi3.Parameter, path []string, schema *openapi3.Schema) bool {
+	if schema != nil && schema.Format == "password" {
+		return true
+	}
+	if parameter != nil {
+		if parameter.Schema != nil && parameter.Schema.Value != nil && parameter.Schema.Value.Format == "password" {
+			return true
+		}
+		if r.sensitiveName(parameter.Name) {
+			return true
+		}
+	}
+	for _, segment := range path {
+		if r.sensitiveName(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) sensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range r.sensitive {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// expected 按关键字取 schema 中的约束
+func expected(schema *openapi3.Schema, keyword string) interface{} {
+	if schema == nil {
+		return nil
+	}
+	switch keyword {
+	case "minimum", "exclusiveMinimum":
+		return floatValue(schema.Min)
+	case "maximum", "exclusiveMaximum":
+		return floatValue(schema.Max)
+	case "multipleOf":
+		return floatValue(schema.MultipleOf)
+	case "minLength":
+		return schema.MinLength
+	case "maxLength":
+		return uintValue(schema.MaxLength)
+	case "minItems":
+		return schema.MinItems
+	case "maxItems":
+		return uintValue(schema.MaxItems)
+	case "minProperties":
+		return schema.MinProps
+	case "maxProperties":
+		return uintValue(schema.MaxProps)
+	case "pattern":
+		return schema.Pattern
+	case "enum":
+		return schema.Enum
+	case "type":
+		return schema.Type
+	case "format":
+		return schema.Format
+	case "uniqueItems":
+		return true
+	}
+	return nil
+}
+
+// actual 标量原样返回；数组与对象只在数量类关键字下返回长度，避免整段回显
+func actual(value interface{}, keyword string) interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		if keyword == "minItems" || keyword == "maxItems" {
+			return len(value)
+		}
+		return nil
+	case map[string]interface{}:
+		if keyword == "minProperties" || keyword == "maxProperties" {
+			return len(value)
+		}
+		return nil
+	}
+	return value
+}
+
+func floatValue(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func uintValue(v *uint64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
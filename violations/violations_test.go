@@ -0,0 +1,183 @@
+package violations_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codegenTest "demo/oapi-codegen-go"
+	"demo/oapi-codegen-go/app"
+	"demo/oapi-codegen-go/violations"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const accountSpec = `
+openapi: "3.0.0"
+info: {title: accounts, version: "1"}
+paths:
+  /accounts:
+    post:
+      parameters:
+        - {name: X-Api-Key, in: header, schema: {type: string, minLength: 8}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [user]
+              properties:
+                user:
+                  type: object
+                  required: [name, password]
+                  properties:
+                    name: {type: string, minLength: 3}
+                    password: {type: string, minLength: 8}
+                    pin: {type: string, format: password, pattern: "^[0-9]{4}$"}
+                roles:
+                  type: array
+                  maxItems: 1
+                  items: {type: string, enum: [admin, user]}
+      responses: {"200": {description: ok}}
+`
+
+func newEcho(t *testing.T, swagger *openapi3.T, multiError bool) *echo.Echo {
+	t.Helper()
+	r := violations.New(violations.Options{})
+	e := echo.New()
+	e.HTTPErrorHandler = app.HTTPErrorHandler
+	e.Use(middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
+		Options:           openapi3filter.Options{MultiError: multiError},
+		MultiErrorHandler: r.MultiErrorHandler,
+		ErrorHandler:      r.ErrorHandler,
+	}))
+	e.Any("/*", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	return e
+}
+
+func send(t *testing.T, e *echo.Echo, method, target, body string, header http.Header) (int, violations.Body) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var result violations.Body
+	if rec.Code != http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result), rec.Body.String())
+	}
+	return rec.Code, result
+}
+
+func TestQueryParameter(t *testing.T) {
+	swagger, err := codegenTest.GetSwaggerWithPrefix("/api")
+	require.NoError(t, err)
+	e := newEcho(t, swagger, false)
+
+	code, body := send(t, e, http.MethodGet, "/api/pets?limit=3", "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, int32(http.StatusBadRequest), body.Code)
+	assert.Equal(t, `parameter "limit" in query has an error: number must be at least 12`, body.Message)
+	assert.Equal(t, []violations.Violation{{
+		Location:  violations.LocationQuery,
+		Parameter: "limit",
+		Keyword:   "minimum",
+		Expected:  float64(12),
+		Actual:    float64(3),
+		Message:   body.Message,
+	}}, body.Violations)
+
+	code, body = send(t, e, http.MethodGet, "/api/pets?limit=abc", "", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, body.Violations, 1)
+	assert.Equal(t, "type", body.Violations[0].Keyword)
+	assert.Equal(t, "integer", body.Violations[0].Expected)
+	assert.Equal(t, "abc", body.Violations[0].Actual)
+	assert.NotContains(t, body.Violations[0].Message, "abc")
+
+	// 找不到路由等非校验错误保持原样
+	code, body = send(t, e, http.MethodGet, "/api/owners", "", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "no matching operation was found", body.Message)
+	assert.Empty(t, body.Violations)
+}
+
+func TestBodyViolationsWithPointers(t *testing.T) {
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(accountSpec))
+	require.NoError(t, err)
+	require.NoError(t, swagger.Validate(context.Background()))
+	e := newEcho(t, swagger, true)
+
+	code, body := send(t, e, http.MethodPost, "/accounts",
+		`{"user":{"name":"al","password":"hunter2","pin":"12a"},"roles":["root","user"]}`,
+		http.Header{"X-Api-Key": {"short"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	type entry struct{ location, parameter, pointer, keyword string }
+	var got []entry
+	for _, v := range body.Violations {
+		got = append(got, entry{v.Location, v.Parameter, v.Pointer, v.Keyword})
+	}
+	assert.ElementsMatch(t, []entry{
+		{"header", "X-Api-Key", "", "minLength"},
+		{"body", "", "/user/name", "minLength"},
+		{"body", "", "/user/password", "minLength"},
+		{"body", "", "/user/pin", "pattern"},
+		{"body", "", "/roles/0", "enum"},
+		{"body", "", "/roles", "maxItems"},
+	}, got)
+
+	byPointer := make(map[string]violations.Violation)
+	for _, v := range body.Violations {
+		byPointer[v.Location+v.Pointer] = v
+	}
+	assert.Equal(t, "al", byPointer["body/user/name"].Actual)
+	assert.Equal(t, float64(3), byPointer["body/user/name"].Expected)
+	assert.Equal(t, violations.Redacted, byPointer["body/user/password"].Actual)
+	assert.Equal(t, violations.Redacted, byPointer["body/user/pin"].Actual, "format: password")
+	assert.Equal(t, violations.Redacted, byPointer["header"].Actual, "X-Api-Key 包含 api-key")
+	assert.Equal(t, float64(2), byPointer["body/roles"].Actual, "数组只返回长度")
+	assert.Equal(t, []interface{}{"admin", "user"}, byPointer["body/roles/0"].Expected)
+	assert.NotContains(t, body.Message, "hunter2")
+
+	code, body = send(t, e, http.MethodPost, "/accounts", `{}`, nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	require.Len(t, body.Violations, 1)
+	assert.Equal(t, "/user", body.Violations[0].Pointer)
+	assert.Equal(t, "required", body.Violations[0].Keyword)
+	assert.Nil(t, body.Violations[0].Actual)
+}
+
+func TestSensitiveNamesOverride(t *testing.T) {
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(accountSpec))
+	require.NoError(t, err)
+	r := violations.New(violations.Options{SensitiveNames: []string{"name"}})
+
+	input := &openapi3filter.RequestValidationInput{
+		Request: httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"user":{"name":"al","password":"x"}}`)),
+		Options: &openapi3filter.Options{MultiError: true},
+	}
+	input.Request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	op := swagger.Paths["/accounts"].Post
+	err = openapi3filter.ValidateRequestBody(context.Background(), input, op.RequestBody.Value)
+	require.Error(t, err)
+
+	actual := make(map[string]interface{})
+	for _, v := range r.Violations(err) {
+		actual[v.Pointer] = v.Actual
+	}
+	assert.Equal(t, violations.Redacted, actual["/user/name"])
+	assert.Equal(t, "x", actual["/user/password"], "覆盖默认列表后 password 不再隐藏")
+}